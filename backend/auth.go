@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"coaching-app/auth"
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const identityKey = "identity"
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	MemberID uint64
+	Email    string
+}
+
+type loginRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type passwordRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// AuthRequired rejects requests without a valid bearer token and stores the
+// caller's Identity on the gin.Context
+func (s *Server) AuthRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			return
+		}
+
+		claims, err := s.tokens.Parse(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		// Deleting a member revokes the tokens they still hold, and with them
+		// their roles and leads
+		members, _, err := s.members.List(repository.MemberFilter{IDs: []uint64{claims.MemberID}, IncludeDeleted: true}, repository.ListOptions{Page: 1, PageSize: 1})
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve member: " + err.Error()})
+			return
+		}
+		if len(members) > 0 && members[0].DeletedAt.Valid {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Member was deleted"})
+			return
+		}
+
+		c.Set(identityKey, Identity{MemberID: claims.MemberID, Email: claims.Email})
+		c.Next()
+	}
+}
+
+// CurrentIdentity returns the caller set by AuthRequired
+func CurrentIdentity(c *gin.Context) Identity {
+	return c.MustGet(identityKey).(Identity)
+}
+
+// Login exchanges an email and password for a signed access token
+func (s *Server) Login(c *gin.Context) {
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Unknown emails and wrong passwords get the same answer
+	var account *models.Account
+	member, err := s.members.GetByEmail(req.Email)
+	if err == nil {
+		account, err = s.members.GetAccount(member.ID)
+	}
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil || !auth.CheckPassword(account.PasswordHash, req.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	}
+
+	token, expiresAt, err := s.tokens.Issue(member.ID, member.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue token: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": token, "expiresAt": expiresAt, "member": member})
+}
+
+// GetCurrentMember returns the team member behind the caller's token
+func (s *Server) GetCurrentMember(c *gin.Context) {
+	member, err := s.members.Get(CurrentIdentity(c).MemberID)
+	if err != nil {
+		respondLookupError(c, err, "Team member not found", "")
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// SetMemberPassword creates the local account of a member or changes its password.
+// Only the member themself or an admin can, whether or not the account exists.
+func (s *Server) SetMemberPassword(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	member, err := s.members.Get(id)
+	if err != nil {
+		respondLookupError(c, err, "Team member not found", "")
+		return
+	}
+
+	var req passwordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account, err := s.members.GetAccount(member.ID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if member.ID != CurrentIdentity(c).MemberID {
+		perms := s.loadPermissions(c)
+		if perms == nil {
+			return
+		}
+		if !perms.Admin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the member or an admin can set this password"})
+			return
+		}
+	}
+	if account == nil {
+		account = &models.Account{MemberID: member.ID}
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	account.PasswordHash = hash
+
+	if err := s.members.SaveAccount(account); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save account: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully"})
+}
+
+// BootstrapAccount makes sure a first admin account exists so somebody can log in
+// to a fresh installation. It does nothing once any account has been created.
+// Every step is idempotent, so a bootstrap interrupted halfway is completed on
+// the next start.
+func (s *Server) BootstrapAccount(email, password string) error {
+	count, err := s.members.CountAccounts()
+	if err != nil || count > 0 {
+		return err
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.members.GetByEmail(email)
+	if errors.Is(err, repository.ErrNotFound) {
+		member = &models.TeamMember{Name: "Administrator", Email: email}
+		err = s.members.Create(member)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.members.GrantRole(&models.MemberRole{MemberID: member.ID, Role: models.RoleAdmin}); err != nil {
+		return err
+	}
+	return s.members.SaveAccount(&models.Account{MemberID: member.ID, PasswordHash: hash})
+}
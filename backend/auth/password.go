@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength is the shortest password accepted for a local account
+const MinPasswordLength = 8
+
+var ErrPasswordTooShort = errors.New("password must be at least 8 characters")
+
+// HashPassword returns a bcrypt hash suitable for storing in the accounts table
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", ErrPasswordTooShort
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches the stored bcrypt hash
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
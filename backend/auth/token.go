@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token has expired")
+)
+
+// Claims is the payload carried by an access token
+type Claims struct {
+	MemberID  uint64 `json:"sub"`
+	Email     string `json:"email"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// TokenIssuer signs and verifies HS256 JWT access tokens
+type TokenIssuer struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewTokenIssuer(secret []byte, ttl time.Duration) *TokenIssuer {
+	return &TokenIssuer{secret: secret, ttl: ttl, now: time.Now}
+}
+
+// tokenHeader is the fixed, pre-encoded JWT header for HS256 tokens
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Issue creates a signed token for the given member and returns it with its expiry time
+func (t *TokenIssuer) Issue(memberID uint64, email string) (string, time.Time, error) {
+	now := t.now()
+	expiresAt := now.Add(t.ttl)
+	payload, err := json.Marshal(Claims{
+		MemberID:  memberID,
+		Email:     email,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	signingInput := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signingInput + "." + t.sign(signingInput), expiresAt, nil
+}
+
+// Parse verifies the token signature and expiry and returns its claims
+func (t *TokenIssuer) Parse(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenHeader {
+		return nil, ErrInvalidToken
+	}
+
+	expected := t.sign(parts[0] + "." + parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.MemberID == 0 {
+		return nil, ErrInvalidToken
+	}
+	if t.now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+	return &claims, nil
+}
+
+func (t *TokenIssuer) sign(signingInput string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(signingInput))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueAndParseToken(t *testing.T) {
+	issuer := NewTokenIssuer([]byte("secret"), time.Hour)
+
+	token, expiresAt, err := issuer.Issue(42, "someone@example.com")
+	assert.NoError(t, err)
+	assert.True(t, expiresAt.After(time.Now()))
+
+	claims, err := issuer.Parse(token)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), claims.MemberID)
+	assert.Equal(t, "someone@example.com", claims.Email)
+}
+
+func TestParseRejectsTamperedToken(t *testing.T) {
+	issuer := NewTokenIssuer([]byte("secret"), time.Hour)
+	token, _, _ := issuer.Issue(42, "someone@example.com")
+
+	other := NewTokenIssuer([]byte("other-secret"), time.Hour)
+	_, err := other.Parse(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	parts := strings.Split(token, ".")
+	forged, _, _ := other.Issue(1, "admin@example.com")
+	_, err = issuer.Parse(parts[0] + "." + strings.Split(forged, ".")[1] + "." + parts[2])
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = issuer.Parse("not-a-token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestParseRejectsExpiredToken(t *testing.T) {
+	issuer := NewTokenIssuer([]byte("secret"), time.Minute)
+	issuer.now = func() time.Time { return time.Now().Add(-2 * time.Minute) }
+	token, _, _ := issuer.Issue(42, "someone@example.com")
+
+	issuer.now = time.Now
+	_, err := issuer.Parse(token)
+	assert.ErrorIs(t, err, ErrExpiredToken)
+}
+
+func TestHashPassword(t *testing.T) {
+	_, err := HashPassword("short")
+	assert.ErrorIs(t, err, ErrPasswordTooShort)
+
+	hash, err := HashPassword("correct horse")
+	assert.NoError(t, err)
+	assert.True(t, CheckPassword(hash, "correct horse"))
+	assert.False(t, CheckPassword(hash, "wrong horse"))
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coaching-app/auth"
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestAccount(t *testing.T, name, email, password string) models.TeamMember {
+	member := models.TeamMember{Name: name, Email: email}
+	assert.NoError(t, testDB.Create(&member).Error)
+	hash, err := auth.HashPassword(password)
+	assert.NoError(t, err)
+	assert.NoError(t, testDB.Create(&models.Account{MemberID: member.ID, PasswordHash: hash}).Error)
+	return member
+}
+
+func TestRoutesRequireAuthentication(t *testing.T) {
+	setupTestDatabase()
+
+	for _, path := range []string{"/members/", "/teams/", "/feedback/", "/auth/me"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		GlobalTestRouter.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+
+		req, _ = http.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer not.a.token")
+		w = httptest.NewRecorder()
+		GlobalTestRouter.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+	}
+}
+
+func TestDeletedMemberLosesAccess(t *testing.T) {
+	setupTestDatabase()
+
+	admin := createTestAccount(t, "Former Admin", "former@example.com", "s3cret-pass")
+	testDB.Create(&models.MemberRole{MemberID: admin.ID, Role: models.RoleAdmin})
+	assert.Equal(t, http.StatusOK, serve("GET", "/members/?include_deleted=true", "", admin.ID).Code)
+
+	assert.Equal(t, http.StatusNoContent, serve("DELETE", fmt.Sprintf("/members/%d", admin.ID), "", testCallerID).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("GET", "/members/?include_deleted=true", "", admin.ID).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("GET", "/auth/me", "", admin.ID).Code)
+
+	assert.Equal(t, http.StatusOK, serve("POST", fmt.Sprintf("/members/%d/restore", admin.ID), "", testCallerID).Code)
+	assert.Equal(t, http.StatusOK, serve("GET", "/auth/me", "", admin.ID).Code)
+}
+
+func TestLogin(t *testing.T) {
+	setupTestDatabase()
+
+	member := createTestAccount(t, "Login User", "login@example.com", "s3cret-pass")
+
+	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBufferString(`{"email": "login@example.com", "password": "wrong-pass"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req, _ = http.NewRequest("POST", "/auth/login", bytes.NewBufferString(`{"email": "login@example.com", "password": "s3cret-pass"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Token string `json:"token"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotEmpty(t, response.Token)
+
+	req, _ = http.NewRequest("GET", "/auth/me", nil)
+	req.Header.Set("Authorization", "Bearer "+response.Token)
+	w = httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var me models.TeamMember
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &me))
+	assert.Equal(t, member.ID, me.ID)
+	assert.NotContains(t, w.Body.String(), "PasswordHash")
+}
+
+func TestSetMemberPassword(t *testing.T) {
+	setupTestDatabase()
+
+	owner := createTestAccount(t, "Owner", "owner@example.com", "original-pass")
+	newcomer := models.TeamMember{Name: "Newcomer", Email: "newcomer@example.com"}
+	testDB.Create(&newcomer)
+
+	// Only admins provision an account for another member without one
+	stranger := models.TeamMember{Name: "Stranger", Email: "stranger@example.com"}
+	testDB.Create(&stranger)
+	req, _ := http.NewRequest("PUT", fmt.Sprintf("/members/%d/password", stranger.ID), bytes.NewBufferString(`{"password": "taken-over"}`))
+	authorizeAs(req, owner.ID)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, _ = http.NewRequest("PUT", fmt.Sprintf("/members/%d/password", newcomer.ID), bytes.NewBufferString(`{"password": "welcome-aboard"}`))
+	authorize(req)
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// And only the owner or an admin can change an existing password
+	req, _ = http.NewRequest("PUT", fmt.Sprintf("/members/%d/password", owner.ID), bytes.NewBufferString(`{"password": "hijacked-pass"}`))
+	authorizeAs(req, newcomer.ID)
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, _ = http.NewRequest("PUT", fmt.Sprintf("/members/%d/password", owner.ID), bytes.NewBufferString(`{"password": "short"}`))
+	authorizeAs(req, owner.ID)
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req, _ = http.NewRequest("PUT", fmt.Sprintf("/members/%d/password", owner.ID), bytes.NewBufferString(`{"password": "rotated-pass"}`))
+	authorizeAs(req, owner.ID)
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var account models.Account
+	testDB.Where("member_id = ?", owner.ID).First(&account)
+	assert.True(t, auth.CheckPassword(account.PasswordHash, "rotated-pass"))
+}
+
+func TestBootstrapAccount(t *testing.T) {
+	setupTestDatabase()
+	srv := NewServer(repository.NewGormRepositories(testDB), testTokens, testSettings)
+
+	assert.NoError(t, srv.BootstrapAccount("admin@example.com", "bootstrap-pass"))
+	assert.NoError(t, srv.BootstrapAccount("other@example.com", "bootstrap-pass"))
+
+	var count int64
+	testDB.Model(&models.Account{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	var member models.TeamMember
+	assert.NoError(t, testDB.Where("email = ?", "admin@example.com").First(&member).Error)
+
+	var role models.MemberRole
+	assert.NoError(t, testDB.Where("member_id = ? AND role = ?", member.ID, models.RoleAdmin).First(&role).Error)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const permissionsKey = "permissions"
+
+// Permissions describes what the authenticated caller is allowed to do
+type Permissions struct {
+	MemberID uint64
+	Admin    bool
+	Coach    bool
+	LeadOf   map[uint64]bool
+}
+
+// LeadsTeam reports whether the caller may manage the membership of a team
+func (p *Permissions) LeadsTeam(teamID uint64) bool {
+	return p.Admin || p.LeadOf[teamID]
+}
+
+// ReadsAllFeedback reports whether the caller may read every feedback entry
+// that is not private
+func (p *Permissions) ReadsAllFeedback() bool {
+	return p.Admin || p.Coach
+}
+
+// FeedbackReader returns the restriction to apply to the feedback lists of
+// the caller. Even admins and coaches do not read the private feedback of others.
+func (p *Permissions) FeedbackReader() *repository.FeedbackReader {
+	if p.ReadsAllFeedback() {
+		return &repository.FeedbackReader{MemberID: p.MemberID, All: true}
+	}
+	reader := &repository.FeedbackReader{MemberID: p.MemberID}
+	for teamID := range p.LeadOf {
+		reader.LedTeamIDs = append(reader.LedTeamIDs, teamID)
+	}
+	return reader
+}
+
+type roleRequest struct {
+	Role   string  `json:"role" binding:"required"`
+	TeamID *uint64 `json:"teamid"`
+}
+
+// CallerPermissions loads the roles of the authenticated caller. The result is
+// cached on the gin.Context for the rest of the request.
+func (s *Server) CallerPermissions(c *gin.Context) (*Permissions, error) {
+	if cached, ok := c.Get(permissionsKey); ok {
+		return cached.(*Permissions), nil
+	}
+
+	identity := CurrentIdentity(c)
+	roles, err := s.members.ListRoles(identity.MemberID)
+	if err != nil {
+		return nil, err
+	}
+
+	perms := &Permissions{MemberID: identity.MemberID, LeadOf: map[uint64]bool{}}
+	for _, role := range roles {
+		switch role.Role {
+		case models.RoleAdmin:
+			perms.Admin = true
+		case models.RoleCoach:
+			perms.Coach = true
+		case models.RoleTeamLead:
+			if role.TeamID != nil {
+				perms.LeadOf[*role.TeamID] = true
+			}
+		}
+	}
+	// Leading a team in its current assignment period counts as the team_lead role
+	now := time.Now()
+	assignments, err := s.teams.ListAssignments(repository.MembershipFilter{MemberID: &identity.MemberID, At: &now})
+	if err != nil {
+		return nil, err
+	}
+	for _, assignment := range assignments {
+		if assignment.Role == models.TeamRoleLead {
+			perms.LeadOf[assignment.TeamID] = true
+		}
+	}
+
+	c.Set(permissionsKey, perms)
+	return perms, nil
+}
+
+// loadPermissions is CallerPermissions for handlers: it writes the error
+// response itself and returns nil when the request has already been answered
+func (s *Server) loadPermissions(c *gin.Context) *Permissions {
+	perms, err := s.CallerPermissions(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load permissions: " + err.Error()})
+		return nil
+	}
+	return perms
+}
+
+// requireAdmin answers 403 unless the caller is an admin
+func (s *Server) requireAdmin(c *gin.Context) bool {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return false
+	}
+	if !perms.Admin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This action requires the admin role"})
+		return false
+	}
+	return true
+}
+
+// requireTeamLead answers 403 unless the caller leads the team (or is an admin)
+func (s *Server) requireTeamLead(c *gin.Context, teamID uint64) bool {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return false
+	}
+	if !perms.LeadsTeam(teamID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only leads of this team can change its members"})
+		return false
+	}
+	return true
+}
+
+// managesMember reports whether the caller is the manager of a member or leads
+// one of their teams (or is an admin)
+func (s *Server) managesMember(perms *Permissions, memberID uint64) (bool, error) {
+	if perms.Admin {
+		return true, nil
+	}
+	member, err := s.members.Get(memberID)
+	switch {
+	case err == nil:
+		if member.ManagerID != nil && *member.ManagerID == perms.MemberID {
+			return true, nil
+		}
+	case !errors.Is(err, repository.ErrNotFound):
+		return false, err
+	}
+	if len(perms.LeadOf) == 0 {
+		return false, nil
+	}
+	teams, _, err := s.teams.List(repository.TeamFilter{MemberID: &memberID}, repository.ListOptions{Page: 1, PageSize: maxPageSize})
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(teams, func(team models.Team) bool { return perms.LeadOf[team.ID] }), nil
+}
+
+// GetMemberRoles lists the roles granted to a member
+func (s *Server) GetMemberRoles(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	roles, err := s.members.ListRoles(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// GrantMemberRole gives a member a role. Only admins can manage roles.
+func (s *Server) GrantMemberRole(c *gin.Context) {
+	if !s.requireAdmin(c) {
+		return
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	member, err := s.members.Get(id)
+	if err != nil {
+		respondLookupError(c, err, "Team member not found", "")
+		return
+	}
+
+	var req roleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch req.Role {
+	case models.RoleAdmin, models.RoleCoach:
+		req.TeamID = nil
+	case models.RoleTeamLead:
+		if req.TeamID == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "TeamID is required for the team_lead role"})
+			return
+		}
+		if _, err := s.teams.Get(*req.TeamID); err != nil {
+			respondLookupError(c, err, "Team not found", "")
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role. Must be 'admin', 'coach' or 'team_lead'."})
+		return
+	}
+
+	role := models.MemberRole{MemberID: member.ID, Role: req.Role, TeamID: req.TeamID}
+	if err := s.members.GrantRole(&role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant role: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, role)
+}
+
+// RevokeMemberRole removes a role from a member. Only admins can manage roles.
+func (s *Server) RevokeMemberRole(c *gin.Context) {
+	if !s.requireAdmin(c) {
+		return
+	}
+
+	memberID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	roleID, ok := idParam(c, "role_id")
+	if !ok {
+		return
+	}
+
+	if err := s.members.RevokeRole(memberID, roleID); err != nil {
+		respondLookupError(c, err, "Role not found", "")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
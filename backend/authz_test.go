@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coaching-app/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteRequiresAdmin(t *testing.T) {
+	setupTestDatabase()
+
+	member := models.TeamMember{Name: "Regular Member", Email: "regular@example.com"}
+	testDB.Create(&member)
+	team := models.Team{Name: "Protected Team"}
+	testDB.Create(&team)
+
+	for _, path := range []string{fmt.Sprintf("/teams/%d", team.ID), fmt.Sprintf("/members/%d", member.ID)} {
+		req, _ := http.NewRequest("DELETE", path, nil)
+		authorizeAs(req, member.ID)
+		w := httptest.NewRecorder()
+		GlobalTestRouter.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, path)
+	}
+
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/teams/%d", team.ID), nil)
+	authorize(req)
+	w := httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestAssignRequiresTeamLead(t *testing.T) {
+	setupTestDatabase()
+
+	lead := models.TeamMember{Name: "Lead", Email: "lead@example.com"}
+	recruit := models.TeamMember{Name: "Recruit", Email: "recruit@example.com"}
+	testDB.Create(&lead)
+	testDB.Create(&recruit)
+	ledTeam := models.Team{Name: "Led Team"}
+	otherTeam := models.Team{Name: "Other Team"}
+	testDB.Create(&ledTeam)
+	testDB.Create(&otherTeam)
+	testDB.Create(&models.MemberRole{MemberID: lead.ID, Role: models.RoleTeamLead, TeamID: &ledTeam.ID})
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/teams/%d/assign/%d", otherTeam.ID, recruit.ID), nil)
+	authorizeAs(req, lead.ID)
+	w := httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, _ = http.NewRequest("POST", fmt.Sprintf("/teams/%d/assign/%d", ledTeam.ID, recruit.ID), nil)
+	authorizeAs(req, lead.ID)
+	w = httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/teams/%d/remove/%d", ledTeam.ID, recruit.ID), nil)
+	authorizeAs(req, recruit.ID)
+	w = httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/teams/%d/remove/%d", ledTeam.ID, recruit.ID), nil)
+	authorizeAs(req, lead.ID)
+	w = httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGetFeedbacksScopedToCaller(t *testing.T) {
+	setupTestDatabase()
+
+	alice := models.TeamMember{Name: "Alice", Email: "alice@example.com"}
+	bob := models.TeamMember{Name: "Bob", Email: "bob@example.com"}
+	coach := models.TeamMember{Name: "Coach", Email: "coach@example.com"}
+	testDB.Create(&alice)
+	testDB.Create(&bob)
+	testDB.Create(&coach)
+	aliceTeam := models.Team{Name: "Alice Team", Members: []models.TeamMember{alice}}
+	bobTeam := models.Team{Name: "Bob Team", Members: []models.TeamMember{bob}}
+	testDB.Create(&aliceTeam)
+	testDB.Create(&bobTeam)
+	testDB.Create(&models.MemberRole{MemberID: coach.ID, Role: models.RoleCoach})
+
+	testDB.Create(&models.Feedback{Content: "To Alice", TargetID: alice.ID, TargetType: "member", GiverID: &coach.ID})
+	testDB.Create(&models.Feedback{Content: "To Alice Team", TargetID: aliceTeam.ID, TargetType: "team", GiverID: &coach.ID})
+	testDB.Create(&models.Feedback{Content: "To Bob", TargetID: bob.ID, TargetType: "member", GiverID: &coach.ID})
+	testDB.Create(&models.Feedback{Content: "To Bob Team", TargetID: bobTeam.ID, TargetType: "team", GiverID: &coach.ID})
+	testDB.Create(&models.Feedback{Content: "From Alice", TargetID: bob.ID, TargetType: "member", GiverID: &alice.ID})
+
+	fetch := func(memberID uint64, query string) []string {
+		req, _ := http.NewRequest("GET", "/feedback/"+query, nil)
+		authorizeAs(req, memberID)
+		w := httptest.NewRecorder()
+		GlobalTestRouter.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var feedbacks []models.Feedback
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &feedbacks))
+		contents := []string{}
+		for _, fb := range feedbacks {
+			contents = append(contents, fb.Content)
+		}
+		return contents
+	}
+
+	assert.ElementsMatch(t, []string{"To Alice", "To Alice Team", "From Alice"}, fetch(alice.ID, ""))
+	assert.ElementsMatch(t, []string{"From Alice"}, fetch(alice.ID, fmt.Sprintf("?member_id=%d", bob.ID)))
+	assert.ElementsMatch(t, []string{"To Bob", "To Bob Team", "From Alice"}, fetch(bob.ID, ""))
+	assert.Len(t, fetch(coach.ID, ""), 5)
+
+	testDB.Create(&models.MemberRole{MemberID: alice.ID, Role: models.RoleTeamLead, TeamID: &bobTeam.ID})
+	assert.ElementsMatch(t, []string{"To Alice", "To Alice Team", "To Bob", "To Bob Team", "From Alice"}, fetch(alice.ID, ""))
+}
+
+func TestManageMemberRoles(t *testing.T) {
+	setupTestDatabase()
+
+	member := models.TeamMember{Name: "Future Lead", Email: "future_lead@example.com"}
+	testDB.Create(&member)
+	team := models.Team{Name: "Some Team"}
+	testDB.Create(&team)
+
+	grant := func(callerID uint64, payload string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/members/%d/roles", member.ID), bytes.NewBufferString(payload))
+		authorizeAs(req, callerID)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		GlobalTestRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusForbidden, grant(member.ID, `{"role": "admin"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, grant(testCallerID, `{"role": "overlord"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, grant(testCallerID, `{"role": "team_lead"}`).Code)
+	assert.Equal(t, http.StatusNotFound, grant(testCallerID, `{"role": "team_lead", "teamid": 99999}`).Code)
+
+	w := grant(testCallerID, fmt.Sprintf(`{"role": "team_lead", "teamid": %d}`, team.ID))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var role models.MemberRole
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &role))
+
+	// Granting the same role twice is idempotent
+	w = grant(testCallerID, fmt.Sprintf(`{"role": "team_lead", "teamid": %d}`, team.ID))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var again models.MemberRole
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &again))
+	assert.Equal(t, role.ID, again.ID)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/members/%d/roles", member.ID), nil)
+	authorizeAs(req, member.ID)
+	w = httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var roles []models.MemberRole
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &roles))
+	assert.Len(t, roles, 1)
+
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/members/%d/roles/%d", member.ID, role.ID), nil)
+	authorize(req)
+	w = httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/members/%d/roles/%d", member.ID, role.ID), nil)
+	authorize(req)
+	w = httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultMinScore = 1
+	defaultMaxScore = 5
+)
+
+type competencyRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	MinScore    int    `json:"minscore"`
+	MaxScore    int    `json:"maxscore"`
+}
+
+type competencyUpdateRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Active      *bool   `json:"active"`
+}
+
+// CompetencyScore aggregates the scores of one competency over one period
+type CompetencyScore struct {
+	CompetencyID uint64
+	Competency   string
+	Period       string
+	Count        int
+	Average      float64
+	Min          int
+	Max          int
+	// Suppressed is set instead of the figures when too few givers contributed
+	Suppressed bool
+}
+
+// scorePeriods maps the supported interval values to the function naming the
+// period a score falls in
+var scorePeriods = map[string]func(time.Time) string{
+	"day":   func(t time.Time) string { return t.Format("2006-01-02") },
+	"week":  func(t time.Time) string { year, week := t.ISOWeek(); return fmt.Sprintf("%d-W%02d", year, week) },
+	"month": func(t time.Time) string { return t.Format("2006-01") },
+	"year":  func(t time.Time) string { return t.Format("2006") },
+	"all":   func(time.Time) string { return "all" },
+}
+
+// CreateCompetency adds a competency to the framework. Scales default to 1-5.
+// Only admins can change the framework.
+func (s *Server) CreateCompetency(c *gin.Context) {
+	if !s.requireAdmin(c) {
+		return
+	}
+
+	var req competencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	competency := models.Competency{
+		Name:        req.Name,
+		Description: req.Description,
+		MinScore:    cmp.Or(req.MinScore, defaultMinScore),
+		MaxScore:    cmp.Or(req.MaxScore, defaultMaxScore),
+		Active:      true,
+	}
+	if competency.MinScore >= competency.MaxScore {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "MinScore must be lower than MaxScore"})
+		return
+	}
+
+	if err := s.competencies.Create(&competency); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create competency: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, competency)
+}
+
+// GetCompetencies lists the competency framework, including deactivated competencies
+func (s *Server) GetCompetencies(c *gin.Context) {
+	competencies, err := s.competencies.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve competencies: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, competencies)
+}
+
+// UpdateCompetency renames, describes, deactivates or reactivates a competency.
+// The scale cannot change once created, so that scores stay comparable.
+// Deactivated competencies keep their scores but cannot be scored anymore.
+func (s *Server) UpdateCompetency(c *gin.Context) {
+	if !s.requireAdmin(c) {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req competencyUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	changes := map[string]any{}
+	if req.Name != nil {
+		if *req.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Name cannot be empty"})
+			return
+		}
+		changes["name"] = *req.Name
+	}
+	if req.Description != nil {
+		changes["description"] = *req.Description
+	}
+	if req.Active != nil {
+		changes["active"] = *req.Active
+	}
+
+	competency, err := s.competencies.Update(id, changes)
+	if err != nil {
+		respondLookupError(c, err, "Competency not found", "Failed to update competency: ")
+		return
+	}
+	c.JSON(http.StatusOK, competency)
+}
+
+// validateScores checks the scores of new feedback against the competency
+// framework. It answers 400 and returns false when a score is invalid.
+func (s *Server) validateScores(c *gin.Context, scores []models.FeedbackScore) bool {
+	seen := map[uint64]bool{}
+	for i := range scores {
+		score := &scores[i]
+		score.ID = 0
+		score.FeedbackID = 0
+
+		if seen[score.CompetencyID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Competency %d is scored more than once", score.CompetencyID)})
+			return false
+		}
+		seen[score.CompetencyID] = true
+
+		competency, err := s.competencies.Get(score.CompetencyID)
+		if err != nil {
+			respondLookupError(c, err, fmt.Sprintf("Competency %d not found", score.CompetencyID), "Error finding competency: ")
+			return false
+		}
+		if !competency.Active {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Competency %q is no longer active", competency.Name)})
+			return false
+		}
+		if score.Score < competency.MinScore || score.Score > competency.MaxScore {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Score for %q must be between %d and %d", competency.Name, competency.MinScore, competency.MaxScore)})
+			return false
+		}
+	}
+	return true
+}
+
+// GetMemberScores aggregates the competency scores given to a member
+func (s *Server) GetMemberScores(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.members.Get(id); err != nil {
+		respondLookupError(c, err, "Team member not found", "")
+		return
+	}
+	s.respondScores(c, repository.ScoreFilter{MemberID: &id}, 0)
+}
+
+// GetTeamScores aggregates the competency scores given to a team and to its
+// members. Aggregates with fewer distinct givers than the anonymity minimum are
+// suppressed, so that the givers of a small team cannot be singled out.
+func (s *Server) GetTeamScores(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.teams.Get(id); err != nil {
+		respondLookupError(c, err, "Team not found", "")
+		return
+	}
+	teamIDs, ok := s.rollupQuery(c, id)
+	if !ok {
+		return
+	}
+	s.respondScores(c, repository.ScoreFilter{TeamIDs: teamIDs}, s.settings.MinAnonymousGivers)
+}
+
+// respondScores answers with the average, minimum and maximum score per
+// competency and per period. The period is chosen with interval (day, week,
+// month, year or all; month by default) and the range with from/to. Only the
+// feedback the caller may read is counted. The figures of the aggregates with
+// fewer than minGivers distinct givers are left out.
+func (s *Server) respondScores(c *gin.Context, filter repository.ScoreFilter, minGivers int) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	filter.Reader = perms.FeedbackReader()
+
+	interval := cmp.Or(c.Query("interval"), "month")
+	periodOf, ok := scorePeriods[interval]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be one of day, week, month, year or all"})
+		return
+	}
+
+	var err error
+	if filter.CreatedFrom, filter.CreatedBefore, err = dateRangeQuery(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	samples, err := s.competencies.ListScores(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve scores: " + err.Error()})
+		return
+	}
+	competencies, err := s.competencies.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve competencies: " + err.Error()})
+		return
+	}
+	names := map[uint64]string{}
+	for _, competency := range competencies {
+		names[competency.ID] = competency.Name
+	}
+
+	type bucket struct {
+		competencyID uint64
+		period       string
+	}
+	totals := map[bucket]int{}
+	givers := map[bucket]map[string]bool{}
+	aggregates := map[bucket]*CompetencyScore{}
+	for _, sample := range samples {
+		key := bucket{sample.CompetencyID, periodOf(sample.CreatedAt.UTC())}
+		aggregate, ok := aggregates[key]
+		if !ok {
+			aggregate = &CompetencyScore{
+				CompetencyID: sample.CompetencyID,
+				Competency:   names[sample.CompetencyID],
+				Period:       key.period,
+				Min:          sample.Score,
+				Max:          sample.Score,
+			}
+			aggregates[key] = aggregate
+			givers[key] = map[string]bool{}
+		}
+		givers[key][giverKey(sample)] = true
+		aggregate.Count++
+		aggregate.Min = min(aggregate.Min, sample.Score)
+		aggregate.Max = max(aggregate.Max, sample.Score)
+		totals[key] += sample.Score
+	}
+
+	result := make([]CompetencyScore, 0, len(aggregates))
+	for key, aggregate := range aggregates {
+		aggregate.Average = float64(totals[key]) / float64(aggregate.Count)
+		if len(givers[key]) < minGivers {
+			*aggregate = CompetencyScore{
+				CompetencyID: aggregate.CompetencyID,
+				Competency:   aggregate.Competency,
+				Period:       aggregate.Period,
+				Suppressed:   true,
+			}
+		}
+		result = append(result, *aggregate)
+	}
+	slices.SortFunc(result, func(a, b CompetencyScore) int {
+		return cmp.Or(cmp.Compare(a.Period, b.Period), cmp.Compare(a.Competency, b.Competency))
+	})
+	c.JSON(http.StatusOK, result)
+}
+
+// giverKey tells the givers of score samples apart, anonymous or not. The
+// samples whose giver was deleted share the empty key.
+func giverKey(sample repository.ScoreSample) string {
+	switch {
+	case sample.GiverHash != "":
+		return "anonymous:" + sample.GiverHash
+	case sample.GiverID != nil:
+		return "member:" + strconv.FormatUint(*sample.GiverID, 10)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"coaching-app/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManageCompetencies(t *testing.T) {
+	setupTestDatabase()
+
+	member := models.TeamMember{Name: "Regular", Email: "regular@example.com"}
+	testDB.Create(&member)
+
+	w := serve("POST", "/competencies/", `{"name": "Communication"}`, member.ID)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serve("POST", "/competencies/", `{"name": "Upside down", "minscore": 5, "maxscore": 1}`, testCallerID)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve("POST", "/competencies/", `{"name": "Communication", "description": "Shares context early"}`, testCallerID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var competency models.Competency
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &competency))
+	assert.Equal(t, 1, competency.MinScore)
+	assert.Equal(t, 5, competency.MaxScore)
+	assert.True(t, competency.Active)
+
+	w = serve("PUT", fmt.Sprintf("/competencies/%d", competency.ID), `{"active": false}`, testCallerID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &competency))
+	assert.False(t, competency.Active)
+	assert.Equal(t, "Communication", competency.Name)
+
+	w = serve("PUT", "/competencies/99999", `{"active": true}`, testCallerID)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var competencies []models.Competency
+	getList(t, "/competencies/", &competencies)
+	assert.Len(t, competencies, 1)
+}
+
+func TestGiveFeedbackWithScores(t *testing.T) {
+	setupTestDatabase()
+
+	target := models.TeamMember{Name: "Target", Email: "target@example.com"}
+	giver := models.TeamMember{Name: "Giver", Email: "giver@example.com"}
+	testDB.Create(&target)
+	testDB.Create(&giver)
+	communication := models.Competency{Name: "Communication", MinScore: 1, MaxScore: 5, Active: true}
+	retired := models.Competency{Name: "Retired", MinScore: 1, MaxScore: 5}
+	testDB.Create(&communication)
+	testDB.Create(&retired)
+	testDB.Model(&retired).Update("active", false)
+
+	give := func(scores string) int {
+		payload := fmt.Sprintf(`{"content": "Scored", "targetid": %d, "targettype": "member", "scores": %s}`, target.ID, scores)
+		return serve("POST", "/feedback/", payload, giver.ID).Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, give(fmt.Sprintf(`[{"competencyid": %d, "score": 6}]`, communication.ID)))
+	assert.Equal(t, http.StatusBadRequest, give(fmt.Sprintf(`[{"competencyid": %d, "score": 3}, {"competencyid": %d, "score": 4}]`, communication.ID, communication.ID)))
+	assert.Equal(t, http.StatusBadRequest, give(fmt.Sprintf(`[{"competencyid": %d, "score": 3}]`, retired.ID)))
+	assert.Equal(t, http.StatusNotFound, give(`[{"competencyid": 99999, "score": 3}]`))
+	assert.Equal(t, http.StatusCreated, give(fmt.Sprintf(`[{"competencyid": %d, "score": 4}]`, communication.ID)))
+
+	var feedbacks []models.Feedback
+	getList(t, "/feedback/", &feedbacks)
+	if assert.Len(t, feedbacks, 1) && assert.Len(t, feedbacks[0].Scores, 1) {
+		assert.Equal(t, communication.ID, feedbacks[0].Scores[0].CompetencyID)
+		assert.Equal(t, 4, feedbacks[0].Scores[0].Score)
+	}
+}
+
+func TestScoreAggregates(t *testing.T) {
+	setupTestDatabase()
+
+	alice := models.TeamMember{Name: "Alice", Email: "alice@example.com"}
+	bob := models.TeamMember{Name: "Bob", Email: "bob@example.com"}
+	outsider := models.TeamMember{Name: "Outsider", Email: "outsider@example.com"}
+	testDB.Create(&alice)
+	testDB.Create(&bob)
+	testDB.Create(&outsider)
+	team := models.Team{Name: "Platform", Members: []models.TeamMember{alice, bob}}
+	testDB.Create(&team)
+	delivery := models.Competency{Name: "Delivery", MinScore: 1, MaxScore: 5, Active: true}
+	testDB.Create(&delivery)
+
+	score := func(targetType string, targetID uint64, value int, createdAt time.Time) {
+		testDB.Create(&models.Feedback{
+			Content: "Scored", TargetType: targetType, TargetID: targetID, CreatedAt: createdAt,
+			Scores: []models.FeedbackScore{{CompetencyID: delivery.ID, Score: value}},
+		})
+	}
+	january := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	february := time.Date(2024, 2, 10, 9, 0, 0, 0, time.UTC)
+	score("member", alice.ID, 2, january)
+	score("member", alice.ID, 4, january)
+	score("member", alice.ID, 5, february)
+	score("member", bob.ID, 1, february)
+	score("team", team.ID, 3, february)
+
+	var scores []CompetencyScore
+	getList(t, fmt.Sprintf("/members/%d/scores", alice.ID), &scores)
+	if assert.Len(t, scores, 2) {
+		assert.Equal(t, CompetencyScore{CompetencyID: delivery.ID, Competency: "Delivery", Period: "2024-01", Count: 2, Average: 3, Min: 2, Max: 4}, scores[0])
+		assert.Equal(t, "2024-02", scores[1].Period)
+	}
+
+	getList(t, fmt.Sprintf("/members/%d/scores?interval=all&from=2024-02-01", alice.ID), &scores)
+	if assert.Len(t, scores, 1) {
+		assert.Equal(t, 1, scores[0].Count)
+	}
+
+	getList(t, fmt.Sprintf("/teams/%d/scores?interval=year", team.ID), &scores)
+	if assert.Len(t, scores, 1) {
+		assert.Equal(t, "2024", scores[0].Period)
+		assert.Equal(t, 5, scores[0].Count)
+		assert.Equal(t, 1, scores[0].Min)
+		assert.Equal(t, 5, scores[0].Max)
+	}
+
+	w := getList(t, fmt.Sprintf("/members/%d/scores?interval=decade", alice.ID), &scores)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Only readable feedback is counted
+	w = serve("GET", fmt.Sprintf("/members/%d/scores", alice.ID), "", outsider.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &scores))
+	assert.Empty(t, scores)
+}
@@ -0,0 +1,666 @@
+package main
+
+import (
+	"cmp"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// giveFeedbackRequest holds the fields of new feedback a client chooses. The
+// giver, timestamps and review state are the server's.
+type giveFeedbackRequest struct {
+	Content       string                  `json:"content"`
+	TargetID      uint64                  `json:"targetid"`
+	TargetType    string                  `json:"targettype"`
+	ReviewCycleID *uint64                 `json:"reviewcycleid"`
+	GoalID        *uint64                 `json:"goalid"`
+	RequestID     *uint64                 `json:"requestid"`
+	TemplateID    *uint64                 `json:"templateid"`
+	Anonymous     bool                    `json:"anonymous"`
+	Visibility    string                  `json:"visibility"`
+	Category      string                  `json:"category"`
+	Scores        []models.FeedbackScore  `json:"scores"`
+	Answers       []models.FeedbackAnswer `json:"answers"`
+	Tags          []models.Tag            `json:"tags"`
+}
+
+// GiveFeedback creates a new feedback entry given by the authenticated caller.
+// Anonymous feedback is stored without the caller's ID. The visibility defaults
+// to the recipient. Answers to a template are written out in the Content.
+func (s *Server) GiveFeedback(c *gin.Context) {
+	var req giveFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	feedback := models.Feedback{
+		Content:       req.Content,
+		TargetID:      req.TargetID,
+		TargetType:    req.TargetType,
+		ReviewCycleID: req.ReviewCycleID,
+		GoalID:        req.GoalID,
+		RequestID:     req.RequestID,
+		TemplateID:    req.TemplateID,
+		Anonymous:     req.Anonymous,
+		Visibility:    req.Visibility,
+		Category:      req.Category,
+		Version:       1,
+		Scores:        req.Scores,
+		Answers:       req.Answers,
+		Tags:          req.Tags,
+	}
+
+	// Validate TargetType and TargetID
+	if feedback.TargetType != "team" && feedback.TargetType != "member" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid TargetType. Must be 'team' or 'member'."})
+		return
+	}
+
+	// The authenticated caller is always the giver
+	giverID := CurrentIdentity(c).MemberID
+	feedback.GiverID = &giverID
+
+	if _, err := s.members.Get(giverID); err != nil {
+		respondLookupError(c, err, "Giver member not found", "Error finding giver member: ")
+		return
+	}
+
+	if feedback.TargetType == "team" {
+		if _, err := s.teams.Get(feedback.TargetID); err != nil {
+			respondLookupError(c, err, "Target team not found", "Error finding target team: ")
+			return
+		}
+	} else if feedback.TargetType == "member" {
+		if _, err := s.members.Get(feedback.TargetID); err != nil {
+			respondLookupError(c, err, "Target member not found", "Error finding target member: ")
+			return
+		}
+	}
+
+	if !s.validateScores(c, feedback.Scores) {
+		return
+	}
+	if !s.applyTemplate(c, &feedback) {
+		return
+	}
+	if feedback.Category != "" && !validCategory(c, feedback.Category) {
+		return
+	}
+	if !normalizeTags(c, &feedback) {
+		return
+	}
+
+	if feedback.GoalID != nil {
+		goal, err := s.goals.Get(*feedback.GoalID)
+		if err != nil {
+			respondLookupError(c, err, "Goal not found", "Error finding goal: ")
+			return
+		}
+		if goal.OwnerType != feedback.TargetType || goal.OwnerID != feedback.TargetID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Feedback can only be linked to a goal of its target"})
+			return
+		}
+	}
+
+	feedback.Visibility = cmp.Or(feedback.Visibility, models.VisibilityRecipient)
+	if !validVisibility(c, feedback.Visibility) {
+		return
+	}
+
+	if feedback.Anonymous {
+		// A private note nobody can trace back to its author could never be read
+		if feedback.Visibility == models.VisibilityPrivate {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Anonymous feedback cannot be private"})
+			return
+		}
+		// Review assignments name their reviewer, so reviews cannot be anonymous
+		if feedback.ReviewCycleID != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Review feedback cannot be anonymous"})
+			return
+		}
+		// Neither can answers, since requests show who answered them
+		if feedback.RequestID != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Answers to feedback requests cannot be anonymous"})
+			return
+		}
+		feedback.GiverID = nil
+		feedback.GiverHash = s.giverHash(giverID)
+	}
+
+	if feedback.RequestID != nil {
+		s.answerFeedbackRequest(c, &feedback, giverID)
+		return
+	}
+	if feedback.ReviewCycleID != nil {
+		s.giveReviewFeedback(c, &feedback)
+		return
+	}
+
+	if err := s.feedback.Create(&feedback); err != nil {
+		respondCreateFeedbackError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, feedback)
+}
+
+// respondCreateFeedbackError answers the errors common to every way of storing
+// new feedback
+func respondCreateFeedbackError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrTargetNotFound) {
+		// The target was deleted since it was looked up
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feedback target not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create feedback: " + err.Error()})
+}
+
+// giverHash stands in for the giver of anonymous feedback. It is the same for
+// all the feedback of a giver, so that distinct givers can be counted, but
+// cannot be traced back to them without the anonymity key.
+func (s *Server) giverHash(giverID uint64) string {
+	mac := hmac.New(sha256.New, s.settings.AnonymityKey)
+	mac.Write([]byte(strconv.FormatUint(giverID, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GetFeedbacks retrieves one page of the feedbacks the caller may read, optionally
+// filtered by member_id or team_id, by giver_id, by review cycle_id, by goal_id, by
+// visibility, by acknowledged, by category, by tags (all of them, comma-separated),
+// by archived (false by default) and by a from/to creation date range, and sorted
+// by created_at or updated_at.
+// expand=target,giver embeds the target and the giver in each entry.
+func (s *Server) GetFeedbacks(c *gin.Context) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+
+	opts, err := parseListParams(c, "created_at", "updated_at")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, ok := s.feedbackFilterQuery(c, perms)
+	if !ok {
+		return
+	}
+
+	expandTarget, expandGiver, ok := expandQuery(c)
+	if !ok {
+		return
+	}
+
+	feedbacks, total, err := s.feedback.List(filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feedbacks: " + err.Error()})
+		return
+	}
+	if err := s.expandFeedbacks(feedbacks, expandTarget, expandGiver); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to expand feedbacks: " + err.Error()})
+		return
+	}
+	setPageHeaders(c, opts, total)
+
+	c.JSON(http.StatusOK, feedbacks)
+}
+
+// expandQuery reads the comma-separated expand query parameter of feedback
+// lists. It answers 400 and returns false when it names anything but target
+// and giver.
+func expandQuery(c *gin.Context) (target, giver, ok bool) {
+	if c.Query("expand") == "" {
+		return false, false, true
+	}
+	for _, field := range strings.Split(c.Query("expand"), ",") {
+		switch field {
+		case "target":
+			target = true
+		case "giver":
+			giver = true
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expand. Must be among target, giver."})
+			return false, false, false
+		}
+	}
+	return target, giver, true
+}
+
+// expandFeedbacks fills in the Target and Giver of the feedbacks, looking all
+// the members up at once and all the teams at once. They stay nil for
+// anonymous feedback and for members or teams that no longer exist.
+func (s *Server) expandFeedbacks(feedbacks []models.Feedback, target, giver bool) error {
+	var memberIDs, teamIDs []uint64
+	for _, feedback := range feedbacks {
+		if target && feedback.TargetType == "team" {
+			teamIDs = append(teamIDs, feedback.TargetID)
+		} else if target {
+			memberIDs = append(memberIDs, feedback.TargetID)
+		}
+		if giver && feedback.GiverID != nil {
+			memberIDs = append(memberIDs, *feedback.GiverID)
+		}
+	}
+	slices.Sort(memberIDs)
+	memberIDs = slices.Compact(memberIDs)
+	slices.Sort(teamIDs)
+	teamIDs = slices.Compact(teamIDs)
+
+	parties := map[string]map[uint64]*models.FeedbackParty{"member": {}, "team": {}}
+	if len(memberIDs) > 0 {
+		members, _, err := s.members.List(repository.MemberFilter{IDs: memberIDs}, repository.ListOptions{Page: 1, PageSize: len(memberIDs)})
+		if err != nil {
+			return err
+		}
+		for _, member := range members {
+			parties["member"][member.ID] = &models.FeedbackParty{Type: "member", ID: member.ID, Name: member.Name, PictureURL: member.PictureURL}
+		}
+	}
+	if len(teamIDs) > 0 {
+		teams, _, err := s.teams.List(repository.TeamFilter{IDs: teamIDs}, repository.ListOptions{Page: 1, PageSize: len(teamIDs)})
+		if err != nil {
+			return err
+		}
+		for _, team := range teams {
+			parties["team"][team.ID] = &models.FeedbackParty{Type: "team", ID: team.ID, Name: team.Name, PictureURL: team.LogoURL}
+		}
+	}
+
+	for i, feedback := range feedbacks {
+		if target && parties[feedback.TargetType] != nil {
+			feedbacks[i].Target = parties[feedback.TargetType][feedback.TargetID]
+		}
+		if giver && feedback.GiverID != nil {
+			feedbacks[i].Giver = parties["member"][*feedback.GiverID]
+		}
+	}
+	return nil
+}
+
+// feedbackFilterQuery reads the feedback filters shared by the feedback list and
+// search from the query parameters, limited to what the caller may read. With
+// rollup=true, team_id also selects the feedback about the teams below the team.
+// It answers and returns false when one of them is invalid.
+func (s *Server) feedbackFilterQuery(c *gin.Context, perms *Permissions) (repository.FeedbackFilter, bool) {
+	filter := repository.FeedbackFilter{Reader: perms.FeedbackReader()}
+
+	memberID, err := optionalIDQuery(c, "member_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	teamID, err := optionalIDQuery(c, "team_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	if memberID != nil {
+		filter.TargetType, filter.TargetID = "member", memberID
+	} else if teamID != nil {
+		teamIDs, ok := s.rollupQuery(c, *teamID)
+		if !ok {
+			return filter, false
+		}
+		filter.TargetType, filter.TargetIDs = "team", teamIDs
+	}
+
+	if filter.GiverID, err = optionalIDQuery(c, "giver_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	if filter.CycleID, err = optionalIDQuery(c, "cycle_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	if filter.GoalID, err = optionalIDQuery(c, "goal_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	if filter.Visibility = c.Query("visibility"); filter.Visibility != "" && !validVisibility(c, filter.Visibility) {
+		return filter, false
+	}
+
+	if filter.Acknowledged, err = optionalBoolQuery(c, "acknowledged"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	if filter.Category = c.Query("category"); filter.Category != "" && !validCategory(c, filter.Category) {
+		return filter, false
+	}
+	filter.Tags = tagsQuery(c)
+
+	// Feedback about deleted members and teams is only listed on request
+	if filter.Archived, err = optionalBoolQuery(c, "archived"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	if filter.Archived == nil {
+		filter.Archived = new(bool)
+	}
+
+	if filter.CreatedFrom, filter.CreatedBefore, err = dateRangeQuery(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+
+	includeDeleted, err := optionalBoolQuery(c, "include_deleted")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	if includeDeleted != nil && *includeDeleted {
+		if !perms.Admin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can list deleted feedback"})
+			return filter, false
+		}
+		filter.IncludeDeleted = true
+	}
+	return filter, true
+}
+
+// validVisibility answers 400 and returns false unless visibility is a known feedback visibility
+func validVisibility(c *gin.Context, visibility string) bool {
+	if !slices.Contains(models.FeedbackVisibilities, visibility) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Visibility. Must be one of " + strings.Join(models.FeedbackVisibilities, ", ") + "."})
+		return false
+	}
+	return true
+}
+
+type visibilityRequest struct {
+	Visibility string `json:"visibility" binding:"required"`
+}
+
+// SetFeedbackVisibility changes who can read a feedback entry. Only its author
+// can, and every change is recorded.
+func (s *Server) SetFeedbackVisibility(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	feedback, err := s.feedback.Get(id)
+	if err != nil {
+		respondLookupError(c, err, "Feedback not found", "")
+		return
+	}
+	callerID := CurrentIdentity(c).MemberID
+	if !s.isAuthor(callerID, feedback) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can change the visibility of feedback"})
+		return
+	}
+
+	var req visibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validVisibility(c, req.Visibility) {
+		return
+	}
+	if feedback.Anonymous && req.Visibility == models.VisibilityPrivate {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Anonymous feedback cannot be private"})
+		return
+	}
+
+	if req.Visibility != feedback.Visibility {
+		change := models.VisibilityChange{
+			FeedbackID:    feedback.ID,
+			ChangedByID:   changedBy(feedback, callerID),
+			OldVisibility: feedback.Visibility,
+			NewVisibility: req.Visibility,
+		}
+		if err := s.feedback.SetVisibility(&change); err != nil {
+			respondLookupError(c, err, "Feedback not found", "Failed to change visibility: ")
+			return
+		}
+		feedback.Visibility = req.Visibility
+	}
+	c.JSON(http.StatusOK, feedback)
+}
+
+// GetFeedbackVisibilityChanges lists the visibility changes of a feedback
+// entry, oldest first. They are available to its author and to admins.
+func (s *Server) GetFeedbackVisibilityChanges(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	feedback, err := s.feedback.Get(id)
+	if err != nil {
+		respondLookupError(c, err, "Feedback not found", "")
+		return
+	}
+	if !perms.Admin && !s.isAuthor(perms.MemberID, feedback) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can see the visibility changes of feedback"})
+		return
+	}
+
+	changes, err := s.feedback.ListVisibilityChanges(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve visibility changes: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+// isAuthor reports whether the member gave the feedback. The author of
+// anonymous feedback is recognized by their giver hash.
+func (s *Server) isAuthor(memberID uint64, feedback *models.Feedback) bool {
+	if feedback.Anonymous {
+		return hmac.Equal([]byte(feedback.GiverHash), []byte(s.giverHash(memberID)))
+	}
+	return feedback.GiverID != nil && *feedback.GiverID == memberID
+}
+
+// changedBy is who edits or retracts the feedback as recorded in its history:
+// nobody for anonymous feedback
+func changedBy(feedback *models.Feedback, memberID uint64) *uint64 {
+	if feedback.Anonymous {
+		return nil
+	}
+	return &memberID
+}
+
+// requireEditable answers and returns false unless the caller is the author
+// of the feedback, the feedback is not retracted and the edit window is still open
+func (s *Server) requireEditable(c *gin.Context, feedback *models.Feedback) bool {
+	if !s.isAuthor(CurrentIdentity(c).MemberID, feedback) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can change feedback"})
+		return false
+	}
+	if feedback.RetractedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Feedback was retracted"})
+		return false
+	}
+	if time.Since(feedback.CreatedAt) > s.settings.FeedbackEditWindow {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Feedback can no longer be changed"})
+		return false
+	}
+	return true
+}
+
+type feedbackUpdateRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// UpdateFeedback replaces the content of a feedback entry. Only its author can,
+// within the edit window, and the replaced content is kept in its history.
+// Feedback answering a template is written out from its answers, so it cannot
+// be edited; it is retracted and given again instead.
+func (s *Server) UpdateFeedback(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	feedback, err := s.feedback.Get(id)
+	if err != nil {
+		respondLookupError(c, err, "Feedback not found", "")
+		return
+	}
+	if !s.requireEditable(c, feedback) {
+		return
+	}
+	if feedback.TemplateID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Feedback answering a template cannot be edited. Retract it and give it again."})
+		return
+	}
+
+	var req feedbackUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Content must not be blank"})
+		return
+	}
+	if req.Content == feedback.Content {
+		c.JSON(http.StatusOK, feedback)
+		return
+	}
+
+	updated, err := s.feedback.Revise(id, req.Content, changedBy(feedback, CurrentIdentity(c).MemberID))
+	if err != nil {
+		respondLookupError(c, err, "Feedback not found", "Failed to update feedback: ")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// RetractFeedback withdraws a feedback entry. The entry stays as a tombstone
+// without content or scores; the retracted content is kept in its history.
+// Authors can retract within the edit window, admins at any time.
+func (s *Server) RetractFeedback(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	feedback, err := s.feedback.Get(id)
+	if err != nil {
+		respondLookupError(c, err, "Feedback not found", "")
+		return
+	}
+	if !perms.Admin && !s.requireEditable(c, feedback) {
+		return
+	}
+	if feedback.RetractedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Feedback was retracted"})
+		return
+	}
+
+	retracted, err := s.feedback.Retract(id, changedBy(feedback, perms.MemberID), time.Now())
+	if err != nil {
+		respondLookupError(c, err, "Feedback not found", "Failed to retract feedback: ")
+		return
+	}
+	c.JSON(http.StatusOK, retracted)
+}
+
+// RestoreFeedback brings back feedback deleted along with its member or team,
+// which must have been restored first. Only admins can restore feedback; the
+// entry is not returned since it may be private to its author.
+func (s *Server) RestoreFeedback(c *gin.Context) {
+	if !s.requireAdmin(c) {
+		return
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.feedback.Restore(id); err != nil {
+		if errors.Is(err, repository.ErrTargetNotFound) {
+			c.JSON(http.StatusConflict, gin.H{"error": "The member or team of the feedback is deleted; restore it first"})
+			return
+		}
+		respondLookupError(c, err, "Feedback not found", "Failed to restore feedback: ")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetFeedbackHistory lists the earlier versions of a feedback entry, oldest
+// first; the current version is the entry itself. It is only available to admins,
+// and only for the feedback they may read.
+func (s *Server) GetFeedbackHistory(c *gin.Context) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	if !perms.Admin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This action requires the admin role"})
+		return
+	}
+	feedback := s.readableFeedbackParam(c, perms)
+	if feedback == nil {
+		return
+	}
+
+	versions, err := s.feedback.ListVersions(feedback.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feedback history: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, versions)
+}
+
+// dateRangeQuery reads the optional from/to query parameters as an inclusive
+// lower bound and an exclusive upper bound. A plain date as 'to' includes the
+// whole day, a timestamp includes itself.
+func dateRangeQuery(c *gin.Context) (from, before *time.Time, err error) {
+	if value := c.Query("from"); value != "" {
+		fromTime, _, err := parseDateParam(value)
+		if err != nil {
+			return nil, nil, errors.New("Invalid 'from' date: " + err.Error())
+		}
+		from = &fromTime
+	}
+
+	if value := c.Query("to"); value != "" {
+		toTime, dateOnly, err := parseDateParam(value)
+		if err != nil {
+			return nil, nil, errors.New("Invalid 'to' date: " + err.Error())
+		}
+		if dateOnly {
+			toTime = toTime.AddDate(0, 0, 1)
+		} else {
+			toTime = toTime.Add(time.Nanosecond)
+		}
+		before = &toTime
+	}
+
+	return from, before, nil
+}
+
+// parseDateParam accepts either an RFC3339 timestamp or a plain YYYY-MM-DD date.
+// The second return value reports whether the input was a plain date.
+func parseDateParam(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, false, errors.New("expected RFC3339 timestamp or YYYY-MM-DD date")
+	}
+	return t, true, nil
+}
@@ -0,0 +1,507 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGiveAnonymousFeedback(t *testing.T) {
+	setupTestDatabase()
+
+	target := models.TeamMember{Name: "Target", Email: "target@example.com"}
+	giver := models.TeamMember{Name: "Giver", Email: "giver@example.com"}
+	testDB.Create(&target)
+	testDB.Create(&giver)
+
+	payload := fmt.Sprintf(`{"content": "Honest take", "targetid": %d, "targettype": "member", "anonymous": true}`, target.ID)
+	w := serve("POST", "/feedback/", payload, giver.ID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NotContains(t, w.Body.String(), fmt.Sprintf(`"GiverID":%d`, giver.ID))
+
+	var stored models.Feedback
+	assert.NoError(t, testDB.First(&stored).Error)
+	assert.Nil(t, stored.GiverID)
+	assert.True(t, stored.Anonymous)
+	assert.Len(t, stored.GiverHash, 64)
+
+	// Neither the target nor an admin can find out who gave it
+	for _, caller := range []uint64{target.ID, testCallerID} {
+		w = serve("GET", "/feedback/", "", caller)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, strings.Contains(w.Body.String(), stored.GiverHash))
+		var feedbacks []models.Feedback
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &feedbacks))
+		if assert.Len(t, feedbacks, 1) {
+			assert.Nil(t, feedbacks[0].GiverID)
+			assert.True(t, feedbacks[0].Anonymous)
+		}
+	}
+
+	var feedbacks []models.Feedback
+	getList(t, fmt.Sprintf("/feedback/?giver_id=%d", giver.ID), &feedbacks)
+	assert.Empty(t, feedbacks)
+}
+
+func TestTeamScoresSuppressedBelowMinimumGivers(t *testing.T) {
+	setupTestDatabase()
+	previous := GlobalTestRouter
+	GlobalTestRouter = setupRouterForTests(repository.NewGormRepositories(testDB), Settings{AnonymityKey: []byte("key"), MinAnonymousGivers: 3})
+	defer func() { GlobalTestRouter = previous }()
+
+	members := make([]models.TeamMember, 4)
+	for i := range members {
+		members[i] = models.TeamMember{Name: fmt.Sprintf("Member %d", i), Email: fmt.Sprintf("member%d@example.com", i)}
+		testDB.Create(&members[i])
+	}
+	team := models.Team{Name: "Small", Members: members[:2]}
+	testDB.Create(&team)
+	delivery := models.Competency{Name: "Delivery", MinScore: 1, MaxScore: 5, Active: true}
+	testDB.Create(&delivery)
+
+	give := func(giver models.TeamMember, score int) {
+		payload := fmt.Sprintf(`{"content": "Scored", "targetid": %d, "targettype": "team", "anonymous": true,
+			"scores": [{"competencyid": %d, "score": %d}]}`, team.ID, delivery.ID, score)
+		assert.Equal(t, http.StatusCreated, serve("POST", "/feedback/", payload, giver.ID).Code)
+	}
+	give(members[0], 2)
+	give(members[0], 3)
+	give(members[1], 4)
+
+	var scores []CompetencyScore
+	getList(t, fmt.Sprintf("/teams/%d/scores?interval=all", team.ID), &scores)
+	if assert.Len(t, scores, 1) {
+		assert.Equal(t, CompetencyScore{CompetencyID: delivery.ID, Competency: "Delivery", Period: "all", Suppressed: true}, scores[0])
+	}
+
+	give(members[2], 5)
+	getList(t, fmt.Sprintf("/teams/%d/scores?interval=all", team.ID), &scores)
+	if assert.Len(t, scores, 1) {
+		assert.False(t, scores[0].Suppressed)
+		assert.Equal(t, 4, scores[0].Count)
+		assert.Equal(t, 3.5, scores[0].Average)
+	}
+}
+
+func TestTeamTagCountsSuppressedBelowMinimumGivers(t *testing.T) {
+	setupTestDatabase()
+	previous := GlobalTestRouter
+	GlobalTestRouter = setupRouterForTests(repository.NewGormRepositories(testDB), Settings{AnonymityKey: []byte("key"), MinAnonymousGivers: 3})
+	defer func() { GlobalTestRouter = previous }()
+
+	members := make([]models.TeamMember, 3)
+	for i := range members {
+		members[i] = models.TeamMember{Name: fmt.Sprintf("Member %d", i), Email: fmt.Sprintf("member%d@example.com", i)}
+		testDB.Create(&members[i])
+	}
+	team := models.Team{Name: "Small", Members: members[:2]}
+	testDB.Create(&team)
+
+	give := func(giver models.TeamMember, tags string) {
+		payload := fmt.Sprintf(`{"content": "Tagged", "targetid": %d, "targettype": "team", "anonymous": true, "tags": %s}`, team.ID, tags)
+		assert.Equal(t, http.StatusCreated, serve("POST", "/feedback/", payload, giver.ID).Code)
+	}
+	give(members[0], `[{"name": "morale"}, {"name": "tooling"}]`)
+	give(members[0], `[{"name": "morale"}]`)
+	give(members[1], `[{"name": "morale"}]`)
+	give(members[2], `[{"name": "morale"}]`)
+
+	var counts []TagCount
+	getList(t, fmt.Sprintf("/teams/%d/tags", team.ID), &counts)
+	assert.Equal(t, []TagCount{{Tag: "morale", Count: 4}, {Tag: "tooling", Suppressed: true}}, counts)
+}
+
+func TestReviewFeedbackCannotBeAnonymous(t *testing.T) {
+	setupTestDatabase()
+
+	target := models.TeamMember{Name: "Target", Email: "target@example.com"}
+	testDB.Create(&target)
+	giver := models.TeamMember{Name: "Giver", Email: "giver@example.com"}
+	testDB.Create(&giver)
+
+	payload := fmt.Sprintf(`{"content": "Review", "targetid": %d, "targettype": "member", "anonymous": true, "reviewcycleid": 1}`, target.ID)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/feedback/", payload, giver.ID).Code)
+}
+
+func TestFeedbackVisibilityChanges(t *testing.T) {
+	setupTestDatabase()
+
+	coach := models.TeamMember{Name: "Coach", Email: "coach@example.com"}
+	target := models.TeamMember{Name: "Target", Email: "target@example.com"}
+	testDB.Create(&coach)
+	testDB.Create(&target)
+	testDB.Create(&models.MemberRole{MemberID: coach.ID, Role: models.RoleCoach})
+
+	give := func(payload string) int {
+		return serve("POST", "/feedback/", fmt.Sprintf(payload, target.ID), coach.ID).Code
+	}
+	assert.Equal(t, http.StatusBadRequest, give(`{"content": "Draft", "targetid": %d, "targettype": "member", "visibility": "everyone"}`))
+	assert.Equal(t, http.StatusBadRequest, give(`{"content": "Draft", "targetid": %d, "targettype": "member", "visibility": "private", "anonymous": true}`))
+	assert.Equal(t, http.StatusCreated, give(`{"content": "Draft", "targetid": %d, "targettype": "member", "visibility": "private"}`))
+
+	var draft models.Feedback
+	testDB.First(&draft)
+	fetch := func(memberID uint64) []models.Feedback {
+		var feedbacks []models.Feedback
+		w := serve("GET", "/feedback/", "", memberID)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &feedbacks))
+		return feedbacks
+	}
+	assert.Empty(t, fetch(target.ID))
+	assert.Empty(t, fetch(testCallerID))
+	assert.Len(t, fetch(coach.ID), 1)
+
+	path := fmt.Sprintf("/feedback/%d/visibility", draft.ID)
+	assert.Equal(t, http.StatusForbidden, serve("PUT", path, `{"visibility": "recipient"}`, target.ID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("PUT", path, `{"visibility": "public"}`, coach.ID).Code)
+	assert.Equal(t, http.StatusNotFound, serve("PUT", "/feedback/99999/visibility", `{"visibility": "recipient"}`, coach.ID).Code)
+	w := serve("PUT", path, `{"visibility": "recipient"}`, coach.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, fetch(target.ID), 1)
+
+	assert.Equal(t, http.StatusForbidden, serve("GET", path, "", target.ID).Code)
+	var changes []models.VisibilityChange
+	w = serve("GET", path, "", testCallerID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &changes))
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, models.VisibilityPrivate, changes[0].OldVisibility)
+		assert.Equal(t, models.VisibilityRecipient, changes[0].NewVisibility)
+		assert.Equal(t, &coach.ID, changes[0].ChangedByID)
+	}
+
+	// The author of anonymous feedback changes it too, without being recorded
+	assert.Equal(t, http.StatusCreated, give(`{"content": "Unsigned", "targetid": %d, "targettype": "member", "anonymous": true}`))
+	var unsigned models.Feedback
+	testDB.Where("anonymous = ?", true).First(&unsigned)
+	path = fmt.Sprintf("/feedback/%d/visibility", unsigned.ID)
+	assert.Equal(t, http.StatusForbidden, serve("PUT", path, `{"visibility": "team"}`, target.ID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("PUT", path, `{"visibility": "private"}`, coach.ID).Code)
+	assert.Equal(t, http.StatusOK, serve("PUT", path, `{"visibility": "team"}`, coach.ID).Code)
+	w = serve("GET", path, "", coach.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &changes))
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, models.VisibilityTeam, changes[0].NewVisibility)
+		assert.Nil(t, changes[0].ChangedByID)
+	}
+}
+
+func TestFeedbackThread(t *testing.T) {
+	setupTestDatabase()
+
+	giver := models.TeamMember{Name: "Giver", Email: "giver@example.com"}
+	target := models.TeamMember{Name: "Target", Email: "target@example.com"}
+	outsider := models.TeamMember{Name: "Outsider", Email: "outsider@example.com"}
+	testDB.Create(&giver)
+	testDB.Create(&target)
+	testDB.Create(&outsider)
+	feedback := models.Feedback{Content: "Clear slides", TargetType: "member", TargetID: target.ID, GiverID: &giver.ID, Visibility: models.VisibilityRecipient}
+	testDB.Create(&feedback)
+	path := fmt.Sprintf("/feedback/%d", feedback.ID)
+
+	var feedbacks []models.Feedback
+	getList(t, "/feedback/?acknowledged=false", &feedbacks)
+	assert.Len(t, feedbacks, 1)
+	assert.Equal(t, http.StatusBadRequest, serve("GET", "/feedback/?acknowledged=maybe", "", testCallerID).Code)
+
+	// Only the recipient acknowledges, and only once
+	assert.Equal(t, http.StatusForbidden, serve("POST", path+"/acknowledge", "", giver.ID).Code)
+	assert.Equal(t, http.StatusNotFound, serve("POST", path+"/acknowledge", "", outsider.ID).Code)
+	w := serve("POST", path+"/acknowledge", "", target.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var acknowledged models.Feedback
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &acknowledged))
+	if assert.NotNil(t, acknowledged.AcknowledgedAt) {
+		w = serve("POST", path+"/acknowledge", "", target.ID)
+		var again models.Feedback
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &again))
+		assert.True(t, acknowledged.AcknowledgedAt.Equal(*again.AcknowledgedAt))
+	}
+	assert.Equal(t, &target.ID, acknowledged.AcknowledgedByID)
+	getList(t, "/feedback/?acknowledged=false", &feedbacks)
+	assert.Empty(t, feedbacks)
+	getList(t, "/feedback/?acknowledged=true", &feedbacks)
+	assert.Len(t, feedbacks, 1)
+
+	assert.Equal(t, http.StatusCreated, serve("POST", path+"/replies", `{"content": "Thanks, which part?"}`, target.ID).Code)
+	assert.Equal(t, http.StatusCreated, serve("POST", path+"/replies", `{"content": "The timeline"}`, giver.ID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", path+"/replies", `{"content": "  "}`, giver.ID).Code)
+	assert.Equal(t, http.StatusNotFound, serve("POST", path+"/replies", `{"content": "Me too"}`, outsider.ID).Code)
+	var replies []models.FeedbackReply
+	w = serve("GET", path+"/replies", "", target.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &replies))
+	if assert.Len(t, replies, 2) {
+		assert.Equal(t, &target.ID, replies[0].AuthorID)
+		assert.Equal(t, "The timeline", replies[1].Content)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, serve("POST", path+"/reactions", `{"kind": "meh"}`, target.ID).Code)
+	assert.Equal(t, http.StatusCreated, serve("POST", path+"/reactions", `{"kind": "thanks"}`, target.ID).Code)
+	assert.Equal(t, http.StatusConflict, serve("POST", path+"/reactions", `{"kind": "thanks"}`, target.ID).Code)
+	assert.Equal(t, http.StatusCreated, serve("POST", path+"/reactions", `{"kind": "celebrate"}`, giver.ID).Code)
+	assert.Equal(t, http.StatusNoContent, serve("DELETE", path+"/reactions/celebrate", "", giver.ID).Code)
+	assert.Equal(t, http.StatusNotFound, serve("DELETE", path+"/reactions/celebrate", "", giver.ID).Code)
+	var reactions []models.FeedbackReaction
+	w = serve("GET", path+"/reactions", "", giver.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &reactions))
+	if assert.Len(t, reactions, 1) {
+		assert.Equal(t, models.FeedbackReaction{ID: reactions[0].ID, FeedbackID: feedback.ID, MemberID: target.ID, Kind: models.ReactionThanks, CreatedAt: reactions[0].CreatedAt}, reactions[0])
+	}
+}
+
+func TestAcknowledgeTeamFeedback(t *testing.T) {
+	setupTestDatabase()
+
+	member := models.TeamMember{Name: "Member", Email: "member@example.com"}
+	testDB.Create(&member)
+	team := models.Team{Name: "Team", Members: []models.TeamMember{member}}
+	testDB.Create(&team)
+	feedback := models.Feedback{Content: "Good sprint", TargetType: "team", TargetID: team.ID, Visibility: models.VisibilityRecipient}
+	testDB.Create(&feedback)
+
+	assert.Equal(t, http.StatusForbidden, serve("POST", fmt.Sprintf("/feedback/%d/acknowledge", feedback.ID), "", testCallerID).Code)
+	assert.Equal(t, http.StatusOK, serve("POST", fmt.Sprintf("/feedback/%d/acknowledge", feedback.ID), "", member.ID).Code)
+}
+
+func TestEditAndRetractFeedback(t *testing.T) {
+	setupTestDatabase()
+
+	giver := models.TeamMember{Name: "Giver", Email: "giver@example.com"}
+	target := models.TeamMember{Name: "Target", Email: "target@example.com"}
+	testDB.Create(&giver)
+	testDB.Create(&target)
+	feedback := models.Feedback{Content: "Great talk", TargetType: "member", TargetID: target.ID, GiverID: &giver.ID, Visibility: models.VisibilityRecipient}
+	testDB.Create(&feedback)
+	old := models.Feedback{Content: "Last quarter", TargetType: "member", TargetID: target.ID, GiverID: &giver.ID, Visibility: models.VisibilityRecipient, CreatedAt: time.Now().Add(-2 * time.Hour)}
+	testDB.Create(&old)
+	path := fmt.Sprintf("/feedback/%d", feedback.ID)
+
+	assert.Equal(t, http.StatusForbidden, serve("PUT", path, `{"content": "Hijacked"}`, target.ID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("PUT", path, `{"content": " "}`, giver.ID).Code)
+	assert.Equal(t, http.StatusForbidden, serve("PUT", fmt.Sprintf("/feedback/%d", old.ID), `{"content": "Too late"}`, giver.ID).Code)
+
+	w := serve("PUT", path, `{"content": "Great talk, clear demo"}`, giver.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var updated models.Feedback
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, "Great talk, clear demo", updated.Content)
+	assert.Equal(t, 2, updated.Version)
+
+	assert.Equal(t, http.StatusForbidden, serve("DELETE", path, "", target.ID).Code)
+	w = serve("DELETE", path, "", giver.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var tombstone models.Feedback
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &tombstone))
+	assert.NotNil(t, tombstone.RetractedAt)
+	assert.Empty(t, tombstone.Content)
+	assert.Equal(t, http.StatusConflict, serve("PUT", path, `{"content": "Back"}`, giver.ID).Code)
+	assert.Equal(t, http.StatusConflict, serve("DELETE", path, "", giver.ID).Code)
+
+	// The tombstone stays in the list
+	var feedbacks []models.Feedback
+	getList(t, fmt.Sprintf("/feedback/?member_id=%d", target.ID), &feedbacks)
+	assert.Len(t, feedbacks, 2)
+
+	assert.Equal(t, http.StatusForbidden, serve("GET", path+"/history", "", giver.ID).Code)
+	var versions []models.FeedbackVersion
+	w = serve("GET", path+"/history", "", testCallerID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &versions))
+	if assert.Len(t, versions, 2) {
+		assert.Equal(t, "Great talk", versions[0].Content)
+		assert.Equal(t, "Great talk, clear demo", versions[1].Content)
+		assert.Equal(t, &giver.ID, versions[1].ChangedByID)
+	}
+
+	// Admins retract outside the edit window
+	assert.Equal(t, http.StatusOK, serve("DELETE", fmt.Sprintf("/feedback/%d", old.ID), "", testCallerID).Code)
+}
+
+func TestEditAnonymousFeedback(t *testing.T) {
+	setupTestDatabase()
+
+	giver := models.TeamMember{Name: "Giver", Email: "giver@example.com"}
+	target := models.TeamMember{Name: "Target", Email: "target@example.com"}
+	testDB.Create(&giver)
+	testDB.Create(&target)
+
+	payload := fmt.Sprintf(`{"content": "Honest take", "targetid": %d, "targettype": "member", "anonymous": true}`, target.ID)
+	var feedback models.Feedback
+	w := serve("POST", "/feedback/", payload, giver.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &feedback))
+
+	path := fmt.Sprintf("/feedback/%d", feedback.ID)
+	assert.Equal(t, http.StatusForbidden, serve("PUT", path, `{"content": "Guess"}`, target.ID).Code)
+	assert.Equal(t, http.StatusOK, serve("PUT", path, `{"content": "Honest, kind take"}`, giver.ID).Code)
+
+	var versions []models.FeedbackVersion
+	w = serve("GET", path+"/history", "", testCallerID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &versions))
+	if assert.Len(t, versions, 1) {
+		assert.Nil(t, versions[0].ChangedByID)
+	}
+}
+
+func TestFeedbackTagsAndCategories(t *testing.T) {
+	setupTestDatabase()
+
+	target := models.TeamMember{Name: "Target", Email: "target@example.com"}
+	giver := models.TeamMember{Name: "Giver", Email: "giver@example.com"}
+	testDB.Create(&target)
+	testDB.Create(&giver)
+
+	payload := fmt.Sprintf(`{"content": "Clear demo", "targetid": %d, "targettype": "member", "category": "praise", "tags": [{"name": " Communication "}, {"name": "communication"}, {"name": "demo"}]}`, target.ID)
+	w := serve("POST", "/feedback/", payload, giver.ID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created models.Feedback
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	if assert.Len(t, created.Tags, 2) {
+		assert.Equal(t, "communication", created.Tags[0].Name)
+	}
+
+	payload = fmt.Sprintf(`{"content": "Slow reviews", "targetid": %d, "targettype": "member", "category": "improvement", "tags": [{"name": "communication"}]}`, target.ID)
+	assert.Equal(t, http.StatusCreated, serve("POST", "/feedback/", payload, giver.ID).Code)
+
+	payload = fmt.Sprintf(`{"content": "Hmm", "targetid": %d, "targettype": "member", "category": "rant"}`, target.ID)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/feedback/", payload, giver.ID).Code)
+	payload = fmt.Sprintf(`{"content": "Hmm", "targetid": %d, "targettype": "member", "tags": [{"name": "  "}]}`, target.ID)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/feedback/", payload, giver.ID).Code)
+
+	var feedbacks []models.Feedback
+	getList(t, "/feedback/?tags=Communication,demo", &feedbacks)
+	if assert.Len(t, feedbacks, 1) {
+		assert.Equal(t, "Clear demo", feedbacks[0].Content)
+	}
+	getList(t, fmt.Sprintf("/feedback/?member_id=%d&category=improvement", target.ID), &feedbacks)
+	if assert.Len(t, feedbacks, 1) {
+		assert.Equal(t, "Slow reviews", feedbacks[0].Content)
+	}
+	assert.Equal(t, http.StatusBadRequest, serve("GET", "/feedback/?category=rant", "", testCallerID).Code)
+
+	w = serve("GET", "/feedback/tags?prefix=COM", "", giver.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var tags []models.Tag
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &tags))
+	if assert.Len(t, tags, 1) {
+		assert.Equal(t, "communication", tags[0].Name)
+	}
+	assert.Equal(t, http.StatusBadRequest, serve("GET", "/feedback/tags?limit=0", "", giver.ID).Code)
+
+	// The tags of a private note are not suggested to others
+	payload = fmt.Sprintf(`{"content": "Note to self", "targetid": %d, "targettype": "member", "visibility": "private", "tags": [{"name": "confidential"}]}`, target.ID)
+	assert.Equal(t, http.StatusCreated, serve("POST", "/feedback/", payload, giver.ID).Code)
+	suggested := func(memberID uint64) []models.Tag {
+		var tags []models.Tag
+		w := serve("GET", "/feedback/tags?prefix=conf", "", memberID)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &tags))
+		return tags
+	}
+	assert.Len(t, suggested(giver.ID), 1)
+	assert.Empty(t, suggested(target.ID))
+	assert.Empty(t, suggested(testCallerID))
+
+	w = serve("GET", fmt.Sprintf("/members/%d/tags", target.ID), "", testCallerID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var counts []TagCount
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &counts))
+	assert.Equal(t, []TagCount{{Tag: "communication", Count: 2}, {Tag: "demo", Count: 1}}, counts)
+	assert.Equal(t, http.StatusNotFound, serve("GET", "/members/99999/tags", "", testCallerID).Code)
+	assert.Equal(t, http.StatusNotFound, serve("GET", "/teams/99999/tags", "", testCallerID).Code)
+}
+
+func TestExpandFeedbacks(t *testing.T) {
+	setupTestDatabase()
+
+	members := make([]models.TeamMember, 3)
+	for i := range members {
+		members[i] = models.TeamMember{Name: fmt.Sprintf("Member %d", i), Email: fmt.Sprintf("member%d@example.com", i), PictureURL: fmt.Sprintf("https://example.com/%d.png", i)}
+		testDB.Create(&members[i])
+	}
+	team := models.Team{Name: "Team", LogoURL: "https://example.com/team.png"}
+	testDB.Create(&team)
+	for i := range members {
+		testDB.Create(&models.Feedback{Content: "To member", TargetType: "member", TargetID: members[i].ID, GiverID: &members[(i+1)%3].ID})
+		testDB.Create(&models.Feedback{Content: "To team", TargetType: "team", TargetID: team.ID, GiverID: &members[i].ID})
+	}
+	testDB.Create(&models.Feedback{Content: "Anonymous", TargetType: "team", TargetID: team.ID, Anonymous: true})
+
+	// Members and teams are looked up once each, however many entries there are
+	queries := 0
+	testDB.Callback().Query().After("gorm:query").Register("test:count_queries", func(*gorm.DB) { queries++ })
+	defer testDB.Callback().Query().Remove("test:count_queries")
+
+	var feedbacks []models.Feedback
+	extraQueries := func() int {
+		queries = 0
+		getList(t, "/feedback/?sort=created_at", &feedbacks)
+		plain := queries
+		queries = 0
+		getList(t, "/feedback/?expand=target,giver&sort=created_at", &feedbacks)
+		return queries - plain
+	}
+	before := extraQueries()
+	for i := range 5 {
+		member := models.TeamMember{Name: fmt.Sprintf("Other %d", i), Email: fmt.Sprintf("other%d@example.com", i)}
+		testDB.Create(&member)
+		testDB.Create(&models.Feedback{Content: "More", TargetType: "member", TargetID: member.ID, GiverID: &members[0].ID})
+	}
+	assert.Equal(t, before, extraQueries())
+	testDB.Where("content = ?", "More").Delete(&models.Feedback{})
+
+	getList(t, "/feedback/?expand=target,giver&sort=created_at", &feedbacks)
+	if assert.Len(t, feedbacks, 7) {
+		assert.Equal(t, &models.FeedbackParty{Type: "member", ID: members[0].ID, Name: "Member 0", PictureURL: "https://example.com/0.png"}, feedbacks[0].Target)
+		assert.Equal(t, &models.FeedbackParty{Type: "member", ID: members[1].ID, Name: "Member 1", PictureURL: "https://example.com/1.png"}, feedbacks[0].Giver)
+		assert.Equal(t, &models.FeedbackParty{Type: "team", ID: team.ID, Name: "Team", PictureURL: "https://example.com/team.png"}, feedbacks[1].Target)
+		assert.NotNil(t, feedbacks[6].Target)
+		assert.Nil(t, feedbacks[6].Giver)
+	}
+
+	w := serve("GET", "/feedback/?expand=target", "", testCallerID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"Giver"`)
+	assert.Equal(t, http.StatusBadRequest, serve("GET", "/feedback/?expand=answers", "", testCallerID).Code)
+}
+
+func TestGiveFeedbackIgnoresServerFields(t *testing.T) {
+	setupTestDatabase()
+
+	giver := models.TeamMember{Name: "Giver", Email: "giver@example.com"}
+	testDB.Create(&giver)
+	target := models.TeamMember{Name: "Target", Email: "target@example.com"}
+	testDB.Create(&target)
+
+	payload := fmt.Sprintf(`{"content": "Forged", "targetid": %d, "targettype": "member",
+		"acknowledgedat": "2024-01-01T00:00:00Z", "acknowledgedbyid": %d,
+		"version": 42, "retractedat": "2024-01-01T00:00:00Z",
+		"archivedat": "2024-01-01T00:00:00Z", "deletedat": "2024-01-01T00:00:00Z"}`, target.ID, target.ID)
+	w := serve("POST", "/feedback/", payload, giver.ID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created models.Feedback
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	var stored models.Feedback
+	assert.NoError(t, testDB.First(&stored, created.ID).Error)
+	assert.Nil(t, stored.AcknowledgedAt)
+	assert.Nil(t, stored.AcknowledgedByID)
+	assert.Equal(t, 1, stored.Version)
+	assert.Nil(t, stored.RetractedAt)
+	assert.Equal(t, "Forged", stored.Content)
+	assert.Nil(t, stored.ArchivedAt)
+
+	// It shows in the default list
+	var feedbacks []models.Feedback
+	getList(t, fmt.Sprintf("/feedback/?member_id=%d", target.ID), &feedbacks)
+	assert.Len(t, feedbacks, 1)
+}
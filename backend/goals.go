@@ -0,0 +1,492 @@
+package main
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type keyResultRequest struct {
+	Title        string   `json:"title" binding:"required"`
+	StartValue   float64  `json:"startvalue"`
+	TargetValue  float64  `json:"targetvalue"`
+	CurrentValue *float64 `json:"currentvalue"`
+	Unit         string   `json:"unit"`
+}
+
+type keyResultUpdateRequest struct {
+	Title       *string  `json:"title"`
+	StartValue  *float64 `json:"startvalue"`
+	TargetValue *float64 `json:"targetvalue"`
+	Unit        *string  `json:"unit"`
+}
+
+type goalRequest struct {
+	Title       string             `json:"title" binding:"required"`
+	Description string             `json:"description"`
+	OwnerType   string             `json:"ownertype" binding:"required"`
+	OwnerID     uint64             `json:"ownerid" binding:"required"`
+	Status      string             `json:"status"`
+	DueDate     *time.Time         `json:"duedate"`
+	KeyResults  []keyResultRequest `json:"keyresults"`
+}
+
+type goalUpdateRequest struct {
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	Status      *string    `json:"status"`
+	DueDate     *time.Time `json:"duedate"`
+}
+
+type checkInRequest struct {
+	KeyResultID *uint64  `json:"keyresultid"`
+	Value       *float64 `json:"value"`
+	Status      string   `json:"status"`
+	Note        string   `json:"note"`
+}
+
+// validGoalStatus answers 400 and returns false unless status is a known goal status
+func validGoalStatus(c *gin.Context, status string) bool {
+	if !slices.Contains(models.GoalStatuses, status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Status. Must be one of " + strings.Join(models.GoalStatuses, ", ") + "."})
+		return false
+	}
+	return true
+}
+
+// lookupGoalOwner answers 400 or 404 and returns false unless the owner exists
+func (s *Server) lookupGoalOwner(c *gin.Context, ownerType string, ownerID uint64) bool {
+	switch ownerType {
+	case "member":
+		if _, err := s.members.Get(ownerID); err != nil {
+			respondLookupError(c, err, "Owner member not found", "Error finding owner member: ")
+			return false
+		}
+	case "team":
+		if _, err := s.teams.Get(ownerID); err != nil {
+			respondLookupError(c, err, "Owner team not found", "Error finding owner team: ")
+			return false
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid OwnerType. Must be 'team' or 'member'."})
+		return false
+	}
+	return true
+}
+
+// canManageGoal reports whether the caller may change a goal: admins, the
+// owning member, their manager and the leads of their teams, or the leads of
+// the owning team
+func (s *Server) canManageGoal(perms *Permissions, goal *models.Goal) (bool, error) {
+	if goal.OwnerType == "team" {
+		return perms.LeadsTeam(goal.OwnerID), nil
+	}
+	if perms.MemberID == goal.OwnerID {
+		return true, nil
+	}
+	return s.managesMember(perms, goal.OwnerID)
+}
+
+// requireGoalManager answers 403 unless the caller may change the goal
+func (s *Server) requireGoalManager(c *gin.Context, goal *models.Goal) bool {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return false
+	}
+	allowed, err := s.canManageGoal(perms, goal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load permissions: " + err.Error()})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot manage the goals of this owner"})
+		return false
+	}
+	return true
+}
+
+// goalParam loads the goal named by the :id path parameter, answering 400 or
+// 404 and returning nil when it cannot
+func (s *Server) goalParam(c *gin.Context) *models.Goal {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return nil
+	}
+	goal, err := s.goals.Get(id)
+	if err != nil {
+		respondLookupError(c, err, "Goal not found", "")
+		return nil
+	}
+	return goal
+}
+
+// CreateGoal creates a goal with its key results for a member or a team
+func (s *Server) CreateGoal(c *gin.Context) {
+	var req goalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !s.lookupGoalOwner(c, req.OwnerType, req.OwnerID) {
+		return
+	}
+
+	goal := models.Goal{
+		Title:       req.Title,
+		Description: req.Description,
+		OwnerType:   req.OwnerType,
+		OwnerID:     req.OwnerID,
+		Status:      cmp.Or(req.Status, models.GoalNotStarted),
+		DueDate:     req.DueDate,
+	}
+	if !validGoalStatus(c, goal.Status) || !s.requireGoalManager(c, &goal) {
+		return
+	}
+	for _, krReq := range req.KeyResults {
+		keyResult, ok := newKeyResult(c, krReq)
+		if !ok {
+			return
+		}
+		goal.KeyResults = append(goal.KeyResults, keyResult)
+	}
+	if creator, err := s.members.Get(CurrentIdentity(c).MemberID); err == nil {
+		goal.CreatedByID = &creator.ID
+	}
+
+	if err := s.goals.Create(&goal); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create goal: " + err.Error()})
+		return
+	}
+	goal.FillProgress()
+	c.JSON(http.StatusCreated, goal)
+}
+
+// newKeyResult validates a key result request. It answers 400 and returns
+// false when the key result cannot be measured.
+func newKeyResult(c *gin.Context, req keyResultRequest) (models.KeyResult, bool) {
+	keyResult := models.KeyResult{
+		Title:        req.Title,
+		StartValue:   req.StartValue,
+		TargetValue:  req.TargetValue,
+		CurrentValue: req.StartValue,
+		Unit:         req.Unit,
+	}
+	if req.CurrentValue != nil {
+		keyResult.CurrentValue = *req.CurrentValue
+	}
+	if keyResult.TargetValue == keyResult.StartValue {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "TargetValue of key result '" + req.Title + "' must differ from its StartValue"})
+		return keyResult, false
+	}
+	return keyResult, true
+}
+
+// GetGoals lists goals one page at a time. They can be filtered by owner with
+// member_id or team_id and by status, and sorted by title, status, due_date or created_at.
+func (s *Server) GetGoals(c *gin.Context) {
+	var filter repository.GoalFilter
+	memberID, err := optionalIDQuery(c, "member_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	teamID, err := optionalIDQuery(c, "team_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if memberID != nil {
+		filter.OwnerType, filter.OwnerID = "member", memberID
+	} else if teamID != nil {
+		filter.OwnerType, filter.OwnerID = "team", teamID
+	}
+	s.respondGoals(c, filter)
+}
+
+// GetMemberGoals lists the goals owned by a member
+func (s *Server) GetMemberGoals(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok || !s.lookupGoalOwner(c, "member", id) {
+		return
+	}
+	s.respondGoals(c, repository.GoalFilter{OwnerType: "member", OwnerID: &id})
+}
+
+// GetTeamGoals lists the goals owned by a team
+func (s *Server) GetTeamGoals(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok || !s.lookupGoalOwner(c, "team", id) {
+		return
+	}
+	s.respondGoals(c, repository.GoalFilter{OwnerType: "team", OwnerID: &id})
+}
+
+func (s *Server) respondGoals(c *gin.Context, filter repository.GoalFilter) {
+	opts, err := parseListParams(c, "title", "status", "due_date", "created_at")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.Status = c.Query("status"); filter.Status != "" && !validGoalStatus(c, filter.Status) {
+		return
+	}
+
+	goals, total, err := s.goals.List(filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve goals: " + err.Error()})
+		return
+	}
+	for i := range goals {
+		goals[i].FillProgress()
+	}
+	setPageHeaders(c, opts, total)
+	c.JSON(http.StatusOK, goals)
+}
+
+func (s *Server) GetGoal(c *gin.Context) {
+	goal := s.goalParam(c)
+	if goal == nil {
+		return
+	}
+	goal.FillProgress()
+	c.JSON(http.StatusOK, goal)
+}
+
+// UpdateGoal changes the title, description, status or due date of a goal.
+// Its owner cannot change.
+func (s *Server) UpdateGoal(c *gin.Context) {
+	goal := s.goalParam(c)
+	if goal == nil || !s.requireGoalManager(c, goal) {
+		return
+	}
+
+	var req goalUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	changes := map[string]any{}
+	if req.Title != nil {
+		if *req.Title == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Title cannot be empty"})
+			return
+		}
+		changes["title"] = *req.Title
+	}
+	if req.Description != nil {
+		changes["description"] = *req.Description
+	}
+	if req.Status != nil {
+		if !validGoalStatus(c, *req.Status) {
+			return
+		}
+		changes["status"] = *req.Status
+	}
+	if req.DueDate != nil {
+		changes["due_date"] = *req.DueDate
+	}
+
+	updated, err := s.goals.Update(goal.ID, changes)
+	if err != nil {
+		respondLookupError(c, err, "Goal not found", "Failed to update goal: ")
+		return
+	}
+	updated.FillProgress()
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteGoal removes a goal with its key results and check-ins. Feedback
+// linked to the goal is kept.
+func (s *Server) DeleteGoal(c *gin.Context) {
+	goal := s.goalParam(c)
+	if goal == nil || !s.requireGoalManager(c, goal) {
+		return
+	}
+	if err := s.goals.Delete(goal.ID); err != nil {
+		respondLookupError(c, err, "Goal not found", "Failed to delete goal: ")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (s *Server) AddKeyResult(c *gin.Context) {
+	goal := s.goalParam(c)
+	if goal == nil || !s.requireGoalManager(c, goal) {
+		return
+	}
+
+	var req keyResultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	keyResult, ok := newKeyResult(c, req)
+	if !ok {
+		return
+	}
+	keyResult.GoalID = goal.ID
+
+	if err := s.goals.AddKeyResult(&keyResult); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create key result: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, keyResult)
+}
+
+// UpdateKeyResult changes the definition of a key result. Its current value
+// only moves through check-ins.
+func (s *Server) UpdateKeyResult(c *gin.Context) {
+	goal := s.goalParam(c)
+	if goal == nil || !s.requireGoalManager(c, goal) {
+		return
+	}
+	keyResultID, ok := idParam(c, "kr_id")
+	if !ok {
+		return
+	}
+	index := slices.IndexFunc(goal.KeyResults, func(kr models.KeyResult) bool { return kr.ID == keyResultID })
+	if index < 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Key result not found"})
+		return
+	}
+	keyResult := goal.KeyResults[index]
+
+	var req keyResultUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	changes := map[string]any{}
+	if req.Title != nil {
+		if *req.Title == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Title cannot be empty"})
+			return
+		}
+		changes["title"] = *req.Title
+	}
+	if req.StartValue != nil {
+		keyResult.StartValue = *req.StartValue
+		changes["start_value"] = *req.StartValue
+	}
+	if req.TargetValue != nil {
+		keyResult.TargetValue = *req.TargetValue
+		changes["target_value"] = *req.TargetValue
+	}
+	if req.Unit != nil {
+		changes["unit"] = *req.Unit
+	}
+	if keyResult.TargetValue == keyResult.StartValue {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "TargetValue must differ from StartValue"})
+		return
+	}
+
+	updated, err := s.goals.UpdateKeyResult(goal.ID, keyResultID, changes)
+	if err != nil {
+		respondLookupError(c, err, "Key result not found", "Failed to update key result: ")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (s *Server) DeleteKeyResult(c *gin.Context) {
+	goal := s.goalParam(c)
+	if goal == nil || !s.requireGoalManager(c, goal) {
+		return
+	}
+	keyResultID, ok := idParam(c, "kr_id")
+	if !ok {
+		return
+	}
+	if err := s.goals.DeleteKeyResult(goal.ID, keyResultID); err != nil {
+		respondLookupError(c, err, "Key result not found", "Failed to delete key result: ")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// AddGoalCheckIn records progress on a goal. Besides the goal managers, the
+// members of an owning team can check in.
+func (s *Server) AddGoalCheckIn(c *gin.Context) {
+	goal := s.goalParam(c)
+	if goal == nil {
+		return
+	}
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	allowed, err := s.canManageGoal(perms, goal)
+	if err == nil && !allowed && goal.OwnerType == "team" {
+		var team *models.Team
+		if team, err = s.teams.Get(goal.OwnerID); err == nil {
+			allowed = slices.ContainsFunc(team.Members, func(m models.TeamMember) bool { return m.ID == perms.MemberID })
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load permissions: " + err.Error()})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot check in on this goal"})
+		return
+	}
+
+	var req checkInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Value == nil && req.Status == "" && req.Note == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A check-in needs a value, a status or a note"})
+		return
+	}
+	if req.Value != nil && req.KeyResultID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "KeyResultID is required with a value"})
+		return
+	}
+	if req.KeyResultID != nil && !slices.ContainsFunc(goal.KeyResults, func(kr models.KeyResult) bool { return kr.ID == *req.KeyResultID }) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Key result not found"})
+		return
+	}
+	if req.Status != "" && !validGoalStatus(c, req.Status) {
+		return
+	}
+
+	checkIn := models.GoalCheckIn{
+		GoalID:      goal.ID,
+		KeyResultID: req.KeyResultID,
+		Value:       req.Value,
+		Status:      req.Status,
+		Note:        req.Note,
+	}
+	if author, err := s.members.Get(perms.MemberID); err == nil {
+		checkIn.AuthorID = &author.ID
+	}
+
+	if err := s.goals.AddCheckIn(&checkIn); err != nil {
+		respondLookupError(c, err, "Key result not found", "Failed to record check-in: ")
+		return
+	}
+	c.JSON(http.StatusCreated, checkIn)
+}
+
+// GetGoalCheckIns lists the check-ins of a goal, oldest first
+func (s *Server) GetGoalCheckIns(c *gin.Context) {
+	goal := s.goalParam(c)
+	if goal == nil {
+		return
+	}
+	checkIns, err := s.goals.ListCheckIns(goal.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve check-ins: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, checkIns)
+}
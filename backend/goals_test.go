@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"coaching-app/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManageGoals(t *testing.T) {
+	setupTestDatabase()
+
+	owner := models.TeamMember{Name: "Owner", Email: "owner@example.com"}
+	lead := models.TeamMember{Name: "Lead", Email: "lead@example.com"}
+	outsider := models.TeamMember{Name: "Outsider", Email: "outsider@example.com"}
+	testDB.Create(&owner)
+	testDB.Create(&lead)
+	testDB.Create(&outsider)
+	platform := models.Team{Name: "Platform", Members: []models.TeamMember{owner, lead}}
+	testDB.Create(&platform)
+	testDB.Create(&models.MemberRole{MemberID: lead.ID, Role: models.RoleTeamLead, TeamID: &platform.ID})
+
+	payload := fmt.Sprintf(`{"title": "Ship v2", "ownertype": "member", "ownerid": %d,
+		"keyresults": [{"title": "Endpoints migrated", "startvalue": 0, "targetvalue": 20}]}`, owner.ID)
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/goals/", payload, outsider.ID).Code)
+	assert.Equal(t, http.StatusNotFound, serve("POST", "/goals/", `{"title": "Nobody", "ownertype": "member", "ownerid": 99999}`, testCallerID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/goals/", fmt.Sprintf(`{"title": "Flat", "ownertype": "member", "ownerid": %d,
+		"keyresults": [{"title": "Nothing", "startvalue": 5, "targetvalue": 5}]}`, owner.ID), owner.ID).Code)
+
+	w := serve("POST", "/goals/", payload, lead.ID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var goal models.Goal
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &goal))
+	assert.Equal(t, models.GoalNotStarted, goal.Status)
+	assert.Equal(t, &lead.ID, goal.CreatedByID)
+	if !assert.Len(t, goal.KeyResults, 1) {
+		return
+	}
+	keyResult := goal.KeyResults[0]
+
+	w = serve("PUT", fmt.Sprintf("/goals/%d", goal.ID), `{"status": "done"}`, owner.ID)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve("PUT", fmt.Sprintf("/goals/%d", goal.ID), `{"status": "on_track"}`, outsider.ID)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serve("PUT", fmt.Sprintf("/goals/%d", goal.ID), `{"status": "on_track"}`, owner.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	checkIn := fmt.Sprintf(`{"keyresultid": %d, "value": 5, "status": "at_risk", "note": "Slower than planned"}`, keyResult.ID)
+	assert.Equal(t, http.StatusForbidden, serve("POST", fmt.Sprintf("/goals/%d/checkins", goal.ID), checkIn, outsider.ID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", fmt.Sprintf("/goals/%d/checkins", goal.ID), `{"value": 5}`, owner.ID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", fmt.Sprintf("/goals/%d/checkins", goal.ID), `{}`, owner.ID).Code)
+	assert.Equal(t, http.StatusNotFound, serve("POST", fmt.Sprintf("/goals/%d/checkins", goal.ID), `{"keyresultid": 99999, "value": 5}`, owner.ID).Code)
+	assert.Equal(t, http.StatusCreated, serve("POST", fmt.Sprintf("/goals/%d/checkins", goal.ID), checkIn, owner.ID).Code)
+
+	w = serve("GET", fmt.Sprintf("/goals/%d", goal.ID), "", owner.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &goal))
+	assert.Equal(t, models.GoalAtRisk, goal.Status)
+	assert.InDelta(t, 0.25, goal.Progress, 0.0001)
+	if assert.Len(t, goal.KeyResults, 1) {
+		assert.Equal(t, 5.0, goal.KeyResults[0].CurrentValue)
+		assert.InDelta(t, 0.25, goal.KeyResults[0].Progress, 0.0001)
+	}
+
+	var checkIns []models.GoalCheckIn
+	w = serve("GET", fmt.Sprintf("/goals/%d/checkins", goal.ID), "", owner.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &checkIns))
+	if assert.Len(t, checkIns, 1) {
+		assert.Equal(t, &owner.ID, checkIns[0].AuthorID)
+	}
+
+	var goals []models.Goal
+	getList(t, fmt.Sprintf("/members/%d/goals?status=at_risk", owner.ID), &goals)
+	assert.Len(t, goals, 1)
+	getList(t, fmt.Sprintf("/goals/?member_id=%d&status=completed", owner.ID), &goals)
+	assert.Len(t, goals, 0)
+
+	w = serve("DELETE", fmt.Sprintf("/goals/%d/keyresults/%d", goal.ID, keyResult.ID), "", owner.ID)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve("DELETE", fmt.Sprintf("/goals/%d", goal.ID), "", owner.ID)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, http.StatusNotFound, serve("GET", fmt.Sprintf("/goals/%d", goal.ID), "", owner.ID).Code)
+}
+
+func TestTeamGoalCheckIns(t *testing.T) {
+	setupTestDatabase()
+
+	member := models.TeamMember{Name: "Member", Email: "member@example.com"}
+	outsider := models.TeamMember{Name: "Outsider", Email: "outsider@example.com"}
+	testDB.Create(&member)
+	testDB.Create(&outsider)
+	platform := models.Team{Name: "Platform", Members: []models.TeamMember{member}}
+	testDB.Create(&platform)
+	goal := models.Goal{Title: "Reliability", OwnerType: "team", OwnerID: platform.ID, Status: models.GoalOnTrack}
+	testDB.Create(&goal)
+
+	assert.Equal(t, http.StatusForbidden, serve("PUT", fmt.Sprintf("/goals/%d", goal.ID), `{"title": "Renamed"}`, member.ID).Code)
+	assert.Equal(t, http.StatusCreated, serve("POST", fmt.Sprintf("/goals/%d/checkins", goal.ID), `{"note": "Paged twice"}`, member.ID).Code)
+	assert.Equal(t, http.StatusForbidden, serve("POST", fmt.Sprintf("/goals/%d/checkins", goal.ID), `{"note": "Looks fine"}`, outsider.ID).Code)
+
+	var goals []models.Goal
+	getList(t, fmt.Sprintf("/teams/%d/goals", platform.ID), &goals)
+	assert.Len(t, goals, 1)
+}
+
+func TestFeedbackLinkedToGoal(t *testing.T) {
+	setupTestDatabase()
+
+	target := models.TeamMember{Name: "Target", Email: "target@example.com"}
+	other := models.TeamMember{Name: "Other", Email: "other@example.com"}
+	giver := models.TeamMember{Name: "Giver", Email: "giver@example.com"}
+	testDB.Create(&target)
+	testDB.Create(&other)
+	testDB.Create(&giver)
+	goal := models.Goal{Title: "Mentoring", OwnerType: "member", OwnerID: target.ID, Status: models.GoalOnTrack}
+	testDB.Create(&goal)
+
+	give := func(targetID, goalID uint64) int {
+		payload := fmt.Sprintf(`{"content": "On the goal", "targetid": %d, "targettype": "member", "goalid": %d}`, targetID, goalID)
+		return serve("POST", "/feedback/", payload, giver.ID).Code
+	}
+	assert.Equal(t, http.StatusNotFound, give(target.ID, 99999))
+	assert.Equal(t, http.StatusBadRequest, give(other.ID, goal.ID))
+	assert.Equal(t, http.StatusCreated, give(target.ID, goal.ID))
+
+	var feedbacks []models.Feedback
+	getList(t, fmt.Sprintf("/feedback/?goal_id=%d", goal.ID), &feedbacks)
+	assert.Len(t, feedbacks, 1)
+
+	assert.Equal(t, http.StatusNoContent, serve("DELETE", fmt.Sprintf("/goals/%d", goal.ID), "", testCallerID).Code)
+	getList(t, "/feedback/", &feedbacks)
+	if assert.Len(t, feedbacks, 1) {
+		assert.Nil(t, feedbacks[0].GoalID)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// targetDeletionQuery reads what to do with the feedback about a deleted member
+// or team: the on_feedback query parameter (cascade, reassign or archive)
+// overrides FEEDBACK_ON_TARGET_DELETE, and reassign needs reassign_to. It
+// answers 400 and returns false when they are invalid.
+func (s *Server) targetDeletionQuery(c *gin.Context) (repository.TargetDeletion, bool) {
+	deletion := repository.TargetDeletion{Policy: cmp.Or(c.Query("on_feedback"), s.settings.FeedbackOnTargetDelete, repository.FeedbackArchive)}
+	if !slices.Contains(repository.TargetDeletionPolicies, deletion.Policy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid on_feedback. Must be one of " + strings.Join(repository.TargetDeletionPolicies, ", ") + "."})
+		return deletion, false
+	}
+
+	reassignTo, err := optionalIDQuery(c, "reassign_to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return deletion, false
+	}
+	if deletion.Policy == repository.FeedbackReassign {
+		if reassignTo == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reassigning feedback needs reassign_to"})
+			return deletion, false
+		}
+		deletion.ReassignTo = *reassignTo
+	}
+	return deletion, true
+}
+
+// includeDeletedQuery reads the include_deleted query parameter of the member
+// and team lists. It answers 400 when it is not a boolean and 403 when a
+// caller other than an admin sets it, returning false in both cases.
+func (s *Server) includeDeletedQuery(c *gin.Context) (bool, bool) {
+	includeDeleted, err := optionalBoolQuery(c, "include_deleted")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false, false
+	}
+	if includeDeleted == nil || !*includeDeleted {
+		return false, true
+	}
+	return true, s.requireAdmin(c)
+}
+
+// runCheckCommand implements "server check [--repair]". It reports the feedback
+// about members or teams that no longer exist, left by deletions made before
+// their feedback was taken care of, and with --repair deletes it under the
+// cascade policy or archives it otherwise, since orphans have nobody to be
+// reassigned to.
+func runCheckCommand(integrity repository.IntegrityRepository, policy string, args []string, out io.Writer) error {
+	repair := false
+	for _, arg := range args {
+		if arg != "--repair" {
+			return fmt.Errorf("unknown check argument %q (expected --repair)", arg)
+		}
+		repair = true
+	}
+
+	orphans, err := integrity.FindOrphans()
+	if err != nil {
+		return err
+	}
+	for _, orphan := range orphans {
+		fmt.Fprintf(out, "feedback %d\tabout missing %s %d\n", orphan.FeedbackID, orphan.TargetType, orphan.TargetID)
+	}
+	fmt.Fprintf(out, "Found %d orphaned feedback entries\n", len(orphans))
+	if !repair || len(orphans) == 0 {
+		return nil
+	}
+
+	repaired, err := integrity.RepairOrphans(policy)
+	if policy == repository.FeedbackCascade {
+		fmt.Fprintf(out, "Deleted %d feedback entries\n", repaired)
+	} else {
+		fmt.Fprintf(out, "Archived %d feedback entries\n", repaired)
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteTargetWithFeedback(t *testing.T) {
+	setupTestDatabase()
+
+	teams := []models.Team{{Name: "Old"}, {Name: "New"}}
+	for i := range teams {
+		testDB.Create(&teams[i])
+	}
+	member := models.TeamMember{Name: "Member", Email: "member@example.com"}
+	testDB.Create(&member)
+	teamFeedback := models.Feedback{Content: "Team", TargetType: "team", TargetID: teams[0].ID}
+	memberFeedback := models.Feedback{Content: "Member", TargetType: "member", TargetID: member.ID}
+	testDB.Create(&teamFeedback)
+	testDB.Create(&memberFeedback)
+
+	assert.Equal(t, http.StatusBadRequest, serve("DELETE", fmt.Sprintf("/teams/%d?on_feedback=forget", teams[0].ID), "", testCallerID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("DELETE", fmt.Sprintf("/teams/%d?on_feedback=reassign", teams[0].ID), "", testCallerID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("DELETE", fmt.Sprintf("/teams/%d?on_feedback=reassign&reassign_to=99999", teams[0].ID), "", testCallerID).Code)
+	assert.Equal(t, http.StatusNoContent, serve("DELETE", fmt.Sprintf("/teams/%d?on_feedback=reassign&reassign_to=%d", teams[0].ID, teams[1].ID), "", testCallerID).Code)
+	var stored models.Feedback
+	testDB.First(&stored, teamFeedback.ID)
+	assert.Equal(t, teams[1].ID, stored.TargetID)
+
+	// Archiving is the default, and archived feedback is only listed on request
+	assert.Equal(t, http.StatusNoContent, serve("DELETE", fmt.Sprintf("/members/%d", member.ID), "", testCallerID).Code)
+	var feedbacks []models.Feedback
+	getList(t, "/feedback/", &feedbacks)
+	if assert.Len(t, feedbacks, 1) {
+		assert.Equal(t, teamFeedback.ID, feedbacks[0].ID)
+	}
+	getList(t, "/feedback/?archived=true", &feedbacks)
+	if assert.Len(t, feedbacks, 1) {
+		assert.Equal(t, memberFeedback.ID, feedbacks[0].ID)
+		assert.NotNil(t, feedbacks[0].ArchivedAt)
+	}
+
+	giver := models.TeamMember{Name: "Giver", Email: "giver@example.com"}
+	testDB.Create(&giver)
+	payload := fmt.Sprintf(`{"content": "Hello", "targetid": %d, "targettype": "member"}`, member.ID)
+	assert.Equal(t, http.StatusNotFound, serve("POST", "/feedback/", payload, giver.ID).Code)
+}
+
+func TestCheckCommand(t *testing.T) {
+	setupTestDatabase()
+
+	member := models.TeamMember{Name: "Member", Email: "member@example.com"}
+	testDB.Create(&member)
+	testDB.Create(&models.Feedback{Content: "Fine", TargetType: "member", TargetID: member.ID})
+	orphans := []models.Feedback{
+		{Content: "Lost", TargetType: "member", TargetID: 99999},
+		{Content: "Lost team", TargetType: "team", TargetID: 99999},
+	}
+	testDB.Create(&orphans)
+	integrity := repository.NewGormRepositories(testDB).Integrity
+
+	var out strings.Builder
+	assert.NoError(t, runCheckCommand(integrity, repository.FeedbackArchive, nil, &out))
+	assert.Contains(t, out.String(), fmt.Sprintf("feedback %d\tabout missing member 99999", orphans[0].ID))
+	assert.Contains(t, out.String(), "Found 2 orphaned feedback entries")
+
+	out.Reset()
+	assert.NoError(t, runCheckCommand(integrity, repository.FeedbackArchive, []string{"--repair"}, &out))
+	assert.Contains(t, out.String(), "Archived 2 feedback entries")
+	var archived int64
+	testDB.Model(&models.Feedback{}).Where("archived_at IS NOT NULL").Count(&archived)
+	assert.Equal(t, int64(2), archived)
+
+	// Archived feedback is no longer reported
+	out.Reset()
+	assert.NoError(t, runCheckCommand(integrity, repository.FeedbackCascade, []string{"--repair"}, &out))
+	assert.Contains(t, out.String(), "Found 0 orphaned feedback entries")
+
+	testDB.Create(&models.Feedback{Content: "Lost again", TargetType: "member", TargetID: 99999})
+	out.Reset()
+	assert.NoError(t, runCheckCommand(integrity, repository.FeedbackCascade, []string{"--repair"}, &out))
+	assert.Contains(t, out.String(), "Deleted 1 feedback entries")
+	var remaining int64
+	testDB.Model(&models.Feedback{}).Count(&remaining)
+	assert.Equal(t, int64(3), remaining)
+
+	assert.Error(t, runCheckCommand(integrity, repository.FeedbackArchive, []string{"--fix"}, &out))
+}
+
+func TestCommandsRequireMigratedDatabase(t *testing.T) {
+	setupTestDatabase()
+	assert.NoError(t, requireMigrated(testDB))
+
+	var out strings.Builder
+	assert.NoError(t, runMigrateCommand(testDB, []string{"down", "1"}, &out))
+	err := requireMigrated(testDB)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "database not migrated: 1 pending migration(s)")
+	}
+
+	assert.NoError(t, MigrateDatabase(testDB))
+	assert.NoError(t, requireMigrated(testDB))
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type managerRequest struct {
+	ManagerID *uint64 `json:"managerid"`
+}
+
+// respondManagerError answers 400 and returns true when a member could not be
+// placed under their manager
+func respondManagerError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, repository.ErrManagerNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Manager not found"})
+	case errors.Is(err, repository.ErrCycle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A member cannot report to themselves or to one of their reports"})
+	default:
+		return false
+	}
+	return true
+}
+
+// SetMemberManager changes who a member reports to, keeping the earlier
+// managers in their history. A null or 0 ManagerID leaves the member without a
+// manager. Only admins can change managers.
+func (s *Server) SetMemberManager(c *gin.Context) {
+	if !s.requireAdmin(c) {
+		return
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	var req managerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := s.members.SetManager(id, req.ManagerID, time.Now())
+	if err != nil {
+		if !respondManagerError(c, err) {
+			respondLookupError(c, err, "Team member not found", "Failed to change manager: ")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// GetMemberManagers lists the periods during which a member reported to each
+// of their managers, oldest first
+func (s *Server) GetMemberManagers(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.members.Get(id); err != nil {
+		respondLookupError(c, err, "Team member not found", "")
+		return
+	}
+	periods, err := s.members.ListManagerPeriods(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve managers: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, periods)
+}
+
+// GetMemberReports lists the members reporting to a member, ordered by name.
+// With transitive=true it also lists the reports of their reports, all the
+// way down.
+func (s *Server) GetMemberReports(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	transitive, err := optionalBoolQuery(c, "transitive")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := s.members.Get(id); err != nil {
+		respondLookupError(c, err, "Team member not found", "")
+		return
+	}
+	reports, err := s.members.ListReports(id, transitive != nil && *transitive)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reports: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
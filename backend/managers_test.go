@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"coaching-app/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagers(t *testing.T) {
+	setupTestDatabase()
+
+	lead := models.TeamMember{Name: "Lead", Email: "lead@example.com"}
+	testDB.Create(&lead)
+	w := serve("POST", "/members/", fmt.Sprintf(`{"name": "Report", "email": "report@example.com", "managerid": %d}`, lead.ID), testCallerID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var report models.TeamMember
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/members/", `{"name": "Lost", "email": "lost@example.com", "managerid": 99999}`, testCallerID).Code)
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/members/", fmt.Sprintf(`{"name": "Spy", "email": "spy@example.com", "managerid": %d}`, lead.ID), lead.ID).Code)
+	intern := models.TeamMember{Name: "Intern", Email: "intern@example.com"}
+	testDB.Create(&intern)
+	outsider := models.TeamMember{Name: "Outsider", Email: "outsider@example.com"}
+	testDB.Create(&outsider)
+
+	setManager := func(memberID uint64, body string, caller uint64) int {
+		return serve("PUT", fmt.Sprintf("/members/%d/manager", memberID), body, caller).Code
+	}
+	assert.Equal(t, http.StatusForbidden, setManager(intern.ID, fmt.Sprintf(`{"managerid": %d}`, report.ID), lead.ID))
+	assert.Equal(t, http.StatusOK, setManager(intern.ID, fmt.Sprintf(`{"managerid": %d}`, report.ID), testCallerID))
+	assert.Equal(t, http.StatusBadRequest, setManager(lead.ID, fmt.Sprintf(`{"managerid": %d}`, intern.ID), testCallerID))
+	assert.Equal(t, http.StatusBadRequest, setManager(lead.ID, `{"managerid": 99999}`, testCallerID))
+	assert.Equal(t, http.StatusNotFound, setManager(99999, fmt.Sprintf(`{"managerid": %d}`, lead.ID), testCallerID))
+
+	var reports []models.TeamMember
+	getList(t, fmt.Sprintf("/members/%d/reports", lead.ID), &reports)
+	if assert.Len(t, reports, 1) {
+		assert.Equal(t, "Report", reports[0].Name)
+	}
+	getList(t, fmt.Sprintf("/members/%d/reports?transitive=true", lead.ID), &reports)
+	assert.Len(t, reports, 2)
+	assert.Equal(t, http.StatusNotFound, serve("GET", "/members/99999/reports", "", testCallerID).Code)
+
+	// The intern changes manager, then loses it
+	assert.Equal(t, http.StatusOK, setManager(intern.ID, fmt.Sprintf(`{"managerid": %d}`, lead.ID), testCallerID))
+	assert.Equal(t, http.StatusOK, setManager(intern.ID, `{"managerid": null}`, testCallerID))
+	var periods []models.ManagerPeriod
+	getList(t, fmt.Sprintf("/members/%d/managers", intern.ID), &periods)
+	if assert.Len(t, periods, 2) {
+		assert.Equal(t, report.ID, periods[0].ManagerID)
+		assert.Equal(t, "Lead", periods[1].Manager.Name)
+		assert.NotNil(t, periods[1].EndedAt)
+	}
+
+	// The manager of the target reads manager-only feedback about them
+	payload := fmt.Sprintf(`{"content": "Ready for more", "targetid": %d, "targettype": "member", "visibility": "manager"}`, report.ID)
+	assert.Equal(t, http.StatusCreated, serve("POST", "/feedback/", payload, outsider.ID).Code)
+	fetch := func(caller uint64) []models.Feedback {
+		w := serve("GET", fmt.Sprintf("/feedback/?member_id=%d", report.ID), "", caller)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var feedbacks []models.Feedback
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &feedbacks))
+		return feedbacks
+	}
+	assert.Len(t, fetch(lead.ID), 1)
+	assert.Empty(t, fetch(report.ID))
+	assert.Empty(t, fetch(intern.ID))
+
+	// A feedback request can go to the manager of the requester
+	w = serve("POST", "/feedback/requests", `{"includemanager": true}`, report.ID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var request models.FeedbackRequest
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &request))
+	if assert.Len(t, request.Recipients, 1) {
+		assert.Equal(t, lead.ID, request.Recipients[0].RecipientID)
+	}
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/feedback/requests", `{"includemanager": true}`, intern.ID).Code)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type memberCreateRequest struct {
+	Name       string  `json:"name"`
+	Email      string  `json:"email"`
+	PictureURL string  `json:"pictureurl"`
+	ManagerID  *uint64 `json:"managerid"`
+}
+
+// CreateTeamMember creates a member, reporting to ManagerID when it is set.
+// Like SetMemberManager, only admins can set a manager.
+func (s *Server) CreateTeamMember(c *gin.Context) {
+	var req memberCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ManagerID != nil && *req.ManagerID != 0 && !s.requireAdmin(c) {
+		return
+	}
+
+	member := models.TeamMember{Name: req.Name, Email: req.Email, PictureURL: req.PictureURL, ManagerID: req.ManagerID}
+
+	if err := s.members.Create(&member); err != nil {
+		if respondManagerError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create team member: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// GetTeamMembers lists members one page at a time. They can be filtered by name
+// prefix, email_domain and team_id membership and sorted by name, email or created_at.
+// Admins can list the deleted members too with include_deleted.
+func (s *Server) GetTeamMembers(c *gin.Context) {
+	opts, err := parseListParams(c, "name", "email", "created_at")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := repository.MemberFilter{
+		NamePrefix:  c.Query("name"),
+		EmailDomain: c.Query("email_domain"),
+	}
+	if filter.TeamID, err = optionalIDQuery(c, "team_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var ok bool
+	if filter.IncludeDeleted, ok = s.includeDeletedQuery(c); !ok {
+		return
+	}
+
+	members, total, err := s.members.List(filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	setPageHeaders(c, opts, total)
+	c.JSON(http.StatusOK, members)
+}
+
+func (s *Server) GetTeamMember(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	member, err := s.members.Get(id)
+	if err != nil {
+		respondLookupError(c, err, "Team member not found", "")
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+type memberUpdateRequest struct {
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	PictureURL string `json:"pictureurl"`
+}
+
+// UpdateTeamMember changes the name, email or picture of a member. Only the
+// member or an admin can, since the email is what they log in with. Their
+// manager is changed with SetMemberManager.
+func (s *Server) UpdateTeamMember(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	if id != perms.MemberID && !perms.Admin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the member or an admin can change this member"})
+		return
+	}
+	if _, err := s.members.Get(id); err != nil {
+		respondLookupError(c, err, "Team member not found", "")
+		return
+	}
+
+	var req memberUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := s.members.Update(id, models.TeamMember{Name: req.Name, Email: req.Email, PictureURL: req.PictureURL})
+	if err != nil {
+		respondLookupError(c, err, "Team member not found", "")
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// DeleteTeamMember soft-deletes a member, keeping their account, roles and
+// memberships until they are restored or purged. Only admins can delete
+// members. The feedback about the member is archived, deleted or reassigned,
+// see targetDeletionQuery, and their reports move to their own manager.
+func (s *Server) DeleteTeamMember(c *gin.Context) {
+	if !s.requireAdmin(c) {
+		return
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	deletion, ok := s.targetDeletionQuery(c)
+	if !ok {
+		return
+	}
+	if err := s.members.Delete(id, deletion); err != nil {
+		if errors.Is(err, repository.ErrTargetNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reassign_to must be another existing member"})
+			return
+		}
+		respondLookupError(c, err, "Team member not found", "")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// RestoreTeamMember brings back a deleted member with their memberships and the
+// feedback about them that was archived or deleted along with them. Only admins
+// can restore members. The reports they had stay with their new manager.
+func (s *Server) RestoreTeamMember(c *gin.Context) {
+	if !s.requireAdmin(c) {
+		return
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	member, err := s.members.Restore(id)
+	if err != nil {
+		respondLookupError(c, err, "Team member not found", "")
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
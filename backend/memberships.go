@@ -0,0 +1,273 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMemberTimeline lists the assignment periods of a member, oldest first,
+// with their teams: which teams they were on, when, and in which role.
+func (s *Server) GetMemberTimeline(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.members.Get(id); err != nil {
+		respondLookupError(c, err, "Team member not found", "")
+		return
+	}
+	s.respondAssignments(c, repository.MembershipFilter{MemberID: &id})
+}
+
+// GetTeamTimeline lists the assignment periods of a team, oldest first, with
+// their members.
+func (s *Server) GetTeamTimeline(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.teams.Get(id); err != nil {
+		respondLookupError(c, err, "Team not found", "")
+		return
+	}
+	s.respondAssignments(c, repository.MembershipFilter{TeamID: &id})
+}
+
+// GetTeamRoster lists the assignment periods of the members who were on a team
+// at the time given by the at query parameter, now by default. A plain date
+// gives the roster at the end of that day.
+func (s *Server) GetTeamRoster(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.teams.Get(id); err != nil {
+		respondLookupError(c, err, "Team not found", "")
+		return
+	}
+
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		parsed, dateOnly, err := parseDateParam(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'at' date: " + err.Error()})
+			return
+		}
+		if dateOnly {
+			parsed = parsed.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		at = parsed
+	}
+	s.respondAssignments(c, repository.MembershipFilter{TeamID: &id, At: &at})
+}
+
+func (s *Server) respondAssignments(c *gin.Context, filter repository.MembershipFilter) {
+	assignments, err := s.teams.ListAssignments(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve assignments: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, assignments)
+}
+
+type moveRequest struct {
+	TeamID uint64 `json:"teamid" binding:"required"`
+	assignmentRequest
+}
+
+// MoveMemberToTeam moves a member from a team to the team given by TeamID in
+// one transaction: their period on the first team closes when the one on the
+// other opens. The role and allocation on the new team are optional, as with
+// AssignMemberToTeam. Only leads of both teams can move members.
+func (s *Server) MoveMemberToTeam(c *gin.Context) {
+	from, member, ok := s.lookupTeamAndMember(c)
+	if !ok {
+		return
+	}
+	var req moveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if invalid := req.validate(); invalid != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalid})
+		return
+	}
+	if req.TeamID == from.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A member cannot be moved to the team they are on"})
+		return
+	}
+	to, err := s.teams.Get(req.TeamID)
+	if err != nil {
+		respondLookupError(c, err, "Team not found", "Error finding team: ")
+		return
+	}
+	if !s.requireTeamLead(c, from.ID) || !s.requireTeamLead(c, to.ID) {
+		return
+	}
+
+	assignment := req.assignment(to.ID, member.ID)
+	if err := s.teams.MoveMember(from.ID, &assignment); err != nil {
+		if errors.Is(err, repository.ErrNotAssigned) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Member is not on the team"})
+			return
+		}
+		respondLookupError(c, err, "Team or member not found", "Failed to move member: ")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member moved to team successfully"})
+}
+
+type bulkAssignmentItem struct {
+	MemberID uint64 `json:"memberid"`
+	assignmentRequest
+}
+
+type bulkAssignmentRequest struct {
+	Members []bulkAssignmentItem `json:"members"`
+	Atomic  bool                 `json:"atomic"`
+}
+
+type bulkRemovalRequest struct {
+	MemberIDs []uint64 `json:"memberids"`
+	Atomic    bool     `json:"atomic"`
+}
+
+// BulkMembershipResult is the outcome for one member of a bulk assignment or removal
+type BulkMembershipResult struct {
+	MemberID uint64
+	Applied  bool
+	Error    string `json:",omitempty"`
+}
+
+// AssignMembersToTeam assigns a list of members to a team, each with an
+// optional role and allocation as with AssignMemberToTeam, and reports the
+// outcome for each. Members that fail are skipped, unless atomic is set: then
+// nothing is applied when any of them fails. Only leads of the team can assign
+// members.
+func (s *Server) AssignMembersToTeam(c *gin.Context) {
+	team, ok := s.lookupBulkTeam(c)
+	if !ok {
+		return
+	}
+	var req bulkAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Members) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "members must not be empty"})
+		return
+	}
+
+	results := make([]BulkMembershipResult, len(req.Members))
+	var assignments []models.TeamAssignment
+	var indexes []int
+	for i, item := range req.Members {
+		results[i].MemberID = item.MemberID
+		if results[i].Error = item.validate(); results[i].Error != "" {
+			continue
+		}
+		assignments = append(assignments, item.assignment(team.ID, item.MemberID))
+		indexes = append(indexes, i)
+	}
+	if req.Atomic && len(indexes) < len(results) {
+		respondBulkResults(c, results, true)
+		return
+	}
+
+	errs, err := s.teams.AddMembers(assignments, req.Atomic)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign members to team: " + err.Error()})
+		return
+	}
+	for j, i := range indexes {
+		setBulkResult(&results[i], errs[j])
+	}
+	respondBulkResults(c, results, req.Atomic)
+}
+
+// RemoveMembersFromTeam removes a list of members from a team and reports the
+// outcome for each. Members that are not on the team fail and are skipped,
+// unless atomic is set: then nothing is applied when any of them fails. Only
+// leads of the team can remove members.
+func (s *Server) RemoveMembersFromTeam(c *gin.Context) {
+	team, ok := s.lookupBulkTeam(c)
+	if !ok {
+		return
+	}
+	var req bulkRemovalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.MemberIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "memberids must not be empty"})
+		return
+	}
+
+	errs, err := s.teams.RemoveMembers(team.ID, req.MemberIDs, time.Now(), req.Atomic)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove members from team: " + err.Error()})
+		return
+	}
+	results := make([]BulkMembershipResult, len(req.MemberIDs))
+	for i, memberID := range req.MemberIDs {
+		results[i].MemberID = memberID
+		setBulkResult(&results[i], errs[i])
+	}
+	respondBulkResults(c, results, req.Atomic)
+}
+
+// lookupBulkTeam loads the team of a bulk route and checks that the caller
+// leads it, answering otherwise
+func (s *Server) lookupBulkTeam(c *gin.Context) (*models.Team, bool) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+	team, err := s.teams.Get(id)
+	if err != nil {
+		respondLookupError(c, err, "Team not found", "Error finding team: ")
+		return nil, false
+	}
+	if !s.requireTeamLead(c, team.ID) {
+		return nil, false
+	}
+	return team, true
+}
+
+// setBulkResult records the outcome of the change of one member
+func setBulkResult(result *BulkMembershipResult, err error) {
+	switch {
+	case err == nil:
+		result.Applied = true
+	case errors.Is(err, repository.ErrNotFound):
+		result.Error = "Team member not found"
+	case errors.Is(err, repository.ErrNotAssigned):
+		result.Error = "Member is not on the team"
+	default:
+		result.Error = err.Error()
+	}
+}
+
+// respondBulkResults answers with the outcome for each member. When an atomic
+// change had failures nothing was applied, and it answers 400.
+func respondBulkResults(c *gin.Context, results []BulkMembershipResult, atomic bool) {
+	failed := slices.ContainsFunc(results, func(result BulkMembershipResult) bool { return result.Error != "" })
+	if atomic && failed {
+		for i := range results {
+			results[i].Applied = false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No member was changed because some of them failed", "results": results})
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
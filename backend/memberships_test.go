@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"coaching-app/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMembershipTimeline(t *testing.T) {
+	setupTestDatabase()
+
+	member := models.TeamMember{Name: "Member", Email: "member@example.com"}
+	testDB.Create(&member)
+	team := models.Team{Name: "Team"}
+	testDB.Create(&team)
+	assign := fmt.Sprintf("/teams/%d/assign/%d", team.ID, member.ID)
+
+	assert.Equal(t, http.StatusBadRequest, serve("POST", assign, `{"role": "boss"}`, testCallerID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", assign, `{"allocation": 150}`, testCallerID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", assign, `{"allocation": 0}`, testCallerID).Code)
+	assert.Equal(t, http.StatusOK, serve("POST", assign, "", testCallerID).Code)
+	assert.Equal(t, http.StatusOK, serve("POST", assign, `{"role": "lead", "allocation": 50}`, testCallerID).Code)
+	assert.Equal(t, http.StatusOK, serve("DELETE", fmt.Sprintf("/teams/%d/remove/%d", team.ID, member.ID), "", testCallerID).Code)
+
+	var timeline []models.TeamAssignment
+	getList(t, fmt.Sprintf("/members/%d/timeline", member.ID), &timeline)
+	if assert.Len(t, timeline, 2) {
+		assert.Equal(t, models.TeamRoleMember, timeline[0].Role)
+		assert.Equal(t, 100, timeline[0].Allocation)
+		assert.Equal(t, models.TeamRoleLead, timeline[1].Role)
+		assert.Equal(t, 50, timeline[1].Allocation)
+		assert.NotNil(t, timeline[1].EndedAt)
+		if assert.NotNil(t, timeline[1].Team) {
+			assert.Equal(t, team.ID, timeline[1].Team.ID)
+		}
+	}
+	getList(t, fmt.Sprintf("/teams/%d/timeline", team.ID), &timeline)
+	assert.Len(t, timeline, 2)
+
+	// The lead period spanned the whole of 1 March 2024
+	testDB.Model(&models.TeamAssignment{}).Where("id = ?", timeline[1].ID).
+		Updates(map[string]any{"started_at": time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), "ended_at": time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)})
+	var roster []models.TeamAssignment
+	getList(t, fmt.Sprintf("/teams/%d/roster?at=2024-03-01", team.ID), &roster)
+	if assert.Len(t, roster, 1) {
+		assert.Equal(t, models.TeamRoleLead, roster[0].Role)
+		assert.Equal(t, "Member", roster[0].Member.Name)
+	}
+	getList(t, fmt.Sprintf("/teams/%d/roster", team.ID), &roster)
+	assert.Empty(t, roster)
+
+	assert.Equal(t, http.StatusBadRequest, serve("GET", fmt.Sprintf("/teams/%d/roster?at=yesterday", team.ID), "", testCallerID).Code)
+	assert.Equal(t, http.StatusNotFound, serve("GET", "/teams/99999/roster", "", testCallerID).Code)
+	assert.Equal(t, http.StatusNotFound, serve("GET", "/members/99999/timeline", "", testCallerID).Code)
+}
+
+func TestLeadAssignmentGrantsTeamLead(t *testing.T) {
+	setupTestDatabase()
+
+	lead := models.TeamMember{Name: "Lead", Email: "lead@example.com"}
+	testDB.Create(&lead)
+	newcomer := models.TeamMember{Name: "Newcomer", Email: "newcomer@example.com"}
+	testDB.Create(&newcomer)
+	team := models.Team{Name: "Team"}
+	testDB.Create(&team)
+	assignNewcomer := fmt.Sprintf("/teams/%d/assign/%d", team.ID, newcomer.ID)
+
+	assert.Equal(t, http.StatusForbidden, serve("POST", assignNewcomer, "", lead.ID).Code)
+	assert.Equal(t, http.StatusOK, serve("POST", fmt.Sprintf("/teams/%d/assign/%d", team.ID, lead.ID), `{"role": "lead"}`, testCallerID).Code)
+	assert.Equal(t, http.StatusOK, serve("POST", assignNewcomer, "", lead.ID).Code)
+
+	// The rights end with the lead period
+	assert.Equal(t, http.StatusOK, serve("POST", fmt.Sprintf("/teams/%d/assign/%d", team.ID, lead.ID), "", testCallerID).Code)
+	assert.Equal(t, http.StatusForbidden, serve("DELETE", fmt.Sprintf("/teams/%d/remove/%d", team.ID, newcomer.ID), "", lead.ID).Code)
+}
+
+func TestMoveAndBulkMemberships(t *testing.T) {
+	setupTestDatabase()
+
+	from := models.Team{Name: "From"}
+	testDB.Create(&from)
+	to := models.Team{Name: "To"}
+	testDB.Create(&to)
+	var ids []uint64
+	for _, name := range []string{"Ann", "Ben", "Cid"} {
+		member := models.TeamMember{Name: name, Email: name + "@example.com"}
+		testDB.Create(&member)
+		ids = append(ids, member.ID)
+	}
+	lead := models.TeamMember{Name: "Lead", Email: "lead@example.com"}
+	testDB.Create(&lead)
+	testDB.Create(&models.MemberRole{MemberID: lead.ID, Role: models.RoleTeamLead, TeamID: &from.ID})
+	assign := fmt.Sprintf("/teams/%d/assign", from.ID)
+	results := func(w *httptest.ResponseRecorder) []BulkMembershipResult {
+		var body struct{ Results []BulkMembershipResult }
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body.Results
+	}
+
+	// An atomic assignment applies nothing when a member fails
+	payload := fmt.Sprintf(`{"members": [{"memberid": %d}, {"memberid": %d, "role": "boss"}, {"memberid": 99999}], "atomic": true}`, ids[0], ids[1])
+	w := serve("POST", assign, payload, lead.ID)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Len(t, results(w), 3)
+	payload = fmt.Sprintf(`{"members": [{"memberid": %d}, {"memberid": 99999}], "atomic": true}`, ids[0])
+	w = serve("POST", assign, payload, lead.ID)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	if body := results(w); assert.Len(t, body, 2) {
+		assert.False(t, body[0].Applied)
+		assert.Equal(t, "Team member not found", body[1].Error)
+	}
+	var roster []models.TeamAssignment
+	getList(t, fmt.Sprintf("/teams/%d/roster", from.ID), &roster)
+	assert.Empty(t, roster)
+
+	payload = fmt.Sprintf(`{"members": [{"memberid": %d}, {"memberid": %d, "role": "lead", "allocation": 50}, {"memberid": 99999}]}`, ids[0], ids[1])
+	w = serve("POST", assign, payload, lead.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body []BulkMembershipResult
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []BulkMembershipResult{{MemberID: ids[0], Applied: true}, {MemberID: ids[1], Applied: true}, {MemberID: 99999, Error: "Team member not found"}}, body)
+	getList(t, fmt.Sprintf("/teams/%d/roster", from.ID), &roster)
+	assert.Len(t, roster, 2)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", assign, `{"members": []}`, lead.ID).Code)
+	assert.Equal(t, http.StatusForbidden, serve("POST", fmt.Sprintf("/teams/%d/assign", to.ID), payload, lead.ID).Code)
+
+	// Moving needs the lead of both teams
+	move := fmt.Sprintf("/teams/%d/move/%d", from.ID, ids[0])
+	toTeam := fmt.Sprintf(`{"teamid": %d, "role": "lead"}`, to.ID)
+	assert.Equal(t, http.StatusForbidden, serve("POST", move, toTeam, lead.ID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", move, fmt.Sprintf(`{"teamid": %d}`, from.ID), testCallerID).Code)
+	assert.Equal(t, http.StatusNotFound, serve("POST", move, `{"teamid": 99999}`, testCallerID).Code)
+	assert.Equal(t, http.StatusConflict, serve("POST", fmt.Sprintf("/teams/%d/move/%d", to.ID, ids[0]), fmt.Sprintf(`{"teamid": %d}`, from.ID), testCallerID).Code)
+	assert.Equal(t, http.StatusOK, serve("POST", move, toTeam, testCallerID).Code)
+	getList(t, fmt.Sprintf("/teams/%d/roster", to.ID), &roster)
+	if assert.Len(t, roster, 1) {
+		assert.Equal(t, ids[0], roster[0].MemberID)
+		assert.Equal(t, models.TeamRoleLead, roster[0].Role)
+	}
+
+	// Bulk removal reports the members who were not on the team
+	remove := fmt.Sprintf("/teams/%d/remove", from.ID)
+	w = serve("POST", remove, fmt.Sprintf(`{"memberids": [%d, %d], "atomic": true}`, ids[1], ids[2]), lead.ID)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	if body := results(w); assert.Len(t, body, 2) {
+		assert.Equal(t, "Member is not on the team", body[1].Error)
+	}
+	getList(t, fmt.Sprintf("/teams/%d/roster", from.ID), &roster)
+	assert.Len(t, roster, 1)
+	w = serve("POST", remove, fmt.Sprintf(`{"memberids": [%d, %d]}`, ids[1], ids[2]), lead.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	getList(t, fmt.Sprintf("/teams/%d/roster", from.ID), &roster)
+	assert.Empty(t, roster)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"coaching-app/migrations"
+
+	"gorm.io/gorm"
+)
+
+// MigrateDatabase applies every pending schema migration to db
+func MigrateDatabase(db *gorm.DB) error {
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+	_, err = migrator.Up()
+	return err
+}
+
+// requireMigrated returns an error unless every known migration is applied to
+// db, for the commands that use the schema without migrating it
+func requireMigrated(db *gorm.DB) error {
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	pending := 0
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("database not migrated: %d pending migration(s), run \"migrate up\" first", pending)
+	}
+	return nil
+}
+
+// runMigrateCommand implements "server migrate [up | down [steps] | status]"
+func runMigrateCommand(db *gorm.DB, args []string, out io.Writer) error {
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up()
+		fmt.Fprintf(out, "Applied %d migration(s)\n", applied)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		rolledBack, err := migrator.Down(steps)
+		fmt.Fprintf(out, "Rolled back %d migration(s)\n", rolledBack)
+		return err
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q (expected up, down or status)", command)
+	}
+}
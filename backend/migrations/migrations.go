@@ -0,0 +1,255 @@
+// Package migrations applies the versioned database schema embedded in the
+// binary. Every version has an up and a down script per dialect, named
+// <version>_<name>.up.sql and <version>_<name>.down.sql, and the applied
+// versions are recorded with a checksum in the schema_migrations table.
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed mysql/*.sql sqlite/*.sql
+var scripts embed.FS
+
+// Migration is one schema version
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// AppliedMigration is a row of the schema_migrations table
+type AppliedMigration struct {
+	Version   int       `gorm:"primaryKey;column:version;autoIncrement:false"`
+	Name      string    `gorm:"column:name"`
+	Checksum  string    `gorm:"column:checksum"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+func (AppliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status describes a known migration and whether it has been applied
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator runs the migrations of one dialect against a database
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+const createTableSQL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT NOT NULL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    applied_at DATETIME NOT NULL
+)`
+
+// New loads the migrations matching the dialect of db ("mysql" or "sqlite")
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Load reads the embedded migrations of a dialect ordered by version
+func Load(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(scripts, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q: %w", dialect, err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		base, direction, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("unexpected migration file name %q", entry.Name())
+		}
+		versionText, name, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionText)
+		if err != nil {
+			return nil, fmt.Errorf("migration file %q does not start with a version number", entry.Name())
+		}
+
+		content, err := fs.ReadFile(scripts, path.Join(dialect, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, found := byVersion[version]
+		if !found {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		} else if migration.Name != name {
+			return nil, fmt.Errorf("migration %d has two names: %q and %q", version, migration.Name, name)
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down script", migration.Version, migration.Name)
+		}
+		sum := sha256.Sum256([]byte(migration.Up + "\x00" + migration.Down))
+		migration.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies every pending migration in order and returns how many ran
+func (m *Migrator) Up() (int, error) {
+	applied, err := m.verify()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if _, done := applied[migration.Version]; done {
+			continue
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Up); err != nil {
+				return err
+			}
+			return tx.Create(&AppliedMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Checksum:  migration.Checksum,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first, and returns
+// how many were rolled back
+func (m *Migrator) Down(steps int) (int, error) {
+	applied, err := m.verify()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+		migration := m.migrations[i]
+		if _, done := applied[migration.Version]; !done {
+			continue
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&AppliedMigration{Version: migration.Version}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("rollback of migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Status lists every known migration with the time it was applied, if it was
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.verify()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if row, done := applied[migration.Version]; done {
+			status.AppliedAt = &row.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// verify makes sure the bookkeeping table exists and that every applied
+// migration is still known and unchanged since it ran
+func (m *Migrator) verify() (map[int]AppliedMigration, error) {
+	if err := m.db.Exec(createTableSQL).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []AppliedMigration
+	if err := m.db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	known := map[int]Migration{}
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	applied := map[int]AppliedMigration{}
+	for _, row := range rows {
+		migration, ok := known[row.Version]
+		if !ok {
+			return nil, fmt.Errorf("database has migration %d_%s applied, which this binary does not know about", row.Version, row.Name)
+		}
+		if migration.Checksum != row.Checksum {
+			return nil, fmt.Errorf("checksum mismatch for migration %d_%s: it was changed after being applied", row.Version, row.Name)
+		}
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// execScript runs the statements of a script one at a time. Statements end
+// with a ';' at the end of a line and lines starting with "--" are comments.
+func execScript(tx *gorm.DB, script string) error {
+	var statement strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		statement.WriteString(line)
+		statement.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			if err := tx.Exec(statement.String()).Error; err != nil {
+				return err
+			}
+			statement.Reset()
+		}
+	}
+	if strings.TrimSpace(statement.String()) != "" {
+		return tx.Exec(statement.String()).Error
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	return db
+}
+
+func TestDialectsDefineTheSameVersions(t *testing.T) {
+	mysql, err := Load("mysql")
+	assert.NoError(t, err)
+	sqlite, err := Load("sqlite")
+	assert.NoError(t, err)
+
+	if assert.Equal(t, len(mysql), len(sqlite)) {
+		for i := range mysql {
+			assert.Equal(t, mysql[i].Version, sqlite[i].Version)
+			assert.Equal(t, mysql[i].Name, sqlite[i].Name)
+			assert.Equal(t, i+1, mysql[i].Version, "versions must be consecutive")
+		}
+	}
+}
+
+func TestUpAndDown(t *testing.T) {
+	db := openTestDB(t)
+	migrator, err := New(db)
+	assert.NoError(t, err)
+
+	applied, err := migrator.Up()
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrator.migrations), applied)
+	assert.True(t, db.Migrator().HasTable("team_members"))
+	assert.True(t, db.Migrator().HasColumn("feedbacks", "giver_id"))
+
+	// Running again is a no-op
+	applied, err = migrator.Up()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, applied)
+
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt, status.Name)
+	}
+
+	rolledBack, err := migrator.Down(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rolledBack)
+	statuses, _ = migrator.Status()
+	assert.Nil(t, statuses[len(statuses)-1].AppliedAt)
+
+	rolledBack, err = migrator.Down(len(migrator.migrations))
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrator.migrations)-1, rolledBack)
+	assert.False(t, db.Migrator().HasTable("team_members"))
+
+	applied, err = migrator.Up()
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrator.migrations), applied)
+}
+
+func TestChecksumVerification(t *testing.T) {
+	db := openTestDB(t)
+	migrator, err := New(db)
+	assert.NoError(t, err)
+	_, err = migrator.Up()
+	assert.NoError(t, err)
+
+	db.Model(&AppliedMigration{}).Where("version = ?", 1).Update("checksum", "tampered")
+	_, err = migrator.Up()
+	assert.ErrorContains(t, err, "checksum mismatch")
+
+	db.Model(&AppliedMigration{}).Where("version = ?", 1).Update("checksum", migrator.migrations[0].Checksum)
+	db.Create(&AppliedMigration{Version: 9999, Name: "from_the_future", Checksum: "x"})
+	_, err = migrator.Status()
+	assert.ErrorContains(t, err, "does not know about")
+}
+
+func TestExecScriptSplitsStatements(t *testing.T) {
+	db := openTestDB(t)
+
+	err := execScript(db, `-- a comment
+CREATE TABLE a (id INTEGER);
+CREATE TABLE b (
+    id INTEGER
+);
+INSERT INTO a (id) VALUES (1)`)
+	assert.NoError(t, err)
+	assert.True(t, db.Migrator().HasTable("b"))
+
+	var count int64
+	db.Table("a").Count(&count)
+	assert.Equal(t, int64(1), count)
+}
@@ -1,6 +1,5 @@
-CREATE DATABASE IF NOT EXISTS coaching_app;
-USE coaching_app;
-
+-- The schema originally applied from db/schema.sql. IF NOT EXISTS lets databases
+-- created from that script adopt the migration history.
 CREATE TABLE IF NOT EXISTS team_members (
     id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
@@ -27,4 +26,4 @@ CREATE TABLE IF NOT EXISTS team_member_assignments (
     PRIMARY KEY (team_id, team_member_id),
     FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
     FOREIGN KEY (team_member_id) REFERENCES team_members(id) ON DELETE CASCADE
-);
\ No newline at end of file
+);
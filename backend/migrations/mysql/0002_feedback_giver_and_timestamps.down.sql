@@ -0,0 +1,7 @@
+ALTER TABLE feedbacks DROP FOREIGN KEY fk_feedbacks_giver;
+ALTER TABLE feedbacks
+    DROP INDEX idx_feedbacks_giver_id,
+    DROP INDEX idx_feedbacks_created_at,
+    DROP COLUMN giver_id,
+    DROP COLUMN created_at,
+    DROP COLUMN updated_at;
@@ -0,0 +1,7 @@
+ALTER TABLE feedbacks
+    ADD COLUMN giver_id BIGINT UNSIGNED NULL,
+    ADD COLUMN created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    ADD COLUMN updated_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    ADD INDEX idx_feedbacks_giver_id (giver_id),
+    ADD INDEX idx_feedbacks_created_at (created_at),
+    ADD CONSTRAINT fk_feedbacks_giver FOREIGN KEY (giver_id) REFERENCES team_members(id) ON DELETE SET NULL;
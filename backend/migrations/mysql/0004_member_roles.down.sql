@@ -0,0 +1 @@
+DROP TABLE member_roles;
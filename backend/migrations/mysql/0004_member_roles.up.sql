@@ -0,0 +1,10 @@
+CREATE TABLE member_roles (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    member_id BIGINT UNSIGNED NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    team_id BIGINT UNSIGNED NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    INDEX idx_member_roles_member_id (member_id),
+    FOREIGN KEY (member_id) REFERENCES team_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE
+);
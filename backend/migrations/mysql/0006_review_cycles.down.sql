@@ -0,0 +1,7 @@
+ALTER TABLE feedbacks DROP FOREIGN KEY fk_feedbacks_review_cycle;
+ALTER TABLE feedbacks
+    DROP INDEX idx_feedbacks_review_cycle_id,
+    DROP COLUMN review_cycle_id;
+DROP TABLE review_assignments;
+DROP TABLE review_participants;
+DROP TABLE review_cycles;
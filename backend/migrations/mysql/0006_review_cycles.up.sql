@@ -0,0 +1,37 @@
+CREATE TABLE review_cycles (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    starts_at DATETIME(3) NOT NULL,
+    ends_at DATETIME(3) NOT NULL,
+    created_by_id BIGINT UNSIGNED NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    FOREIGN KEY (created_by_id) REFERENCES team_members(id) ON DELETE SET NULL
+);
+CREATE TABLE review_participants (
+    cycle_id BIGINT UNSIGNED NOT NULL,
+    member_id BIGINT UNSIGNED NOT NULL,
+    PRIMARY KEY (cycle_id, member_id),
+    FOREIGN KEY (cycle_id) REFERENCES review_cycles(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES team_members(id) ON DELETE CASCADE
+);
+CREATE TABLE review_assignments (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    cycle_id BIGINT UNSIGNED NOT NULL,
+    reviewer_id BIGINT UNSIGNED NOT NULL,
+    reviewee_id BIGINT UNSIGNED NOT NULL,
+    relationship VARCHAR(20) NOT NULL,
+    feedback_id BIGINT UNSIGNED NULL,
+    completed_at DATETIME(3) NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    UNIQUE KEY uq_review_assignments_pair (cycle_id, reviewer_id, reviewee_id),
+    INDEX idx_review_assignments_reviewer_id (reviewer_id),
+    INDEX idx_review_assignments_reviewee_id (reviewee_id),
+    FOREIGN KEY (cycle_id) REFERENCES review_cycles(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewer_id) REFERENCES team_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewee_id) REFERENCES team_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (feedback_id) REFERENCES feedbacks(id) ON DELETE SET NULL
+);
+ALTER TABLE feedbacks
+    ADD COLUMN review_cycle_id BIGINT UNSIGNED NULL,
+    ADD INDEX idx_feedbacks_review_cycle_id (review_cycle_id),
+    ADD CONSTRAINT fk_feedbacks_review_cycle FOREIGN KEY (review_cycle_id) REFERENCES review_cycles(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+DROP TABLE feedback_scores;
+DROP TABLE competencies;
@@ -0,0 +1,19 @@
+CREATE TABLE competencies (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT NULL,
+    min_score INT NOT NULL DEFAULT 1,
+    max_score INT NOT NULL DEFAULT 5,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3)
+);
+CREATE TABLE feedback_scores (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    feedback_id BIGINT UNSIGNED NOT NULL,
+    competency_id BIGINT UNSIGNED NOT NULL,
+    score INT NOT NULL,
+    UNIQUE KEY uq_feedback_scores_competency (feedback_id, competency_id),
+    INDEX idx_feedback_scores_competency_id (competency_id),
+    FOREIGN KEY (feedback_id) REFERENCES feedbacks(id) ON DELETE CASCADE,
+    FOREIGN KEY (competency_id) REFERENCES competencies(id)
+);
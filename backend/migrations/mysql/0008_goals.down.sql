@@ -0,0 +1,7 @@
+ALTER TABLE feedbacks DROP FOREIGN KEY fk_feedbacks_goal;
+ALTER TABLE feedbacks
+    DROP INDEX idx_feedbacks_goal_id,
+    DROP COLUMN goal_id;
+DROP TABLE goal_check_ins;
+DROP TABLE key_results;
+DROP TABLE goals;
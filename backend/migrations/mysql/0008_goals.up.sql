@@ -0,0 +1,45 @@
+CREATE TABLE goals (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NULL,
+    owner_type VARCHAR(20) NOT NULL,
+    owner_id BIGINT UNSIGNED NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    due_date DATETIME(3) NULL,
+    created_by_id BIGINT UNSIGNED NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    updated_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    INDEX idx_goals_owner (owner_type, owner_id),
+    FOREIGN KEY (created_by_id) REFERENCES team_members(id) ON DELETE SET NULL
+);
+CREATE TABLE key_results (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    goal_id BIGINT UNSIGNED NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    start_value DOUBLE NOT NULL DEFAULT 0,
+    target_value DOUBLE NOT NULL,
+    current_value DOUBLE NOT NULL DEFAULT 0,
+    unit VARCHAR(50) NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    updated_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    INDEX idx_key_results_goal_id (goal_id),
+    FOREIGN KEY (goal_id) REFERENCES goals(id) ON DELETE CASCADE
+);
+CREATE TABLE goal_check_ins (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    goal_id BIGINT UNSIGNED NOT NULL,
+    key_result_id BIGINT UNSIGNED NULL,
+    author_id BIGINT UNSIGNED NULL,
+    value DOUBLE NULL,
+    status VARCHAR(20) NULL,
+    note TEXT NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    INDEX idx_goal_check_ins_goal_id (goal_id),
+    FOREIGN KEY (goal_id) REFERENCES goals(id) ON DELETE CASCADE,
+    FOREIGN KEY (key_result_id) REFERENCES key_results(id) ON DELETE SET NULL,
+    FOREIGN KEY (author_id) REFERENCES team_members(id) ON DELETE SET NULL
+);
+ALTER TABLE feedbacks
+    ADD COLUMN goal_id BIGINT UNSIGNED NULL,
+    ADD INDEX idx_feedbacks_goal_id (goal_id),
+    ADD CONSTRAINT fk_feedbacks_goal FOREIGN KEY (goal_id) REFERENCES goals(id) ON DELETE SET NULL;
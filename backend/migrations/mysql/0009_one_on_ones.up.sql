@@ -0,0 +1,61 @@
+CREATE TABLE one_on_ones (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    host_id BIGINT UNSIGNED NOT NULL,
+    member_id BIGINT UNSIGNED NOT NULL,
+    cadence_days INT NOT NULL,
+    starts_at DATETIME(3) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    UNIQUE INDEX idx_one_on_ones_pair (host_id, member_id),
+    INDEX idx_one_on_ones_member_id (member_id),
+    FOREIGN KEY (host_id) REFERENCES team_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES team_members(id) ON DELETE CASCADE
+);
+CREATE TABLE one_on_one_meetings (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    one_on_one_id BIGINT UNSIGNED NOT NULL,
+    scheduled_at DATETIME(3) NOT NULL,
+    completed_at DATETIME(3) NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    INDEX idx_one_on_one_meetings_series (one_on_one_id, scheduled_at),
+    FOREIGN KEY (one_on_one_id) REFERENCES one_on_ones(id) ON DELETE CASCADE
+);
+CREATE TABLE agenda_items (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    meeting_id BIGINT UNSIGNED NOT NULL,
+    author_id BIGINT UNSIGNED NULL,
+    content TEXT NOT NULL,
+    feedback_id BIGINT UNSIGNED NULL,
+    discussed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    INDEX idx_agenda_items_meeting_id (meeting_id),
+    FOREIGN KEY (meeting_id) REFERENCES one_on_one_meetings(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES team_members(id) ON DELETE SET NULL,
+    FOREIGN KEY (feedback_id) REFERENCES feedbacks(id) ON DELETE SET NULL
+);
+CREATE TABLE meeting_notes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    meeting_id BIGINT UNSIGNED NOT NULL,
+    author_id BIGINT UNSIGNED NOT NULL,
+    content TEXT NOT NULL,
+    shared BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    updated_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    INDEX idx_meeting_notes_meeting_id (meeting_id),
+    FOREIGN KEY (meeting_id) REFERENCES one_on_one_meetings(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES team_members(id) ON DELETE CASCADE
+);
+CREATE TABLE action_items (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    one_on_one_id BIGINT UNSIGNED NOT NULL,
+    meeting_id BIGINT UNSIGNED NOT NULL,
+    owner_id BIGINT UNSIGNED NOT NULL,
+    content TEXT NOT NULL,
+    due_date DATETIME(3) NULL,
+    closed_at DATETIME(3) NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    INDEX idx_action_items_one_on_one_id (one_on_one_id),
+    FOREIGN KEY (one_on_one_id) REFERENCES one_on_ones(id) ON DELETE CASCADE,
+    FOREIGN KEY (meeting_id) REFERENCES one_on_one_meetings(id) ON DELETE CASCADE,
+    FOREIGN KEY (owner_id) REFERENCES team_members(id) ON DELETE CASCADE
+);
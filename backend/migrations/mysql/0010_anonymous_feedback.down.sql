@@ -0,0 +1,3 @@
+ALTER TABLE feedbacks
+    DROP COLUMN giver_hash,
+    DROP COLUMN anonymous;
@@ -0,0 +1,3 @@
+ALTER TABLE feedbacks
+    ADD COLUMN anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN giver_hash VARCHAR(64) NULL;
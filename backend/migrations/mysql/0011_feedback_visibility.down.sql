@@ -0,0 +1,2 @@
+DROP TABLE feedback_visibility_changes;
+ALTER TABLE feedbacks DROP COLUMN visibility;
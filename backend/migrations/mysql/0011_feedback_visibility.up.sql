@@ -0,0 +1,13 @@
+ALTER TABLE feedbacks
+    ADD COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'recipient';
+CREATE TABLE feedback_visibility_changes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    feedback_id BIGINT UNSIGNED NOT NULL,
+    changed_by_id BIGINT UNSIGNED NULL,
+    old_visibility VARCHAR(20) NOT NULL,
+    new_visibility VARCHAR(20) NOT NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    INDEX idx_feedback_visibility_changes_feedback_id (feedback_id),
+    FOREIGN KEY (feedback_id) REFERENCES feedbacks(id) ON DELETE CASCADE,
+    FOREIGN KEY (changed_by_id) REFERENCES team_members(id) ON DELETE SET NULL
+);
@@ -0,0 +1,7 @@
+DROP TABLE feedback_reactions;
+DROP TABLE feedback_replies;
+ALTER TABLE feedbacks DROP FOREIGN KEY fk_feedbacks_acknowledged_by;
+ALTER TABLE feedbacks
+    DROP INDEX idx_feedbacks_acknowledged_at,
+    DROP COLUMN acknowledged_by_id,
+    DROP COLUMN acknowledged_at;
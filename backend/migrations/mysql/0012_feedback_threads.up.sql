@@ -0,0 +1,25 @@
+ALTER TABLE feedbacks
+    ADD COLUMN acknowledged_at DATETIME(3) NULL,
+    ADD COLUMN acknowledged_by_id BIGINT UNSIGNED NULL,
+    ADD INDEX idx_feedbacks_acknowledged_at (acknowledged_at),
+    ADD CONSTRAINT fk_feedbacks_acknowledged_by FOREIGN KEY (acknowledged_by_id) REFERENCES team_members(id) ON DELETE SET NULL;
+CREATE TABLE feedback_replies (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    feedback_id BIGINT UNSIGNED NOT NULL,
+    author_id BIGINT UNSIGNED NULL,
+    content TEXT NOT NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    INDEX idx_feedback_replies_feedback_id (feedback_id),
+    FOREIGN KEY (feedback_id) REFERENCES feedbacks(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES team_members(id) ON DELETE SET NULL
+);
+CREATE TABLE feedback_reactions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    feedback_id BIGINT UNSIGNED NOT NULL,
+    member_id BIGINT UNSIGNED NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    UNIQUE INDEX idx_feedback_reactions_unique (feedback_id, member_id, kind),
+    FOREIGN KEY (feedback_id) REFERENCES feedbacks(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES team_members(id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+DROP TABLE feedback_versions;
+ALTER TABLE feedbacks
+    DROP COLUMN retracted_at,
+    DROP COLUMN version;
@@ -0,0 +1,14 @@
+ALTER TABLE feedbacks
+    ADD COLUMN version INT NOT NULL DEFAULT 1,
+    ADD COLUMN retracted_at DATETIME(3) NULL;
+CREATE TABLE feedback_versions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    feedback_id BIGINT UNSIGNED NOT NULL,
+    version INT NOT NULL,
+    content TEXT NOT NULL,
+    changed_by_id BIGINT UNSIGNED NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    UNIQUE INDEX idx_feedback_versions_version (feedback_id, version),
+    FOREIGN KEY (feedback_id) REFERENCES feedbacks(id) ON DELETE CASCADE,
+    FOREIGN KEY (changed_by_id) REFERENCES team_members(id) ON DELETE SET NULL
+);
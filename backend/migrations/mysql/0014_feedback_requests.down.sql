@@ -0,0 +1,6 @@
+ALTER TABLE feedbacks DROP FOREIGN KEY fk_feedbacks_request;
+ALTER TABLE feedbacks
+    DROP INDEX idx_feedbacks_request_id,
+    DROP COLUMN request_id;
+DROP TABLE feedback_request_recipients;
+DROP TABLE feedback_requests;
@@ -0,0 +1,25 @@
+CREATE TABLE feedback_requests (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    requester_id BIGINT UNSIGNED NOT NULL,
+    question TEXT NULL,
+    expires_at DATETIME(3) NOT NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    INDEX idx_feedback_requests_requester_id (requester_id),
+    FOREIGN KEY (requester_id) REFERENCES team_members(id) ON DELETE CASCADE
+);
+CREATE TABLE feedback_request_recipients (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    request_id BIGINT UNSIGNED NOT NULL,
+    recipient_id BIGINT UNSIGNED NOT NULL,
+    feedback_id BIGINT UNSIGNED NULL,
+    answered_at DATETIME(3) NULL,
+    UNIQUE KEY uq_feedback_request_recipients (request_id, recipient_id),
+    INDEX idx_feedback_request_recipients_recipient_id (recipient_id),
+    FOREIGN KEY (request_id) REFERENCES feedback_requests(id) ON DELETE CASCADE,
+    FOREIGN KEY (recipient_id) REFERENCES team_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (feedback_id) REFERENCES feedbacks(id) ON DELETE SET NULL
+);
+ALTER TABLE feedbacks
+    ADD COLUMN request_id BIGINT UNSIGNED NULL,
+    ADD INDEX idx_feedbacks_request_id (request_id),
+    ADD CONSTRAINT fk_feedbacks_request FOREIGN KEY (request_id) REFERENCES feedback_requests(id) ON DELETE SET NULL;
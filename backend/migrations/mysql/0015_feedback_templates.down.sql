@@ -0,0 +1,8 @@
+ALTER TABLE feedbacks DROP FOREIGN KEY fk_feedbacks_template;
+ALTER TABLE feedbacks
+    DROP INDEX idx_feedbacks_template_id,
+    DROP COLUMN template_version,
+    DROP COLUMN template_id;
+DROP TABLE feedback_answers;
+DROP TABLE template_questions;
+DROP TABLE feedback_templates;
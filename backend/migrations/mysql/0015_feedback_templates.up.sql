@@ -0,0 +1,38 @@
+CREATE TABLE feedback_templates (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT NULL,
+    version INT NOT NULL DEFAULT 1,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    updated_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3)
+);
+CREATE TABLE template_questions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    template_id BIGINT UNSIGNED NOT NULL,
+    version INT NOT NULL,
+    position INT NOT NULL,
+    prompt TEXT NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    min_rating INT NOT NULL DEFAULT 0,
+    max_rating INT NOT NULL DEFAULT 0,
+    options TEXT NULL,
+    INDEX idx_template_questions_version (template_id, version),
+    FOREIGN KEY (template_id) REFERENCES feedback_templates(id) ON DELETE CASCADE
+);
+CREATE TABLE feedback_answers (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    feedback_id BIGINT UNSIGNED NOT NULL,
+    question_id BIGINT UNSIGNED NOT NULL,
+    text TEXT NULL,
+    rating INT NULL,
+    UNIQUE KEY uq_feedback_answers_question (feedback_id, question_id),
+    FOREIGN KEY (feedback_id) REFERENCES feedbacks(id) ON DELETE CASCADE,
+    FOREIGN KEY (question_id) REFERENCES template_questions(id)
+);
+ALTER TABLE feedbacks
+    ADD COLUMN template_id BIGINT UNSIGNED NULL,
+    ADD COLUMN template_version INT NULL,
+    ADD INDEX idx_feedbacks_template_id (template_id),
+    ADD CONSTRAINT fk_feedbacks_template FOREIGN KEY (template_id) REFERENCES feedback_templates(id);
@@ -0,0 +1,5 @@
+DROP TABLE feedback_tags;
+DROP TABLE tags;
+ALTER TABLE feedbacks
+    DROP INDEX idx_feedbacks_category,
+    DROP COLUMN category;
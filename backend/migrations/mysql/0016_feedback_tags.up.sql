@@ -0,0 +1,15 @@
+ALTER TABLE feedbacks
+    ADD COLUMN category VARCHAR(20) NULL,
+    ADD INDEX idx_feedbacks_category (category);
+CREATE TABLE tags (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE
+);
+CREATE TABLE feedback_tags (
+    feedback_id BIGINT UNSIGNED NOT NULL,
+    tag_id BIGINT UNSIGNED NOT NULL,
+    PRIMARY KEY (feedback_id, tag_id),
+    INDEX idx_feedback_tags_tag_id (tag_id),
+    FOREIGN KEY (feedback_id) REFERENCES feedbacks(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+ALTER TABLE teams DROP INDEX ft_teams_name;
+ALTER TABLE team_members DROP INDEX ft_team_members_name_email;
+ALTER TABLE feedbacks DROP INDEX ft_feedbacks_content;
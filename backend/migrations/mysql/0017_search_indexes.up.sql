@@ -0,0 +1,3 @@
+ALTER TABLE feedbacks ADD FULLTEXT INDEX ft_feedbacks_content (content);
+ALTER TABLE team_members ADD FULLTEXT INDEX ft_team_members_name_email (name, email);
+ALTER TABLE teams ADD FULLTEXT INDEX ft_teams_name (name);
@@ -0,0 +1,4 @@
+ALTER TABLE feedbacks
+    DROP INDEX idx_feedbacks_target,
+    DROP INDEX idx_feedbacks_archived_at,
+    DROP COLUMN archived_at;
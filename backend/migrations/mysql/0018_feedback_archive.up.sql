@@ -0,0 +1,5 @@
+-- Feedback about a deleted member or team is archived rather than left dangling
+ALTER TABLE feedbacks
+    ADD COLUMN archived_at DATETIME(3) NULL,
+    ADD INDEX idx_feedbacks_archived_at (archived_at),
+    ADD INDEX idx_feedbacks_target (target_type, target_id);
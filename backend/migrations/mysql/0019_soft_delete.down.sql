@@ -0,0 +1,9 @@
+ALTER TABLE feedbacks
+    DROP INDEX idx_feedbacks_deleted_at,
+    DROP COLUMN deleted_at;
+ALTER TABLE teams
+    DROP INDEX idx_teams_deleted_at,
+    DROP COLUMN deleted_at;
+ALTER TABLE team_members
+    DROP INDEX idx_team_members_deleted_at,
+    DROP COLUMN deleted_at;
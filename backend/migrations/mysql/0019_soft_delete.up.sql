@@ -0,0 +1,9 @@
+ALTER TABLE team_members
+    ADD COLUMN deleted_at DATETIME(3) NULL,
+    ADD INDEX idx_team_members_deleted_at (deleted_at);
+ALTER TABLE teams
+    ADD COLUMN deleted_at DATETIME(3) NULL,
+    ADD INDEX idx_teams_deleted_at (deleted_at);
+ALTER TABLE feedbacks
+    ADD COLUMN deleted_at DATETIME(3) NULL,
+    ADD INDEX idx_feedbacks_deleted_at (deleted_at);
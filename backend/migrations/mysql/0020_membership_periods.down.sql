@@ -0,0 +1,11 @@
+-- Only the current memberships are kept
+DELETE FROM team_member_assignments WHERE ended_at IS NOT NULL;
+ALTER TABLE team_member_assignments
+    DROP COLUMN id,
+    DROP COLUMN role,
+    DROP COLUMN allocation,
+    DROP COLUMN started_at,
+    DROP COLUMN ended_at,
+    ADD PRIMARY KEY (team_id, team_member_id);
+ALTER TABLE team_member_assignments
+    DROP INDEX idx_team_member_assignments_team_id;
@@ -0,0 +1,15 @@
+-- Memberships become periods with a role and an allocation. A member can leave
+-- and rejoin a team, so the table gets a key of its own; the memberships that
+-- already exist are dated from the creation of their team.
+ALTER TABLE team_member_assignments
+    ADD INDEX idx_team_member_assignments_team_id (team_id);
+ALTER TABLE team_member_assignments
+    DROP PRIMARY KEY,
+    ADD COLUMN id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY FIRST,
+    ADD COLUMN role VARCHAR(50) NOT NULL DEFAULT 'member',
+    ADD COLUMN allocation INT NOT NULL DEFAULT 100,
+    ADD COLUMN started_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    ADD COLUMN ended_at DATETIME(3) NULL;
+UPDATE team_member_assignments a JOIN teams t ON t.id = a.team_id
+SET a.started_at = t.created_at
+WHERE t.created_at IS NOT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE teams
+    DROP FOREIGN KEY fk_teams_parent,
+    DROP INDEX idx_teams_parent_id,
+    DROP COLUMN parent_id;
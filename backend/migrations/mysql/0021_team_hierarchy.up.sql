@@ -0,0 +1,5 @@
+-- Teams can belong to a parent team, e.g. squads to a tribe
+ALTER TABLE teams
+    ADD COLUMN parent_id BIGINT UNSIGNED NULL,
+    ADD INDEX idx_teams_parent_id (parent_id),
+    ADD CONSTRAINT fk_teams_parent FOREIGN KEY (parent_id) REFERENCES teams(id) ON DELETE SET NULL;
@@ -0,0 +1,5 @@
+DROP TABLE manager_periods;
+ALTER TABLE team_members
+    DROP FOREIGN KEY fk_team_members_manager,
+    DROP INDEX idx_team_members_manager_id,
+    DROP COLUMN manager_id;
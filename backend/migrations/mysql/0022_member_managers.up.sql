@@ -0,0 +1,17 @@
+-- Members can report to a manager. team_members holds the current manager and
+-- manager_periods the history, one period per manager.
+ALTER TABLE team_members
+    ADD COLUMN manager_id BIGINT UNSIGNED NULL,
+    ADD INDEX idx_team_members_manager_id (manager_id),
+    ADD CONSTRAINT fk_team_members_manager FOREIGN KEY (manager_id) REFERENCES team_members(id) ON DELETE SET NULL;
+CREATE TABLE manager_periods (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    member_id BIGINT UNSIGNED NOT NULL,
+    manager_id BIGINT UNSIGNED NOT NULL,
+    started_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    ended_at DATETIME(3) NULL,
+    INDEX idx_manager_periods_member_id (member_id),
+    INDEX idx_manager_periods_manager_id (manager_id),
+    FOREIGN KEY (member_id) REFERENCES team_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (manager_id) REFERENCES team_members(id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+-- Agenda items pulled from feedback read its content when the agenda is read,
+-- so that edits, retractions and visibility changes apply to them. The copies
+-- stored so far are dropped.
+UPDATE agenda_items SET content = '' WHERE feedback_id IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS team_member_assignments;
+DROP TABLE IF EXISTS feedbacks;
+DROP TABLE IF EXISTS teams;
+DROP TABLE IF EXISTS team_members;
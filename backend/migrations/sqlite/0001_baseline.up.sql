@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS team_members (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    picture_url VARCHAR(255),
+    email VARCHAR(255) UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS teams (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) UNIQUE NOT NULL,
+    logo_url VARCHAR(255)
+);
+
+CREATE TABLE IF NOT EXISTS feedbacks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    content TEXT NOT NULL,
+    target_id INTEGER NOT NULL,
+    target_type VARCHAR(50) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS team_member_assignments (
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    team_member_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    PRIMARY KEY (team_id, team_member_id)
+);
@@ -0,0 +1,5 @@
+DROP INDEX idx_feedbacks_created_at;
+DROP INDEX idx_feedbacks_giver_id;
+ALTER TABLE feedbacks DROP COLUMN updated_at;
+ALTER TABLE feedbacks DROP COLUMN created_at;
+ALTER TABLE feedbacks DROP COLUMN giver_id;
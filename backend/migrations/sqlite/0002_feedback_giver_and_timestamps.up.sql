@@ -0,0 +1,7 @@
+-- SQLite cannot add columns with foreign keys or non-constant defaults, so
+-- giver_id is a plain column and the timestamps are set by the application.
+ALTER TABLE feedbacks ADD COLUMN giver_id INTEGER NULL;
+ALTER TABLE feedbacks ADD COLUMN created_at DATETIME;
+ALTER TABLE feedbacks ADD COLUMN updated_at DATETIME;
+CREATE INDEX idx_feedbacks_giver_id ON feedbacks(giver_id);
+CREATE INDEX idx_feedbacks_created_at ON feedbacks(created_at);
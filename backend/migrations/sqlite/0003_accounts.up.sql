@@ -0,0 +1,7 @@
+CREATE TABLE accounts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    member_id INTEGER NOT NULL UNIQUE REFERENCES team_members(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME
+);
@@ -0,0 +1,8 @@
+CREATE TABLE member_roles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    member_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL,
+    team_id INTEGER NULL REFERENCES teams(id) ON DELETE CASCADE,
+    created_at DATETIME
+);
+CREATE INDEX idx_member_roles_member_id ON member_roles(member_id);
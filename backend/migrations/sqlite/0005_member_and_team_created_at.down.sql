@@ -0,0 +1,2 @@
+ALTER TABLE teams DROP COLUMN created_at;
+ALTER TABLE team_members DROP COLUMN created_at;
@@ -0,0 +1,2 @@
+ALTER TABLE team_members ADD COLUMN created_at DATETIME;
+ALTER TABLE teams ADD COLUMN created_at DATETIME;
@@ -0,0 +1,5 @@
+DROP INDEX idx_feedbacks_review_cycle_id;
+ALTER TABLE feedbacks DROP COLUMN review_cycle_id;
+DROP TABLE review_assignments;
+DROP TABLE review_participants;
+DROP TABLE review_cycles;
@@ -0,0 +1,29 @@
+CREATE TABLE review_cycles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NOT NULL,
+    created_by_id INTEGER NULL REFERENCES team_members(id) ON DELETE SET NULL,
+    created_at DATETIME
+);
+CREATE TABLE review_participants (
+    cycle_id INTEGER NOT NULL REFERENCES review_cycles(id) ON DELETE CASCADE,
+    member_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    PRIMARY KEY (cycle_id, member_id)
+);
+CREATE TABLE review_assignments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    cycle_id INTEGER NOT NULL REFERENCES review_cycles(id) ON DELETE CASCADE,
+    reviewer_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    reviewee_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    relationship VARCHAR(20) NOT NULL,
+    feedback_id INTEGER NULL REFERENCES feedbacks(id) ON DELETE SET NULL,
+    completed_at DATETIME NULL,
+    created_at DATETIME,
+    UNIQUE (cycle_id, reviewer_id, reviewee_id)
+);
+CREATE INDEX idx_review_assignments_reviewer_id ON review_assignments(reviewer_id);
+CREATE INDEX idx_review_assignments_reviewee_id ON review_assignments(reviewee_id);
+-- As with giver_id, the column is added without its foreign key
+ALTER TABLE feedbacks ADD COLUMN review_cycle_id INTEGER NULL;
+CREATE INDEX idx_feedbacks_review_cycle_id ON feedbacks(review_cycle_id);
@@ -0,0 +1,17 @@
+CREATE TABLE competencies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT NULL,
+    min_score INTEGER NOT NULL DEFAULT 1,
+    max_score INTEGER NOT NULL DEFAULT 5,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME
+);
+CREATE TABLE feedback_scores (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    feedback_id INTEGER NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    competency_id INTEGER NOT NULL REFERENCES competencies(id),
+    score INTEGER NOT NULL,
+    UNIQUE (feedback_id, competency_id)
+);
+CREATE INDEX idx_feedback_scores_competency_id ON feedback_scores(competency_id);
@@ -0,0 +1,5 @@
+DROP INDEX idx_feedbacks_goal_id;
+ALTER TABLE feedbacks DROP COLUMN goal_id;
+DROP TABLE goal_check_ins;
+DROP TABLE key_results;
+DROP TABLE goals;
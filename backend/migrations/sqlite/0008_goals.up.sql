@@ -0,0 +1,39 @@
+CREATE TABLE goals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NULL,
+    owner_type VARCHAR(20) NOT NULL,
+    owner_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    due_date DATETIME NULL,
+    created_by_id INTEGER NULL REFERENCES team_members(id) ON DELETE SET NULL,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX idx_goals_owner ON goals(owner_type, owner_id);
+CREATE TABLE key_results (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    goal_id INTEGER NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    start_value REAL NOT NULL DEFAULT 0,
+    target_value REAL NOT NULL,
+    current_value REAL NOT NULL DEFAULT 0,
+    unit VARCHAR(50) NULL,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX idx_key_results_goal_id ON key_results(goal_id);
+CREATE TABLE goal_check_ins (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    goal_id INTEGER NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+    key_result_id INTEGER NULL REFERENCES key_results(id) ON DELETE SET NULL,
+    author_id INTEGER NULL REFERENCES team_members(id) ON DELETE SET NULL,
+    value REAL NULL,
+    status VARCHAR(20) NULL,
+    note TEXT NULL,
+    created_at DATETIME
+);
+CREATE INDEX idx_goal_check_ins_goal_id ON goal_check_ins(goal_id);
+-- As with giver_id, the column is added without its foreign key
+ALTER TABLE feedbacks ADD COLUMN goal_id INTEGER NULL;
+CREATE INDEX idx_feedbacks_goal_id ON feedbacks(goal_id);
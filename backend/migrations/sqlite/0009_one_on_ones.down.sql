@@ -0,0 +1,5 @@
+DROP TABLE action_items;
+DROP TABLE meeting_notes;
+DROP TABLE agenda_items;
+DROP TABLE one_on_one_meetings;
+DROP TABLE one_on_ones;
@@ -0,0 +1,50 @@
+CREATE TABLE one_on_ones (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    host_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    member_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    cadence_days INTEGER NOT NULL,
+    starts_at DATETIME NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_one_on_ones_pair ON one_on_ones(host_id, member_id);
+CREATE INDEX idx_one_on_ones_member_id ON one_on_ones(member_id);
+CREATE TABLE one_on_one_meetings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    one_on_one_id INTEGER NOT NULL REFERENCES one_on_ones(id) ON DELETE CASCADE,
+    scheduled_at DATETIME NOT NULL,
+    completed_at DATETIME NULL,
+    created_at DATETIME
+);
+CREATE INDEX idx_one_on_one_meetings_series ON one_on_one_meetings(one_on_one_id, scheduled_at);
+CREATE TABLE agenda_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    meeting_id INTEGER NOT NULL REFERENCES one_on_one_meetings(id) ON DELETE CASCADE,
+    author_id INTEGER NULL REFERENCES team_members(id) ON DELETE SET NULL,
+    content TEXT NOT NULL,
+    feedback_id INTEGER NULL REFERENCES feedbacks(id) ON DELETE SET NULL,
+    discussed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME
+);
+CREATE INDEX idx_agenda_items_meeting_id ON agenda_items(meeting_id);
+CREATE TABLE meeting_notes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    meeting_id INTEGER NOT NULL REFERENCES one_on_one_meetings(id) ON DELETE CASCADE,
+    author_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    shared BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX idx_meeting_notes_meeting_id ON meeting_notes(meeting_id);
+CREATE TABLE action_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    one_on_one_id INTEGER NOT NULL REFERENCES one_on_ones(id) ON DELETE CASCADE,
+    meeting_id INTEGER NOT NULL REFERENCES one_on_one_meetings(id) ON DELETE CASCADE,
+    owner_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    due_date DATETIME NULL,
+    closed_at DATETIME NULL,
+    created_at DATETIME
+);
+CREATE INDEX idx_action_items_one_on_one_id ON action_items(one_on_one_id);
@@ -0,0 +1,2 @@
+ALTER TABLE feedbacks DROP COLUMN giver_hash;
+ALTER TABLE feedbacks DROP COLUMN anonymous;
@@ -0,0 +1,2 @@
+ALTER TABLE feedbacks ADD COLUMN anonymous BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE feedbacks ADD COLUMN giver_hash VARCHAR(64) NULL;
@@ -0,0 +1,10 @@
+ALTER TABLE feedbacks ADD COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'recipient';
+CREATE TABLE feedback_visibility_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    feedback_id INTEGER NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    changed_by_id INTEGER NULL REFERENCES team_members(id) ON DELETE SET NULL,
+    old_visibility VARCHAR(20) NOT NULL,
+    new_visibility VARCHAR(20) NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX idx_feedback_visibility_changes_feedback_id ON feedback_visibility_changes(feedback_id);
@@ -0,0 +1,5 @@
+DROP TABLE feedback_reactions;
+DROP TABLE feedback_replies;
+DROP INDEX idx_feedbacks_acknowledged_at;
+ALTER TABLE feedbacks DROP COLUMN acknowledged_by_id;
+ALTER TABLE feedbacks DROP COLUMN acknowledged_at;
@@ -0,0 +1,20 @@
+-- As with giver_id, the column is added without its foreign key
+ALTER TABLE feedbacks ADD COLUMN acknowledged_at DATETIME NULL;
+ALTER TABLE feedbacks ADD COLUMN acknowledged_by_id INTEGER NULL;
+CREATE INDEX idx_feedbacks_acknowledged_at ON feedbacks(acknowledged_at);
+CREATE TABLE feedback_replies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    feedback_id INTEGER NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    author_id INTEGER NULL REFERENCES team_members(id) ON DELETE SET NULL,
+    content TEXT NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX idx_feedback_replies_feedback_id ON feedback_replies(feedback_id);
+CREATE TABLE feedback_reactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    feedback_id INTEGER NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    member_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_feedback_reactions_unique ON feedback_reactions(feedback_id, member_id, kind);
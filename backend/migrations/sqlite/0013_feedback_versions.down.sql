@@ -0,0 +1,3 @@
+DROP TABLE feedback_versions;
+ALTER TABLE feedbacks DROP COLUMN retracted_at;
+ALTER TABLE feedbacks DROP COLUMN version;
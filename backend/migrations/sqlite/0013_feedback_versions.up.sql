@@ -0,0 +1,11 @@
+ALTER TABLE feedbacks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE feedbacks ADD COLUMN retracted_at DATETIME NULL;
+CREATE TABLE feedback_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    feedback_id INTEGER NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    changed_by_id INTEGER NULL REFERENCES team_members(id) ON DELETE SET NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_feedback_versions_version ON feedback_versions(feedback_id, version);
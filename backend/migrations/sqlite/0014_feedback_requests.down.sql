@@ -0,0 +1,4 @@
+DROP INDEX idx_feedbacks_request_id;
+ALTER TABLE feedbacks DROP COLUMN request_id;
+DROP TABLE feedback_request_recipients;
+DROP TABLE feedback_requests;
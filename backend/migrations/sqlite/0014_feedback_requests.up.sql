@@ -0,0 +1,19 @@
+CREATE TABLE feedback_requests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    requester_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    question TEXT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX idx_feedback_requests_requester_id ON feedback_requests(requester_id);
+CREATE TABLE feedback_request_recipients (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    request_id INTEGER NOT NULL REFERENCES feedback_requests(id) ON DELETE CASCADE,
+    recipient_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    feedback_id INTEGER NULL REFERENCES feedbacks(id) ON DELETE SET NULL,
+    answered_at DATETIME NULL
+);
+CREATE UNIQUE INDEX uq_feedback_request_recipients ON feedback_request_recipients(request_id, recipient_id);
+CREATE INDEX idx_feedback_request_recipients_recipient_id ON feedback_request_recipients(recipient_id);
+ALTER TABLE feedbacks ADD COLUMN request_id INTEGER NULL;
+CREATE INDEX idx_feedbacks_request_id ON feedbacks(request_id);
@@ -0,0 +1,6 @@
+DROP INDEX idx_feedbacks_template_id;
+ALTER TABLE feedbacks DROP COLUMN template_version;
+ALTER TABLE feedbacks DROP COLUMN template_id;
+DROP TABLE feedback_answers;
+DROP TABLE template_questions;
+DROP TABLE feedback_templates;
@@ -0,0 +1,33 @@
+CREATE TABLE feedback_templates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE TABLE template_questions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    template_id INTEGER NOT NULL REFERENCES feedback_templates(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    prompt TEXT NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    min_rating INTEGER NOT NULL DEFAULT 0,
+    max_rating INTEGER NOT NULL DEFAULT 0,
+    options TEXT NULL
+);
+CREATE INDEX idx_template_questions_version ON template_questions(template_id, version);
+CREATE TABLE feedback_answers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    feedback_id INTEGER NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    question_id INTEGER NOT NULL REFERENCES template_questions(id),
+    text TEXT NULL,
+    rating INTEGER NULL
+);
+CREATE UNIQUE INDEX uq_feedback_answers_question ON feedback_answers(feedback_id, question_id);
+ALTER TABLE feedbacks ADD COLUMN template_id INTEGER NULL;
+ALTER TABLE feedbacks ADD COLUMN template_version INTEGER NULL;
+CREATE INDEX idx_feedbacks_template_id ON feedbacks(template_id);
@@ -0,0 +1,4 @@
+DROP TABLE feedback_tags;
+DROP TABLE tags;
+DROP INDEX idx_feedbacks_category;
+ALTER TABLE feedbacks DROP COLUMN category;
@@ -0,0 +1,12 @@
+ALTER TABLE feedbacks ADD COLUMN category VARCHAR(20) NULL;
+CREATE INDEX idx_feedbacks_category ON feedbacks(category);
+CREATE TABLE tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(50) NOT NULL UNIQUE
+);
+CREATE TABLE feedback_tags (
+    feedback_id INTEGER NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (feedback_id, tag_id)
+);
+CREATE INDEX idx_feedback_tags_tag_id ON feedback_tags(tag_id);
@@ -0,0 +1 @@
+-- SQLite has no FULLTEXT indexes: searches run on an index built in process.
@@ -0,0 +1,3 @@
+DROP INDEX idx_feedbacks_target;
+DROP INDEX idx_feedbacks_archived_at;
+ALTER TABLE feedbacks DROP COLUMN archived_at;
@@ -0,0 +1,4 @@
+-- Feedback about a deleted member or team is archived rather than left dangling
+ALTER TABLE feedbacks ADD COLUMN archived_at DATETIME NULL;
+CREATE INDEX idx_feedbacks_archived_at ON feedbacks(archived_at);
+CREATE INDEX idx_feedbacks_target ON feedbacks(target_type, target_id);
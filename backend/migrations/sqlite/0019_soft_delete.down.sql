@@ -0,0 +1,6 @@
+DROP INDEX idx_feedbacks_deleted_at;
+ALTER TABLE feedbacks DROP COLUMN deleted_at;
+DROP INDEX idx_teams_deleted_at;
+ALTER TABLE teams DROP COLUMN deleted_at;
+DROP INDEX idx_team_members_deleted_at;
+ALTER TABLE team_members DROP COLUMN deleted_at;
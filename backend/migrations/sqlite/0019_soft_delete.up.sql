@@ -0,0 +1,6 @@
+ALTER TABLE team_members ADD COLUMN deleted_at DATETIME NULL;
+CREATE INDEX idx_team_members_deleted_at ON team_members(deleted_at);
+ALTER TABLE teams ADD COLUMN deleted_at DATETIME NULL;
+CREATE INDEX idx_teams_deleted_at ON teams(deleted_at);
+ALTER TABLE feedbacks ADD COLUMN deleted_at DATETIME NULL;
+CREATE INDEX idx_feedbacks_deleted_at ON feedbacks(deleted_at);
@@ -0,0 +1,10 @@
+-- Only the current memberships are kept
+CREATE TABLE team_member_pairs (
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    team_member_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    PRIMARY KEY (team_id, team_member_id)
+);
+INSERT INTO team_member_pairs (team_id, team_member_id)
+SELECT DISTINCT team_id, team_member_id FROM team_member_assignments WHERE ended_at IS NULL;
+DROP TABLE team_member_assignments;
+ALTER TABLE team_member_pairs RENAME TO team_member_assignments;
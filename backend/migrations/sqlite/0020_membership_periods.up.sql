@@ -0,0 +1,19 @@
+-- Memberships become periods with a role and an allocation. A member can leave
+-- and rejoin a team, so the table gets a key of its own; the memberships that
+-- already exist are dated from the creation of their team.
+CREATE TABLE team_member_periods (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    team_member_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL DEFAULT 'member',
+    allocation INTEGER NOT NULL DEFAULT 100,
+    started_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ended_at DATETIME NULL
+);
+INSERT INTO team_member_periods (team_id, team_member_id, started_at)
+SELECT a.team_id, a.team_member_id, COALESCE(t.created_at, CURRENT_TIMESTAMP)
+FROM team_member_assignments a LEFT JOIN teams t ON t.id = a.team_id;
+DROP TABLE team_member_assignments;
+ALTER TABLE team_member_periods RENAME TO team_member_assignments;
+CREATE INDEX idx_team_member_assignments_team_id ON team_member_assignments(team_id);
+CREATE INDEX idx_team_member_assignments_team_member_id ON team_member_assignments(team_member_id);
@@ -0,0 +1,2 @@
+DROP INDEX idx_teams_parent_id;
+ALTER TABLE teams DROP COLUMN parent_id;
@@ -0,0 +1,3 @@
+-- Teams can belong to a parent team, e.g. squads to a tribe
+ALTER TABLE teams ADD COLUMN parent_id INTEGER NULL REFERENCES teams(id) ON DELETE SET NULL;
+CREATE INDEX idx_teams_parent_id ON teams(parent_id);
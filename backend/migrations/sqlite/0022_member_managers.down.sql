@@ -0,0 +1,3 @@
+DROP TABLE manager_periods;
+DROP INDEX idx_team_members_manager_id;
+ALTER TABLE team_members DROP COLUMN manager_id;
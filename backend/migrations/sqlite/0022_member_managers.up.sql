@@ -0,0 +1,13 @@
+-- Members can report to a manager. team_members holds the current manager and
+-- manager_periods the history, one period per manager.
+ALTER TABLE team_members ADD COLUMN manager_id INTEGER NULL REFERENCES team_members(id) ON DELETE SET NULL;
+CREATE INDEX idx_team_members_manager_id ON team_members(manager_id);
+CREATE TABLE manager_periods (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    member_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    manager_id INTEGER NOT NULL REFERENCES team_members(id) ON DELETE CASCADE,
+    started_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ended_at DATETIME NULL
+);
+CREATE INDEX idx_manager_periods_member_id ON manager_periods(member_id);
+CREATE INDEX idx_manager_periods_manager_id ON manager_periods(manager_id);
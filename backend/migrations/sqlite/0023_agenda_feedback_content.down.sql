@@ -0,0 +1 @@
+-- The dropped copies are read from the feedback, so there is nothing to restore.
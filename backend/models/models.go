@@ -1,22 +1,539 @@
 package models
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type TeamMember struct {
-	ID         uint64 `gorm:"primaryKey;column:id"`
-	Name       string `gorm:"column:name"`
-	PictureURL string `gorm:"column:picture_url"`
-	Email      string `gorm:"column:email;unique"`
+	ID         uint64    `gorm:"primaryKey;column:id"`
+	Name       string    `gorm:"column:name"`
+	PictureURL string    `gorm:"column:picture_url"`
+	Email      string    `gorm:"column:email;unique"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+	// ManagerID is the member the member reports to now, see ManagerPeriod
+	ManagerID *uint64 `gorm:"column:manager_id;index"`
+	// DeletedAt is set while the member is soft-deleted, until it is restored or purged
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index"`
+}
+
+// ManagerPeriod is a period during which a member reports to a manager. The
+// period is open while EndedAt is nil; changing manager closes it.
+type ManagerPeriod struct {
+	ID        uint64     `gorm:"primaryKey;column:id"`
+	MemberID  uint64     `gorm:"column:member_id;index"`
+	ManagerID uint64     `gorm:"column:manager_id;index"`
+	StartedAt time.Time  `gorm:"column:started_at"`
+	EndedAt   *time.Time `gorm:"column:ended_at"`
+	// Manager is filled in by the manager history
+	Manager *TeamMember `gorm:"-" json:",omitempty"`
 }
 
 type Team struct {
-	ID      uint64       `gorm:"primaryKey;column:id"`
-	Name    string       `gorm:"column:name;unique"`
-	LogoURL string       `gorm:"column:logo_url"`
+	ID        uint64    `gorm:"primaryKey;column:id"`
+	Name      string    `gorm:"column:name;unique"`
+	LogoURL   string    `gorm:"column:logo_url"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	// ParentID is the team this one belongs to, nil for a top-level team
+	ParentID *uint64 `gorm:"column:parent_id;index"`
+	// Members are the members on the team now, see TeamAssignment
 	Members []TeamMember `gorm:"many2many:team_member_assignments;"`
+	// DeletedAt is set while the team is soft-deleted. Its memberships are kept
+	// so that restoring it brings them back.
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index"`
+}
+
+// Roles of a member within a team. An open lead assignment grants the
+// team_lead role on its team.
+const (
+	TeamRoleMember = "member"
+	TeamRoleLead   = "lead"
+)
+
+// TeamRoles lists the valid values of TeamAssignment.Role
+var TeamRoles = []string{TeamRoleMember, TeamRoleLead}
+
+// TeamAssignment is a period during which a member belongs to a team, with
+// their role in it and the percentage of their time it takes. The member is on
+// the team while EndedAt is nil; leaving or changing role closes the period.
+// Team.Members holds the members with an open period.
+type TeamAssignment struct {
+	ID         uint64     `gorm:"primaryKey;column:id"`
+	TeamID     uint64     `gorm:"column:team_id;index"`
+	MemberID   uint64     `gorm:"column:team_member_id;index"`
+	Role       string     `gorm:"column:role;default:member"`
+	Allocation int        `gorm:"column:allocation;default:100"`
+	StartedAt  time.Time  `gorm:"column:started_at"`
+	EndedAt    *time.Time `gorm:"column:ended_at"`
+	// Team and Member are filled in by the timelines
+	Team   *Team       `gorm:"foreignKey:TeamID" json:",omitempty"`
+	Member *TeamMember `gorm:"foreignKey:MemberID" json:",omitempty"`
+}
+
+func (TeamAssignment) TableName() string {
+	return "team_member_assignments"
 }
 
+// Active reports whether the member was on the team at the given time
+func (a TeamAssignment) Active(at time.Time) bool {
+	return !a.StartedAt.After(at) && (a.EndedAt == nil || a.EndedAt.After(at))
+}
+
+// Feedback is given by GiverID about a member or a team. Anonymous feedback
+// has no GiverID; its giver is only known through GiverHash, a keyed hash that
+// tells the givers apart without naming them and is never serialized.
 type Feedback struct {
+	ID            uint64  `gorm:"primaryKey;column:id"`
+	Content       string  `gorm:"column:content"`
+	TargetID      uint64  `gorm:"column:target_id"`
+	TargetType    string  `gorm:"column:target_type"`
+	GiverID       *uint64 `gorm:"column:giver_id;index"`
+	ReviewCycleID *uint64 `gorm:"column:review_cycle_id;index"`
+	GoalID        *uint64 `gorm:"column:goal_id;index"`
+	RequestID     *uint64 `gorm:"column:request_id;index"`
+	// TemplateID and TemplateVersion name the template questions Answers refer to
+	TemplateID      *uint64 `gorm:"column:template_id;index"`
+	TemplateVersion *int    `gorm:"column:template_version"`
+	Anonymous       bool    `gorm:"column:anonymous"`
+	Visibility      string  `gorm:"column:visibility;default:recipient"`
+	Category        string  `gorm:"column:category"`
+	// AcknowledgedAt is set when the recipient first acknowledges the feedback
+	AcknowledgedAt   *time.Time `gorm:"column:acknowledged_at"`
+	AcknowledgedByID *uint64    `gorm:"column:acknowledged_by_id"`
+	// Version counts the edits of the content, starting at 1. Retracted
+	// feedback is kept as a tombstone: RetractedAt is set and Content is empty.
+	Version     int        `gorm:"column:version;default:1"`
+	RetractedAt *time.Time `gorm:"column:retracted_at"`
+	// ArchivedAt is set when the member or team the feedback is about is deleted
+	// and the feedback is kept
+	ArchivedAt *time.Time `gorm:"column:archived_at"`
+	// DeletedAt is set when the feedback is deleted along with its target
+	DeletedAt gorm.DeletedAt   `gorm:"column:deleted_at;index"`
+	GiverHash string           `gorm:"column:giver_hash" json:"-"`
+	CreatedAt time.Time        `gorm:"column:created_at;index"`
+	UpdatedAt time.Time        `gorm:"column:updated_at"`
+	Scores    []FeedbackScore  `gorm:"foreignKey:FeedbackID"`
+	Answers   []FeedbackAnswer `gorm:"foreignKey:FeedbackID"`
+	Tags      []Tag            `gorm:"many2many:feedback_tags;joinForeignKey:FeedbackID;joinReferences:TagID"`
+	// Target and Giver are only filled in when a feedback list is expanded
+	Target *FeedbackParty `gorm:"-" json:",omitempty"`
+	Giver  *FeedbackParty `gorm:"-" json:",omitempty"`
+}
+
+// FeedbackParty sums up the member or team a feedback entry is about, or the
+// member who gave it. PictureURL is the picture of a member or the logo of a team.
+type FeedbackParty struct {
+	Type       string
+	ID         uint64
+	Name       string
+	PictureURL string
+}
+
+const (
+	// VisibilityPrivate feedback is a note only its author can read
+	VisibilityPrivate = "private"
+	// VisibilityManager feedback is read by the leads and the manager of the
+	// recipient, coaches and admins, but not by the recipient
+	VisibilityManager = "manager"
+	// VisibilityRecipient feedback is also read by the recipient: the target
+	// member, or the members of the target team
+	VisibilityRecipient = "recipient"
+	// VisibilityTeam feedback about a member is also read by their teammates
+	VisibilityTeam = "team"
+)
+
+// FeedbackVisibilities lists the valid values of Feedback.Visibility
+var FeedbackVisibilities = []string{VisibilityPrivate, VisibilityManager, VisibilityRecipient, VisibilityTeam}
+
+const (
+	CategoryPraise      = "praise"
+	CategoryImprovement = "improvement"
+	CategoryObservation = "observation"
+)
+
+// FeedbackCategories lists the valid values of Feedback.Category, which is optional
+var FeedbackCategories = []string{CategoryPraise, CategoryImprovement, CategoryObservation}
+
+// Tag is a free-form label of feedback. Names are stored trimmed and in lower case.
+type Tag struct {
+	ID   uint64 `gorm:"primaryKey;column:id"`
+	Name string `gorm:"column:name;unique"`
+}
+
+// VisibilityChange records a change of the visibility of a feedback entry
+type VisibilityChange struct {
+	ID            uint64    `gorm:"primaryKey;column:id"`
+	FeedbackID    uint64    `gorm:"column:feedback_id;index"`
+	ChangedByID   *uint64   `gorm:"column:changed_by_id"`
+	OldVisibility string    `gorm:"column:old_visibility"`
+	NewVisibility string    `gorm:"column:new_visibility"`
+	CreatedAt     time.Time `gorm:"column:created_at"`
+}
+
+func (VisibilityChange) TableName() string {
+	return "feedback_visibility_changes"
+}
+
+// FeedbackRequest asks some members for feedback about the requester. Each
+// recipient answers at most once, until the request expires.
+type FeedbackRequest struct {
+	ID          uint64                     `gorm:"primaryKey;column:id"`
+	RequesterID uint64                     `gorm:"column:requester_id;index"`
+	Question    string                     `gorm:"column:question"`
+	ExpiresAt   time.Time                  `gorm:"column:expires_at"`
+	CreatedAt   time.Time                  `gorm:"column:created_at"`
+	Recipients  []FeedbackRequestRecipient `gorm:"foreignKey:RequestID"`
+}
+
+// Expired reports whether the request can no longer be answered
+func (r FeedbackRequest) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// FeedbackRequestRecipient is a member asked for feedback. FeedbackID and
+// AnsweredAt are set once they answer.
+type FeedbackRequestRecipient struct {
+	ID          uint64     `gorm:"primaryKey;column:id"`
+	RequestID   uint64     `gorm:"column:request_id"`
+	RecipientID uint64     `gorm:"column:recipient_id;index"`
+	FeedbackID  *uint64    `gorm:"column:feedback_id"`
+	AnsweredAt  *time.Time `gorm:"column:answered_at"`
+}
+
+// FeedbackVersion keeps the content a feedback entry had at Version. It is
+// recorded when ChangedByID edits or retracts the entry; ChangedByID is empty
+// for anonymous feedback.
+type FeedbackVersion struct {
+	ID          uint64    `gorm:"primaryKey;column:id"`
+	FeedbackID  uint64    `gorm:"column:feedback_id;index"`
+	Version     int       `gorm:"column:version"`
+	Content     string    `gorm:"column:content"`
+	ChangedByID *uint64   `gorm:"column:changed_by_id"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+// FeedbackReply is one message of the conversation about a feedback entry
+type FeedbackReply struct {
+	ID         uint64    `gorm:"primaryKey;column:id"`
+	FeedbackID uint64    `gorm:"column:feedback_id;index"`
+	AuthorID   *uint64   `gorm:"column:author_id"`
+	Content    string    `gorm:"column:content"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+const (
+	ReactionLike       = "like"
+	ReactionLove       = "love"
+	ReactionCelebrate  = "celebrate"
+	ReactionThanks     = "thanks"
+	ReactionInsightful = "insightful"
+)
+
+// ReactionKinds lists the valid values of FeedbackReaction.Kind
+var ReactionKinds = []string{ReactionLike, ReactionLove, ReactionCelebrate, ReactionThanks, ReactionInsightful}
+
+// FeedbackReaction is a lightweight reaction of a member to a feedback entry.
+// A member reacts at most once with each kind.
+type FeedbackReaction struct {
+	ID         uint64    `gorm:"primaryKey;column:id"`
+	FeedbackID uint64    `gorm:"column:feedback_id"`
+	MemberID   uint64    `gorm:"column:member_id"`
+	Kind       string    `gorm:"column:kind"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+// Competency is one dimension of the competency framework that feedback can
+// be scored against, on a scale from MinScore to MaxScore
+type Competency struct {
+	ID          uint64    `gorm:"primaryKey;column:id"`
+	Name        string    `gorm:"column:name;unique"`
+	Description string    `gorm:"column:description"`
+	MinScore    int       `gorm:"column:min_score"`
+	MaxScore    int       `gorm:"column:max_score"`
+	Active      bool      `gorm:"column:active"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+// FeedbackScore is the score a feedback entry gives to one competency
+type FeedbackScore struct {
+	ID           uint64 `gorm:"primaryKey;column:id"`
+	FeedbackID   uint64 `gorm:"column:feedback_id"`
+	CompetencyID uint64 `gorm:"column:competency_id"`
+	Score        int    `gorm:"column:score"`
+}
+
+const (
+	QuestionText   = "text"
+	QuestionRating = "rating"
+	QuestionChoice = "choice"
+)
+
+// QuestionKinds lists the valid values of TemplateQuestion.Kind
+var QuestionKinds = []string{QuestionText, QuestionRating, QuestionChoice}
+
+// FeedbackTemplate is a questionnaire for a feedback situation. Changing its
+// questions creates a new Version; the questions of earlier versions are kept
+// so that the answers given against them stay interpretable.
+type FeedbackTemplate struct {
+	ID          uint64             `gorm:"primaryKey;column:id"`
+	Name        string             `gorm:"column:name;unique"`
+	Description string             `gorm:"column:description"`
+	Version     int                `gorm:"column:version"`
+	Active      bool               `gorm:"column:active"`
+	CreatedAt   time.Time          `gorm:"column:created_at"`
+	UpdatedAt   time.Time          `gorm:"column:updated_at"`
+	Questions   []TemplateQuestion `gorm:"-"`
+}
+
+// TemplateQuestion is one question of a template version. Rating questions are
+// answered on a scale from MinRating to MaxRating, choice questions with one of
+// their Options.
+type TemplateQuestion struct {
+	ID         uint64   `gorm:"primaryKey;column:id"`
+	TemplateID uint64   `gorm:"column:template_id"`
+	Version    int      `gorm:"column:version"`
+	Position   int      `gorm:"column:position"`
+	Prompt     string   `gorm:"column:prompt"`
+	Kind       string   `gorm:"column:kind"`
+	Required   bool     `gorm:"column:required"`
+	MinRating  int      `gorm:"column:min_rating"`
+	MaxRating  int      `gorm:"column:max_rating"`
+	Options    []string `gorm:"column:options;serializer:json"`
+}
+
+// FeedbackAnswer answers one template question: Rating for rating questions,
+// Text for the others
+type FeedbackAnswer struct {
 	ID         uint64 `gorm:"primaryKey;column:id"`
-	Content    string `gorm:"column:content"`
-	TargetID   uint64 `gorm:"column:target_id"`
-	TargetType string `gorm:"column:target_type"`
+	FeedbackID uint64 `gorm:"column:feedback_id"`
+	QuestionID uint64 `gorm:"column:question_id"`
+	Text       string `gorm:"column:text"`
+	Rating     *int   `gorm:"column:rating"`
+}
+
+// Account holds the local login credentials of a TeamMember
+type Account struct {
+	ID           uint64    `gorm:"primaryKey;column:id"`
+	MemberID     uint64    `gorm:"column:member_id;uniqueIndex"`
+	PasswordHash string    `gorm:"column:password_hash" json:"-"`
+	CreatedAt    time.Time `gorm:"column:created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at"`
+}
+
+const (
+	RoleAdmin    = "admin"
+	RoleCoach    = "coach"
+	RoleTeamLead = "team_lead"
+)
+
+// MemberRole grants a role to a TeamMember. Team lead roles are scoped to a
+// single team through TeamID; the other roles apply everywhere.
+type MemberRole struct {
+	ID        uint64    `gorm:"primaryKey;column:id"`
+	MemberID  uint64    `gorm:"column:member_id;index"`
+	Role      string    `gorm:"column:role"`
+	TeamID    *uint64   `gorm:"column:team_id"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+const (
+	ReviewSelf = "self"
+	ReviewPeer = "peer"
+	ReviewLead = "lead"
+)
+
+const (
+	CycleScheduled = "scheduled"
+	CycleOpen      = "open"
+	CycleLocked    = "locked"
+)
+
+// ReviewCycle is a 360-degree review round. Feedback can only be written in
+// the cycle between StartsAt and EndsAt; the cycle is locked afterwards.
+type ReviewCycle struct {
+	ID           uint64       `gorm:"primaryKey;column:id"`
+	Name         string       `gorm:"column:name"`
+	StartsAt     time.Time    `gorm:"column:starts_at"`
+	EndsAt       time.Time    `gorm:"column:ends_at"`
+	CreatedByID  *uint64      `gorm:"column:created_by_id"`
+	CreatedAt    time.Time    `gorm:"column:created_at"`
+	Status       string       `gorm:"-"`
+	Participants []TeamMember `gorm:"many2many:review_participants;joinForeignKey:CycleID;joinReferences:MemberID"`
+}
+
+// StatusAt returns whether the cycle is scheduled, open or locked at the given time
+func (c ReviewCycle) StatusAt(now time.Time) string {
+	switch {
+	case now.Before(c.StartsAt):
+		return CycleScheduled
+	case now.Before(c.EndsAt):
+		return CycleOpen
+	default:
+		return CycleLocked
+	}
+}
+
+// ReviewAssignment asks a reviewer to write feedback about a reviewee during a
+// cycle. It is completed by the feedback written for it.
+type ReviewAssignment struct {
+	ID           uint64     `gorm:"primaryKey;column:id"`
+	CycleID      uint64     `gorm:"column:cycle_id"`
+	ReviewerID   uint64     `gorm:"column:reviewer_id;index"`
+	RevieweeID   uint64     `gorm:"column:reviewee_id;index"`
+	Relationship string     `gorm:"column:relationship"`
+	FeedbackID   *uint64    `gorm:"column:feedback_id"`
+	CompletedAt  *time.Time `gorm:"column:completed_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at"`
+}
+
+const (
+	GoalNotStarted = "not_started"
+	GoalOnTrack    = "on_track"
+	GoalAtRisk     = "at_risk"
+	GoalOffTrack   = "off_track"
+	GoalCompleted  = "completed"
+	GoalCancelled  = "cancelled"
+)
+
+// GoalStatuses lists the valid values of Goal.Status
+var GoalStatuses = []string{GoalNotStarted, GoalOnTrack, GoalAtRisk, GoalOffTrack, GoalCompleted, GoalCancelled}
+
+// Goal is an objective owned by a member or a team. Like Feedback targets,
+// OwnerType is "member" or "team" and OwnerID points to that row.
+type Goal struct {
+	ID          uint64      `gorm:"primaryKey;column:id"`
+	Title       string      `gorm:"column:title"`
+	Description string      `gorm:"column:description"`
+	OwnerType   string      `gorm:"column:owner_type"`
+	OwnerID     uint64      `gorm:"column:owner_id"`
+	Status      string      `gorm:"column:status"`
+	DueDate     *time.Time  `gorm:"column:due_date"`
+	CreatedByID *uint64     `gorm:"column:created_by_id"`
+	CreatedAt   time.Time   `gorm:"column:created_at"`
+	UpdatedAt   time.Time   `gorm:"column:updated_at"`
+	Progress    float64     `gorm:"-"`
+	KeyResults  []KeyResult `gorm:"foreignKey:GoalID"`
+}
+
+// KeyResult is a measurable result of a Goal, going from StartValue to TargetValue
+type KeyResult struct {
+	ID           uint64    `gorm:"primaryKey;column:id"`
+	GoalID       uint64    `gorm:"column:goal_id"`
+	Title        string    `gorm:"column:title"`
+	StartValue   float64   `gorm:"column:start_value"`
+	TargetValue  float64   `gorm:"column:target_value"`
+	CurrentValue float64   `gorm:"column:current_value"`
+	Unit         string    `gorm:"column:unit"`
+	CreatedAt    time.Time `gorm:"column:created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at"`
+	Progress     float64   `gorm:"-"`
+}
+
+// FillProgress computes the Progress of the goal and of its key results. A key
+// result progresses linearly from its start to its target value; the goal
+// progresses by the average of its key results.
+func (g *Goal) FillProgress() {
+	g.Progress = 0
+	for i := range g.KeyResults {
+		kr := &g.KeyResults[i]
+		kr.Progress = 0
+		if kr.TargetValue != kr.StartValue {
+			kr.Progress = min(max((kr.CurrentValue-kr.StartValue)/(kr.TargetValue-kr.StartValue), 0), 1)
+		}
+		g.Progress += kr.Progress / float64(len(g.KeyResults))
+	}
+}
+
+// GoalCheckIn records progress on a goal: a new value for one of its key
+// results, a new status, a note, or any combination of them
+type GoalCheckIn struct {
+	ID          uint64    `gorm:"primaryKey;column:id"`
+	GoalID      uint64    `gorm:"column:goal_id;index"`
+	KeyResultID *uint64   `gorm:"column:key_result_id"`
+	AuthorID    *uint64   `gorm:"column:author_id"`
+	Value       *float64  `gorm:"column:value"`
+	Status      string    `gorm:"column:status"`
+	Note        string    `gorm:"column:note"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+// OneOnOne is a recurring one-on-one between a host (usually a coach or a
+// lead) and a member. Meetings are expected every CadenceDays from StartsAt.
+type OneOnOne struct {
+	ID            uint64     `gorm:"primaryKey;column:id"`
+	HostID        uint64     `gorm:"column:host_id"`
+	MemberID      uint64     `gorm:"column:member_id"`
+	CadenceDays   int        `gorm:"column:cadence_days"`
+	StartsAt      time.Time  `gorm:"column:starts_at"`
+	Active        bool       `gorm:"column:active"`
+	CreatedAt     time.Time  `gorm:"column:created_at"`
+	NextMeetingAt *time.Time `gorm:"-"`
+}
+
+// Participant reports whether the member is one side of the one-on-one
+func (o OneOnOne) Participant(memberID uint64) bool {
+	return o.HostID == memberID || o.MemberID == memberID
+}
+
+// ScheduleAfter returns the first date of the schedule after the last meeting,
+// or StartsAt when there was no meeting yet
+func (o OneOnOne) ScheduleAfter(last *OneOnOneMeeting) time.Time {
+	if last == nil || last.ScheduledAt.Before(o.StartsAt) {
+		return o.StartsAt
+	}
+	cadence := time.Duration(o.CadenceDays) * 24 * time.Hour
+	periods := last.ScheduledAt.Sub(o.StartsAt)/cadence + 1
+	return o.StartsAt.Add(periods * cadence)
+}
+
+// OneOnOneMeeting is one occurrence of a OneOnOne. CarriedOver holds the action
+// items of earlier meetings that were still open when it was scheduled.
+type OneOnOneMeeting struct {
+	ID          uint64        `gorm:"primaryKey;column:id"`
+	OneOnOneID  uint64        `gorm:"column:one_on_one_id"`
+	ScheduledAt time.Time     `gorm:"column:scheduled_at"`
+	CompletedAt *time.Time    `gorm:"column:completed_at"`
+	CreatedAt   time.Time     `gorm:"column:created_at"`
+	AgendaItems []AgendaItem  `gorm:"foreignKey:MeetingID"`
+	Notes       []MeetingNote `gorm:"foreignKey:MeetingID"`
+	ActionItems []ActionItem  `gorm:"foreignKey:MeetingID"`
+	CarriedOver []ActionItem  `gorm:"-"`
+}
+
+// AgendaItem is a topic either side adds to a meeting. Items pulled from
+// feedback point to it with FeedbackID.
+type AgendaItem struct {
+	ID         uint64    `gorm:"primaryKey;column:id"`
+	MeetingID  uint64    `gorm:"column:meeting_id"`
+	AuthorID   *uint64   `gorm:"column:author_id"`
+	Content    string    `gorm:"column:content"`
+	FeedbackID *uint64   `gorm:"column:feedback_id"`
+	Discussed  bool      `gorm:"column:discussed"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+// MeetingNote is a note taken during a meeting. Notes that are not Shared are
+// only visible to their author.
+type MeetingNote struct {
+	ID        uint64    `gorm:"primaryKey;column:id"`
+	MeetingID uint64    `gorm:"column:meeting_id"`
+	AuthorID  uint64    `gorm:"column:author_id"`
+	Content   string    `gorm:"column:content"`
+	Shared    bool      `gorm:"column:shared"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+// ActionItem is a follow-up agreed in a meeting. It stays on the agenda of the
+// next meetings of the one-on-one until it is closed.
+type ActionItem struct {
+	ID         uint64     `gorm:"primaryKey;column:id"`
+	OneOnOneID uint64     `gorm:"column:one_on_one_id"`
+	MeetingID  uint64     `gorm:"column:meeting_id"`
+	OwnerID    uint64     `gorm:"column:owner_id"`
+	Content    string     `gorm:"column:content"`
+	DueDate    *time.Time `gorm:"column:due_date"`
+	ClosedAt   *time.Time `gorm:"column:closed_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
 }
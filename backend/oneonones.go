@@ -0,0 +1,806 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultCadenceDays = 7
+	// feedbackLookback is how far back feedback is pulled into the agenda of
+	// the first meeting of a one-on-one
+	feedbackLookback = 30 * 24 * time.Hour
+)
+
+type oneOnOneRequest struct {
+	HostID      *uint64   `json:"hostid"`
+	MemberID    uint64    `json:"memberid" binding:"required"`
+	CadenceDays int       `json:"cadencedays"`
+	StartsAt    time.Time `json:"startsat" binding:"required"`
+}
+
+type oneOnOneUpdateRequest struct {
+	CadenceDays *int       `json:"cadencedays"`
+	StartsAt    *time.Time `json:"startsat"`
+	Active      *bool      `json:"active"`
+}
+
+type meetingRequest struct {
+	ScheduledAt *time.Time `json:"scheduledat"`
+}
+
+type meetingUpdateRequest struct {
+	ScheduledAt *time.Time `json:"scheduledat"`
+	Completed   *bool      `json:"completed"`
+}
+
+type agendaItemRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+type agendaItemUpdateRequest struct {
+	Content   *string `json:"content"`
+	Discussed *bool   `json:"discussed"`
+}
+
+type noteRequest struct {
+	Content string `json:"content" binding:"required"`
+	Shared  bool   `json:"shared"`
+}
+
+type noteUpdateRequest struct {
+	Content *string `json:"content"`
+	Shared  *bool   `json:"shared"`
+}
+
+type actionItemRequest struct {
+	Content string     `json:"content" binding:"required"`
+	OwnerID *uint64    `json:"ownerid"`
+	DueDate *time.Time `json:"duedate"`
+}
+
+type actionItemUpdateRequest struct {
+	Content *string    `json:"content"`
+	DueDate *time.Time `json:"duedate"`
+	Done    *bool      `json:"done"`
+}
+
+// oneOnOneParam loads the one-on-one named by the :id path parameter. Its
+// schedule is open to admins; with participantsOnly, only the two members of
+// the pair get through. It answers and returns nil otherwise.
+func (s *Server) oneOnOneParam(c *gin.Context, participantsOnly bool) *models.OneOnOne {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return nil
+	}
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return nil
+	}
+	oneOnOne, err := s.oneOnOnes.Get(id)
+	if err != nil {
+		respondLookupError(c, err, "One-on-one not found", "")
+		return nil
+	}
+	if !oneOnOne.Participant(perms.MemberID) && (participantsOnly || !perms.Admin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the participants can access this one-on-one"})
+		return nil
+	}
+	return oneOnOne
+}
+
+// meetingParam loads the meeting named by the :meeting_id path parameter
+// within a one-on-one, answering and returning nil when it cannot
+func (s *Server) meetingParam(c *gin.Context, oneOnOne *models.OneOnOne) *models.OneOnOneMeeting {
+	meetingID, ok := idParam(c, "meeting_id")
+	if !ok {
+		return nil
+	}
+	meeting, err := s.oneOnOnes.GetMeeting(oneOnOne.ID, meetingID)
+	if err != nil {
+		respondLookupError(c, err, "Meeting not found", "Failed to retrieve meeting: ")
+		return nil
+	}
+	return meeting
+}
+
+// fillNextMeeting sets NextMeetingAt from the latest meeting of the one-on-one
+func (s *Server) fillNextMeeting(oneOnOne *models.OneOnOne) error {
+	meetings, err := s.oneOnOnes.ListMeetings(oneOnOne.ID)
+	if err != nil {
+		return err
+	}
+	var last *models.OneOnOneMeeting
+	if len(meetings) > 0 {
+		last = &meetings[0]
+	}
+	next := oneOnOne.ScheduleAfter(last)
+	oneOnOne.NextMeetingAt = &next
+	return nil
+}
+
+// CreateOneOnOne sets up a recurring one-on-one. The host defaults to the
+// caller, who must be one of the pair unless they are an admin.
+func (s *Server) CreateOneOnOne(c *gin.Context) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+
+	var req oneOnOneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	oneOnOne := models.OneOnOne{
+		HostID:      perms.MemberID,
+		MemberID:    req.MemberID,
+		CadenceDays: cmp.Or(req.CadenceDays, defaultCadenceDays),
+		StartsAt:    req.StartsAt,
+		Active:      true,
+	}
+	if req.HostID != nil {
+		oneOnOne.HostID = *req.HostID
+	}
+	if oneOnOne.CadenceDays < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CadenceDays must be at least 1"})
+		return
+	}
+	if oneOnOne.HostID == oneOnOne.MemberID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A one-on-one needs two different members"})
+		return
+	}
+	if !oneOnOne.Participant(perms.MemberID) && !perms.Admin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only set up your own one-on-ones"})
+		return
+	}
+	if _, err := s.members.Get(oneOnOne.HostID); err != nil {
+		respondLookupError(c, err, "Host member not found", "Error finding host member: ")
+		return
+	}
+	if _, err := s.members.Get(oneOnOne.MemberID); err != nil {
+		respondLookupError(c, err, "Member not found", "Error finding member: ")
+		return
+	}
+
+	if err := s.oneOnOnes.Create(&oneOnOne); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			c.JSON(http.StatusConflict, gin.H{"error": "This pair already has a one-on-one"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create one-on-one: " + err.Error()})
+		return
+	}
+	oneOnOne.NextMeetingAt = &oneOnOne.StartsAt
+	c.JSON(http.StatusCreated, oneOnOne)
+}
+
+// GetOneOnOnes lists the one-on-ones of the caller. Admins see every
+// one-on-one and can filter them by member_id. Both can filter by active.
+func (s *Server) GetOneOnOnes(c *gin.Context) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	opts, err := parseListParams(c, "starts_at", "created_at")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := repository.OneOnOneFilter{MemberID: &perms.MemberID}
+	if perms.Admin {
+		if filter.MemberID, err = optionalIDQuery(c, "member_id"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if filter.Active, err = optionalBoolQuery(c, "active"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	oneOnOnes, total, err := s.oneOnOnes.List(filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve one-on-ones: " + err.Error()})
+		return
+	}
+	setPageHeaders(c, opts, total)
+	c.JSON(http.StatusOK, oneOnOnes)
+}
+
+// GetOneOnOne returns a one-on-one with the date of its next meeting
+func (s *Server) GetOneOnOne(c *gin.Context) {
+	oneOnOne := s.oneOnOneParam(c, false)
+	if oneOnOne == nil {
+		return
+	}
+	if err := s.fillNextMeeting(oneOnOne); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve meetings: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, oneOnOne)
+}
+
+// UpdateOneOnOne changes the schedule of a one-on-one or pauses it. The pair
+// cannot change.
+func (s *Server) UpdateOneOnOne(c *gin.Context) {
+	oneOnOne := s.oneOnOneParam(c, false)
+	if oneOnOne == nil {
+		return
+	}
+
+	var req oneOnOneUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	changes := map[string]any{}
+	if req.CadenceDays != nil {
+		if *req.CadenceDays < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "CadenceDays must be at least 1"})
+			return
+		}
+		changes["cadence_days"] = *req.CadenceDays
+	}
+	if req.StartsAt != nil {
+		changes["starts_at"] = *req.StartsAt
+	}
+	if req.Active != nil {
+		changes["active"] = *req.Active
+	}
+
+	updated, err := s.oneOnOnes.Update(oneOnOne.ID, changes)
+	if err != nil {
+		respondLookupError(c, err, "One-on-one not found", "Failed to update one-on-one: ")
+		return
+	}
+	if err := s.fillNextMeeting(updated); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve meetings: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// CreateMeeting adds the next meeting of a one-on-one, by default at the next
+// date of its schedule. Open action items of earlier meetings carry over to it.
+func (s *Server) CreateMeeting(c *gin.Context) {
+	oneOnOne := s.oneOnOneParam(c, true)
+	if oneOnOne == nil {
+		return
+	}
+	if !oneOnOne.Active {
+		c.JSON(http.StatusConflict, gin.H{"error": "One-on-one is paused"})
+		return
+	}
+
+	// The body is optional
+	var req meetingRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	meeting := models.OneOnOneMeeting{OneOnOneID: oneOnOne.ID}
+	if req.ScheduledAt != nil {
+		meeting.ScheduledAt = *req.ScheduledAt
+	} else {
+		if err := s.fillNextMeeting(oneOnOne); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve meetings: " + err.Error()})
+			return
+		}
+		meeting.ScheduledAt = *oneOnOne.NextMeetingAt
+	}
+
+	if err := s.oneOnOnes.CreateMeeting(&meeting); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create meeting: " + err.Error()})
+		return
+	}
+	created, err := s.oneOnOnes.GetMeeting(oneOnOne.ID, meeting.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve meeting: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetMeetings lists the meetings of a one-on-one, latest first, without their content
+func (s *Server) GetMeetings(c *gin.Context) {
+	oneOnOne := s.oneOnOneParam(c, true)
+	if oneOnOne == nil {
+		return
+	}
+	meetings, err := s.oneOnOnes.ListMeetings(oneOnOne.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve meetings: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, meetings)
+}
+
+// GetMeeting returns a meeting with its agenda, its action items, the action
+// items carried over from earlier meetings, and the notes the caller may read:
+// the shared ones and their own private ones
+func (s *Server) GetMeeting(c *gin.Context) {
+	oneOnOne := s.oneOnOneParam(c, true)
+	if oneOnOne == nil {
+		return
+	}
+	meeting := s.meetingParam(c, oneOnOne)
+	if meeting == nil {
+		return
+	}
+	s.respondMeeting(c, meeting)
+}
+
+// respondMeeting answers with the meeting as the caller may read it, with
+// the content of the agenda items pulled from feedback
+func (s *Server) respondMeeting(c *gin.Context, meeting *models.OneOnOneMeeting) {
+	hidePrivateNotes(meeting, CurrentIdentity(c).MemberID)
+	items, ok := s.loadAgendaFeedback(c, meeting.AgendaItems)
+	if !ok {
+		return
+	}
+	meeting.AgendaItems = items
+	c.JSON(http.StatusOK, meeting)
+}
+
+// hidePrivateNotes drops the notes of the meeting that are private to someone
+// other than the reader
+func hidePrivateNotes(meeting *models.OneOnOneMeeting, readerID uint64) {
+	meeting.Notes = slices.DeleteFunc(meeting.Notes, func(note models.MeetingNote) bool {
+		return !note.Shared && note.AuthorID != readerID
+	})
+}
+
+// loadAgendaFeedback reads the content of the agenda items pulled from
+// feedback from the feedback itself, so that they follow its edits. The items
+// whose feedback the caller may not read, or which was retracted or deleted,
+// are dropped. It answers and returns false when the feedback cannot be read.
+func (s *Server) loadAgendaFeedback(c *gin.Context, items []models.AgendaItem) ([]models.AgendaItem, bool) {
+	ids := []uint64{}
+	for _, item := range items {
+		if item.FeedbackID != nil {
+			ids = append(ids, *item.FeedbackID)
+		}
+	}
+	if len(ids) == 0 {
+		return items, true
+	}
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return nil, false
+	}
+	feedbacks, _, err := s.feedback.List(repository.FeedbackFilter{IDs: ids, Reader: perms.FeedbackReader()}, repository.ListOptions{Page: 1, PageSize: len(ids)})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feedbacks: " + err.Error()})
+		return nil, false
+	}
+	contents := map[uint64]string{}
+	for _, feedback := range feedbacks {
+		if feedback.RetractedAt == nil {
+			contents[feedback.ID] = feedback.Content
+		}
+	}
+	readable := []models.AgendaItem{}
+	for _, item := range items {
+		if item.FeedbackID != nil {
+			content, ok := contents[*item.FeedbackID]
+			if !ok {
+				continue
+			}
+			item.Content = content
+		}
+		readable = append(readable, item)
+	}
+	return readable, true
+}
+
+// UpdateMeeting reschedules a meeting or marks it as completed
+func (s *Server) UpdateMeeting(c *gin.Context) {
+	oneOnOne := s.oneOnOneParam(c, true)
+	if oneOnOne == nil {
+		return
+	}
+	meetingID, ok := idParam(c, "meeting_id")
+	if !ok {
+		return
+	}
+
+	var req meetingUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	changes := map[string]any{}
+	if req.ScheduledAt != nil {
+		changes["scheduled_at"] = *req.ScheduledAt
+	}
+	if req.Completed != nil {
+		if *req.Completed {
+			changes["completed_at"] = time.Now()
+		} else {
+			changes["completed_at"] = nil
+		}
+	}
+	if err := s.oneOnOnes.UpdateMeeting(oneOnOne.ID, meetingID, changes); err != nil {
+		respondLookupError(c, err, "Meeting not found", "Failed to update meeting: ")
+		return
+	}
+
+	meeting := s.meetingParam(c, oneOnOne)
+	if meeting == nil {
+		return
+	}
+	s.respondMeeting(c, meeting)
+}
+
+// AddAgendaItem adds a topic to a meeting. Either side of the pair can add one.
+func (s *Server) AddAgendaItem(c *gin.Context) {
+	oneOnOne := s.oneOnOneParam(c, true)
+	if oneOnOne == nil {
+		return
+	}
+	meeting := s.meetingParam(c, oneOnOne)
+	if meeting == nil {
+		return
+	}
+
+	var req agendaItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	authorID := CurrentIdentity(c).MemberID
+	item := models.AgendaItem{MeetingID: meeting.ID, AuthorID: &authorID, Content: req.Content}
+	if err := s.oneOnOnes.AddAgendaItem(&item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add agenda item: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+// PullFeedbackIntoAgenda adds the feedback received by the member since the
+// previous meeting to the agenda, skipping feedback already on it. Before the
+// first meeting it looks back 30 days; from overrides the start. Since both
+// sides read the agenda, only the feedback the member reads as its recipient
+// is pulled: neither private notes nor manager-only feedback, nor retracted
+// feedback.
+func (s *Server) PullFeedbackIntoAgenda(c *gin.Context) {
+	oneOnOne := s.oneOnOneParam(c, true)
+	if oneOnOne == nil {
+		return
+	}
+	meeting := s.meetingParam(c, oneOnOne)
+	if meeting == nil {
+		return
+	}
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+
+	from, _, err := dateRangeQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if from == nil {
+		meetings, err := s.oneOnOnes.ListMeetings(oneOnOne.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve meetings: " + err.Error()})
+			return
+		}
+		since := meeting.ScheduledAt.Add(-feedbackLookback)
+		for _, previous := range meetings {
+			if previous.ScheduledAt.Before(meeting.ScheduledAt) {
+				since = previous.ScheduledAt
+				break
+			}
+		}
+		from = &since
+	}
+
+	filter := repository.FeedbackFilter{
+		TargetType:  "member",
+		TargetID:    &oneOnOne.MemberID,
+		CreatedFrom: from,
+		Reader:      &repository.FeedbackReader{MemberID: oneOnOne.MemberID},
+	}
+	feedbacks, _, err := s.feedback.List(filter, repository.ListOptions{Page: 1, PageSize: maxPageSize, SortBy: "created_at"})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feedbacks: " + err.Error()})
+		return
+	}
+
+	onAgenda := map[uint64]bool{}
+	for _, item := range meeting.AgendaItems {
+		if item.FeedbackID != nil {
+			onAgenda[*item.FeedbackID] = true
+		}
+	}
+	added := []models.AgendaItem{}
+	for _, feedback := range feedbacks {
+		shared := feedback.Visibility == models.VisibilityRecipient || feedback.Visibility == models.VisibilityTeam
+		if onAgenda[feedback.ID] || !shared || feedback.RetractedAt != nil {
+			continue
+		}
+		// The content stays with the feedback and is read with the agenda
+		item := models.AgendaItem{
+			MeetingID:  meeting.ID,
+			AuthorID:   &perms.MemberID,
+			FeedbackID: &feedback.ID,
+		}
+		if err := s.oneOnOnes.AddAgendaItem(&item); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add agenda item: " + err.Error()})
+			return
+		}
+		item.Content = feedback.Content
+		added = append(added, item)
+	}
+	c.JSON(http.StatusCreated, added)
+}
+
+// UpdateAgendaItem rewords an agenda item or marks it as discussed. The items
+// pulled from feedback show the feedback and cannot be reworded.
+func (s *Server) UpdateAgendaItem(c *gin.Context) {
+	oneOnOne := s.oneOnOneParam(c, true)
+	if oneOnOne == nil {
+		return
+	}
+	meeting := s.meetingParam(c, oneOnOne)
+	if meeting == nil {
+		return
+	}
+	itemID, ok := idParam(c, "item_id")
+	if !ok {
+		return
+	}
+
+	// The items pulled from feedback the caller may not read are not theirs to change
+	agenda, ok := s.loadAgendaFeedback(c, meeting.AgendaItems)
+	if !ok {
+		return
+	}
+	if slices.ContainsFunc(meeting.AgendaItems, func(item models.AgendaItem) bool { return item.ID == itemID }) &&
+		!slices.ContainsFunc(agenda, func(item models.AgendaItem) bool { return item.ID == itemID }) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Agenda item not found"})
+		return
+	}
+
+	var req agendaItemUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	changes := map[string]any{}
+	if req.Content != nil {
+		if *req.Content == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Content cannot be empty"})
+			return
+		}
+		pulled := slices.ContainsFunc(agenda, func(item models.AgendaItem) bool {
+			return item.ID == itemID && item.FeedbackID != nil
+		})
+		if pulled {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Agenda items pulled from feedback show the feedback and cannot be reworded"})
+			return
+		}
+		changes["content"] = *req.Content
+	}
+	if req.Discussed != nil {
+		changes["discussed"] = *req.Discussed
+	}
+
+	item, err := s.oneOnOnes.UpdateAgendaItem(meeting.ID, itemID, changes)
+	if err != nil {
+		respondLookupError(c, err, "Agenda item not found", "Failed to update agenda item: ")
+		return
+	}
+	if i := slices.IndexFunc(agenda, func(shown models.AgendaItem) bool { return shown.ID == item.ID }); i >= 0 {
+		item.Content = agenda[i].Content
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// AddMeetingNote adds a note to a meeting. Notes are private to their author
+// unless shared.
+func (s *Server) AddMeetingNote(c *gin.Context) {
+	oneOnOne := s.oneOnOneParam(c, true)
+	if oneOnOne == nil {
+		return
+	}
+	meeting := s.meetingParam(c, oneOnOne)
+	if meeting == nil {
+		return
+	}
+
+	var req noteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	note := models.MeetingNote{
+		MeetingID: meeting.ID,
+		AuthorID:  CurrentIdentity(c).MemberID,
+		Content:   req.Content,
+		Shared:    req.Shared,
+	}
+	if err := s.oneOnOnes.AddNote(&note); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add note: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, note)
+}
+
+// UpdateMeetingNote edits, shares or unshares a note. Only its author can.
+func (s *Server) UpdateMeetingNote(c *gin.Context) {
+	oneOnOne := s.oneOnOneParam(c, true)
+	if oneOnOne == nil {
+		return
+	}
+	meeting := s.meetingParam(c, oneOnOne)
+	if meeting == nil {
+		return
+	}
+	noteID, ok := idParam(c, "note_id")
+	if !ok {
+		return
+	}
+	note, err := s.oneOnOnes.GetNote(meeting.ID, noteID)
+	if err != nil {
+		respondLookupError(c, err, "Note not found", "Failed to retrieve note: ")
+		return
+	}
+	if note.AuthorID != CurrentIdentity(c).MemberID {
+		// Private notes of the other side are not acknowledged at all
+		if !note.Shared {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		} else {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can change a note"})
+		}
+		return
+	}
+
+	var req noteUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	changes := map[string]any{}
+	if req.Content != nil {
+		if *req.Content == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Content cannot be empty"})
+			return
+		}
+		changes["content"] = *req.Content
+	}
+	if req.Shared != nil {
+		changes["shared"] = *req.Shared
+	}
+
+	updated, err := s.oneOnOnes.UpdateNote(meeting.ID, noteID, changes)
+	if err != nil {
+		respondLookupError(c, err, "Note not found", "Failed to update note: ")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// AddActionItem records a follow-up agreed in a meeting. The owner defaults
+// to the caller and must be one of the pair.
+func (s *Server) AddActionItem(c *gin.Context) {
+	oneOnOne := s.oneOnOneParam(c, true)
+	if oneOnOne == nil {
+		return
+	}
+	meeting := s.meetingParam(c, oneOnOne)
+	if meeting == nil {
+		return
+	}
+
+	var req actionItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	item := models.ActionItem{
+		OneOnOneID: oneOnOne.ID,
+		MeetingID:  meeting.ID,
+		OwnerID:    CurrentIdentity(c).MemberID,
+		Content:    req.Content,
+		DueDate:    req.DueDate,
+	}
+	if req.OwnerID != nil {
+		item.OwnerID = *req.OwnerID
+	}
+	if !oneOnOne.Participant(item.OwnerID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The owner of an action item must be one of the pair"})
+		return
+	}
+
+	if err := s.oneOnOnes.AddActionItem(&item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add action item: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+// GetActionItems lists the action items of a one-on-one, optionally filtered
+// by open and by owner_id
+func (s *Server) GetActionItems(c *gin.Context) {
+	oneOnOne := s.oneOnOneParam(c, true)
+	if oneOnOne == nil {
+		return
+	}
+
+	filter := repository.ActionItemFilter{OneOnOneID: oneOnOne.ID}
+	var err error
+	if filter.Open, err = optionalBoolQuery(c, "open"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.OwnerID, err = optionalIDQuery(c, "owner_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := s.oneOnOnes.ListActionItems(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve action items: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// UpdateActionItem edits an action item, closes it with done=true or reopens
+// it with done=false
+func (s *Server) UpdateActionItem(c *gin.Context) {
+	oneOnOne := s.oneOnOneParam(c, true)
+	if oneOnOne == nil {
+		return
+	}
+	itemID, ok := idParam(c, "action_id")
+	if !ok {
+		return
+	}
+
+	var req actionItemUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	changes := map[string]any{}
+	if req.Content != nil {
+		if *req.Content == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Content cannot be empty"})
+			return
+		}
+		changes["content"] = *req.Content
+	}
+	if req.DueDate != nil {
+		changes["due_date"] = *req.DueDate
+	}
+	if req.Done != nil {
+		if *req.Done {
+			changes["closed_at"] = time.Now()
+		} else {
+			changes["closed_at"] = nil
+		}
+	}
+
+	item, err := s.oneOnOnes.UpdateActionItem(oneOnOne.ID, itemID, changes)
+	if err != nil {
+		respondLookupError(c, err, "Action item not found", "Failed to update action item: ")
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
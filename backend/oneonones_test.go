@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"coaching-app/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOneOnOneSchedule(t *testing.T) {
+	setupTestDatabase()
+
+	coach := models.TeamMember{Name: "Coach", Email: "coach@example.com"}
+	member := models.TeamMember{Name: "Member", Email: "member@example.com"}
+	outsider := models.TeamMember{Name: "Outsider", Email: "outsider@example.com"}
+	testDB.Create(&coach)
+	testDB.Create(&member)
+	testDB.Create(&outsider)
+
+	payload := fmt.Sprintf(`{"memberid": %d, "startsat": "2026-03-02T10:00:00Z"}`, member.ID)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/oneonones/", fmt.Sprintf(`{"memberid": %d, "startsat": "2026-03-02T10:00:00Z"}`, coach.ID), coach.ID).Code)
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/oneonones/", fmt.Sprintf(`{"hostid": %d, "memberid": %d, "startsat": "2026-03-02T10:00:00Z"}`, coach.ID, member.ID), outsider.ID).Code)
+
+	w := serve("POST", "/oneonones/", payload, coach.ID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var oneOnOne models.OneOnOne
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &oneOnOne))
+	assert.Equal(t, coach.ID, oneOnOne.HostID)
+	assert.Equal(t, 7, oneOnOne.CadenceDays)
+	assert.Equal(t, http.StatusConflict, serve("POST", "/oneonones/", payload, coach.ID).Code)
+
+	assert.Equal(t, http.StatusForbidden, serve("GET", fmt.Sprintf("/oneonones/%d", oneOnOne.ID), "", outsider.ID).Code)
+
+	var oneOnOnes []models.OneOnOne
+	w = serve("GET", "/oneonones/", "", member.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &oneOnOnes))
+	assert.Len(t, oneOnOnes, 1)
+	w = serve("GET", "/oneonones/", "", outsider.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &oneOnOnes))
+	assert.Len(t, oneOnOnes, 0)
+
+	// Meetings follow the weekly schedule unless scheduled explicitly
+	var meeting models.OneOnOneMeeting
+	w = serve("POST", fmt.Sprintf("/oneonones/%d/meetings", oneOnOne.ID), "", coach.ID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &meeting))
+	assert.True(t, meeting.ScheduledAt.Equal(time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)))
+
+	w = serve("POST", fmt.Sprintf("/oneonones/%d/meetings", oneOnOne.ID), "", member.ID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &meeting))
+	assert.True(t, meeting.ScheduledAt.Equal(time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)))
+
+	w = serve("GET", fmt.Sprintf("/oneonones/%d", oneOnOne.ID), "", coach.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &oneOnOne))
+	if assert.NotNil(t, oneOnOne.NextMeetingAt) {
+		assert.True(t, oneOnOne.NextMeetingAt.Equal(time.Date(2026, 3, 16, 10, 0, 0, 0, time.UTC)))
+	}
+
+	w = serve("PUT", fmt.Sprintf("/oneonones/%d", oneOnOne.ID), `{"active": false}`, coach.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusConflict, serve("POST", fmt.Sprintf("/oneonones/%d/meetings", oneOnOne.ID), "", coach.ID).Code)
+}
+
+func TestOneOnOneMeetingContent(t *testing.T) {
+	setupTestDatabase()
+
+	coach := models.TeamMember{Name: "Coach", Email: "coach@example.com"}
+	member := models.TeamMember{Name: "Member", Email: "member@example.com"}
+	testDB.Create(&coach)
+	testDB.Create(&member)
+	oneOnOne := models.OneOnOne{HostID: coach.ID, MemberID: member.ID, CadenceDays: 7, StartsAt: time.Now().Add(-7 * 24 * time.Hour), Active: true}
+	testDB.Create(&oneOnOne)
+	first := models.OneOnOneMeeting{OneOnOneID: oneOnOne.ID, ScheduledAt: oneOnOne.StartsAt}
+	second := models.OneOnOneMeeting{OneOnOneID: oneOnOne.ID, ScheduledAt: time.Now().Add(24 * time.Hour)}
+	testDB.Create(&first)
+	testDB.Create(&second)
+	meetingPath := func(m models.OneOnOneMeeting) string {
+		return fmt.Sprintf("/oneonones/%d/meetings/%d", oneOnOne.ID, m.ID)
+	}
+
+	assert.Equal(t, http.StatusCreated, serve("POST", meetingPath(first)+"/agenda", `{"content": "Career path"}`, member.ID).Code)
+	assert.Equal(t, http.StatusCreated, serve("POST", meetingPath(first)+"/notes", `{"content": "Seems tired"}`, coach.ID).Code)
+	assert.Equal(t, http.StatusCreated, serve("POST", meetingPath(first)+"/notes", `{"content": "Agreed on a plan", "shared": true}`, coach.ID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", meetingPath(first)+"/actions", `{"content": "Nope", "ownerid": 99999}`, coach.ID).Code)
+
+	var open, closed models.ActionItem
+	w := serve("POST", meetingPath(first)+"/actions", fmt.Sprintf(`{"content": "Write the proposal", "ownerid": %d}`, member.ID), coach.ID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &open))
+	w = serve("POST", meetingPath(first)+"/actions", `{"content": "Book the training"}`, coach.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &closed))
+	assert.Equal(t, coach.ID, closed.OwnerID)
+	w = serve("PUT", fmt.Sprintf("/oneonones/%d/actions/%d", oneOnOne.ID, closed.ID), `{"done": true}`, coach.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The member only sees the shared note
+	var meeting models.OneOnOneMeeting
+	w = serve("GET", meetingPath(first), "", member.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &meeting))
+	assert.Len(t, meeting.AgendaItems, 1)
+	assert.Len(t, meeting.ActionItems, 2)
+	if assert.Len(t, meeting.Notes, 1) {
+		assert.Equal(t, "Agreed on a plan", meeting.Notes[0].Content)
+		w = serve("PUT", fmt.Sprintf("%s/notes/%d", meetingPath(first), meeting.Notes[0].ID), `{"content": "Edited"}`, member.ID)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	}
+	w = serve("GET", meetingPath(first), "", coach.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &meeting))
+	assert.Len(t, meeting.Notes, 2)
+
+	// Only the open action item carries over to the next meeting
+	w = serve("GET", meetingPath(second), "", member.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &meeting))
+	if assert.Len(t, meeting.CarriedOver, 1) {
+		assert.Equal(t, open.ID, meeting.CarriedOver[0].ID)
+	}
+
+	var items []models.ActionItem
+	w = serve("GET", fmt.Sprintf("/oneonones/%d/actions?open=true", oneOnOne.ID), "", member.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &items))
+	assert.Len(t, items, 1)
+
+	w = serve("PUT", meetingPath(second), `{"completed": true}`, coach.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &meeting))
+	assert.NotNil(t, meeting.CompletedAt)
+}
+
+func TestPullFeedbackIntoAgenda(t *testing.T) {
+	setupTestDatabase()
+
+	coach := models.TeamMember{Name: "Coach", Email: "coach@example.com"}
+	member := models.TeamMember{Name: "Member", Email: "member@example.com"}
+	testDB.Create(&coach)
+	testDB.Create(&member)
+	testDB.Create(&models.MemberRole{MemberID: coach.ID, Role: models.RoleCoach})
+	now := time.Now()
+	oneOnOne := models.OneOnOne{HostID: coach.ID, MemberID: member.ID, CadenceDays: 7, StartsAt: now.Add(-7 * 24 * time.Hour), Active: true}
+	testDB.Create(&oneOnOne)
+	previous := models.OneOnOneMeeting{OneOnOneID: oneOnOne.ID, ScheduledAt: oneOnOne.StartsAt}
+	next := models.OneOnOneMeeting{OneOnOneID: oneOnOne.ID, ScheduledAt: now}
+	testDB.Create(&previous)
+	testDB.Create(&next)
+
+	testDB.Create(&models.Feedback{Content: "Before the previous meeting", TargetType: "member", TargetID: member.ID, CreatedAt: now.Add(-10 * 24 * time.Hour)})
+	testDB.Create(&models.Feedback{Content: "Great demo", TargetType: "member", TargetID: member.ID, CreatedAt: now.Add(-24 * time.Hour)})
+	testDB.Create(&models.Feedback{Content: "About someone else", TargetType: "member", TargetID: coach.ID, CreatedAt: now.Add(-24 * time.Hour)})
+	// Neither the notes of the coach nor what only managers read reach the shared agenda
+	testDB.Create(&models.Feedback{Content: "Private note", TargetType: "member", TargetID: member.ID, GiverID: &coach.ID, Visibility: models.VisibilityPrivate, CreatedAt: now.Add(-24 * time.Hour)})
+	testDB.Create(&models.Feedback{Content: "For managers", TargetType: "member", TargetID: member.ID, GiverID: &coach.ID, Visibility: models.VisibilityManager, CreatedAt: now.Add(-24 * time.Hour)})
+	retractedAt := now
+	testDB.Create(&models.Feedback{TargetType: "member", TargetID: member.ID, RetractedAt: &retractedAt, CreatedAt: now.Add(-24 * time.Hour)})
+
+	path := fmt.Sprintf("/oneonones/%d/meetings/%d/agenda/feedback", oneOnOne.ID, next.ID)
+	var items []models.AgendaItem
+	w := serve("POST", path, "", coach.ID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &items))
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, "Great demo", items[0].Content)
+		assert.NotNil(t, items[0].FeedbackID)
+	}
+
+	// Pulling again does not duplicate the agenda
+	w = serve("POST", path, "", coach.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &items))
+	assert.Len(t, items, 0)
+
+	// The agenda shows the feedback as it is now, to whoever may read it
+	var stored models.AgendaItem
+	testDB.First(&stored)
+	assert.Empty(t, stored.Content)
+	testDB.Model(&models.Feedback{}).Where("id = ?", *stored.FeedbackID).Update("content", "Great demo, edited")
+	agenda := func(memberID uint64) []models.AgendaItem {
+		var meeting models.OneOnOneMeeting
+		w := serve("GET", fmt.Sprintf("/oneonones/%d/meetings/%d", oneOnOne.ID, next.ID), "", memberID)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &meeting))
+		return meeting.AgendaItems
+	}
+	if shown := agenda(member.ID); assert.Len(t, shown, 1) {
+		assert.Equal(t, "Great demo, edited", shown[0].Content)
+	}
+	itemPath := fmt.Sprintf("/oneonones/%d/meetings/%d/agenda/%d", oneOnOne.ID, next.ID, stored.ID)
+	assert.Equal(t, http.StatusBadRequest, serve("PUT", itemPath, `{"content": "Reworded"}`, coach.ID).Code)
+
+	testDB.Model(&models.Feedback{}).Where("id = ?", *stored.FeedbackID).Update("visibility", models.VisibilityManager)
+	assert.Empty(t, agenda(member.ID))
+	assert.Equal(t, http.StatusNotFound, serve("PUT", itemPath, `{"discussed": true}`, member.ID).Code)
+	assert.Len(t, agenda(coach.ID), 1)
+	testDB.Model(&models.Feedback{}).Where("id = ?", *stored.FeedbackID).Update("retracted_at", time.Now())
+	assert.Empty(t, agenda(coach.ID))
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// teamNode is a team of the org chart with the teams below it
+type teamNode struct {
+	models.Team
+	Children []*teamNode
+}
+
+// buildOrgChart arranges teams into trees. The teams whose parent is not among
+// them, such as the children of a deleted team, are roots.
+func buildOrgChart(teams []models.Team) []*teamNode {
+	nodes := make(map[uint64]*teamNode, len(teams))
+	for _, team := range teams {
+		nodes[team.ID] = &teamNode{Team: team, Children: []*teamNode{}}
+	}
+	roots := []*teamNode{}
+	// teams are ordered by name, and so are the children
+	for _, team := range teams {
+		node := nodes[team.ID]
+		if team.ParentID != nil {
+			if parent, ok := nodes[*team.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// respondParentError answers 400 and returns true when a team could not be
+// placed under its parent
+func respondParentError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, repository.ErrParentNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parent team not found"})
+	case errors.Is(err, repository.ErrCycle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A team cannot be placed under itself or a team below it"})
+	default:
+		return false
+	}
+	return true
+}
+
+// GetOrgChart returns every team as a forest of top-level teams with the teams
+// below them, each with its Members
+func (s *Server) GetOrgChart(c *gin.Context) {
+	teams, err := s.teams.ListSubtree(nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve teams: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, buildOrgChart(teams))
+}
+
+// GetTeamSubtree returns a team with the teams below it as a tree
+func (s *Server) GetTeamSubtree(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	teams, err := s.teams.ListSubtree(&id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve teams: " + err.Error()})
+		return
+	}
+	for _, root := range buildOrgChart(teams) {
+		if root.ID == id {
+			c.JSON(http.StatusOK, root)
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+}
+
+// GetTeamAncestors lists the teams above a team, its parent first
+func (s *Server) GetTeamAncestors(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.teams.Get(id); err != nil {
+		respondLookupError(c, err, "Team not found", "")
+		return
+	}
+	ancestors, err := s.teams.ListAncestors(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve teams: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ancestors)
+}
+
+// rollupQuery returns the team, and with rollup=true the teams below it too,
+// for the team-level feedback and analytics. It answers and returns false when
+// the parameter is invalid or the teams cannot be loaded.
+func (s *Server) rollupQuery(c *gin.Context, teamID uint64) ([]uint64, bool) {
+	rollup, err := optionalBoolQuery(c, "rollup")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if rollup == nil || !*rollup {
+		return []uint64{teamID}, true
+	}
+
+	teams, err := s.teams.ListSubtree(&teamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve teams: " + err.Error()})
+		return nil, false
+	}
+	ids := []uint64{teamID}
+	for _, team := range teams {
+		if team.ID != teamID {
+			ids = append(ids, team.ID)
+		}
+	}
+	return ids, true
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamHierarchy(t *testing.T) {
+	setupTestDatabase()
+
+	w := serve("POST", "/teams/", `{"name": "Tribe"}`, testCallerID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var tribe models.Team
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &tribe))
+	w = serve("POST", "/teams/", fmt.Sprintf(`{"name": "Squad", "parentid": %d}`, tribe.ID), testCallerID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var squad models.Team
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &squad))
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/teams/", `{"name": "Lost", "parentid": 99999}`, testCallerID).Code)
+	chapter := models.Team{Name: "Chapter"}
+	testDB.Create(&chapter)
+
+	// Only admins change the hierarchy, even for the leads of a team
+	member := models.TeamMember{Name: "Member", Email: "member@example.com"}
+	testDB.Create(&member)
+	testDB.Create(&models.MemberRole{MemberID: member.ID, Role: models.RoleTeamLead, TeamID: &chapter.ID})
+	assert.Equal(t, http.StatusForbidden, serve("PUT", fmt.Sprintf("/teams/%d", chapter.ID), fmt.Sprintf(`{"parentid": %d}`, tribe.ID), member.ID).Code)
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/teams/", fmt.Sprintf(`{"name": "Rogue", "parentid": %d}`, tribe.ID), member.ID).Code)
+	assert.Equal(t, http.StatusOK, serve("PUT", fmt.Sprintf("/teams/%d", chapter.ID), `{"logourl": "chapter.png"}`, member.ID).Code)
+
+	// A team cannot move below itself
+	assert.Equal(t, http.StatusBadRequest, serve("PUT", fmt.Sprintf("/teams/%d", tribe.ID), fmt.Sprintf(`{"parentid": %d}`, squad.ID), testCallerID).Code)
+
+	var chart []teamNode
+	getList(t, "/teams/orgchart", &chart)
+	if assert.Len(t, chart, 2) {
+		assert.Equal(t, "Chapter", chart[0].Name)
+		assert.Empty(t, chart[0].Children)
+		assert.Equal(t, "Tribe", chart[1].Name)
+		if assert.Len(t, chart[1].Children, 1) {
+			assert.Equal(t, squad.ID, chart[1].Children[0].ID)
+		}
+	}
+
+	var subtree teamNode
+	getList(t, fmt.Sprintf("/teams/%d/subtree", tribe.ID), &subtree)
+	assert.Equal(t, tribe.ID, subtree.ID)
+	assert.Len(t, subtree.Children, 1)
+	assert.Equal(t, http.StatusNotFound, serve("GET", "/teams/99999/subtree", "", testCallerID).Code)
+
+	var ancestors []models.Team
+	getList(t, fmt.Sprintf("/teams/%d/ancestors", squad.ID), &ancestors)
+	if assert.Len(t, ancestors, 1) {
+		assert.Equal(t, "Tribe", ancestors[0].Name)
+	}
+	assert.Equal(t, http.StatusNotFound, serve("GET", "/teams/99999/ancestors", "", testCallerID).Code)
+
+	// Team feedback and tags roll up over the teams below with rollup=true
+	giver := models.TeamMember{Name: "Giver", Email: "giver@example.com"}
+	testDB.Create(&giver)
+	for _, payload := range []string{
+		fmt.Sprintf(`{"content": "Tribe sync", "targetid": %d, "targettype": "team", "tags": [{"name": "sync"}]}`, tribe.ID),
+		fmt.Sprintf(`{"content": "Squad demo", "targetid": %d, "targettype": "team", "tags": [{"name": "demo"}]}`, squad.ID),
+	} {
+		assert.Equal(t, http.StatusCreated, serve("POST", "/feedback/", payload, giver.ID).Code)
+	}
+	var feedbacks []models.Feedback
+	getList(t, fmt.Sprintf("/feedback/?team_id=%d", tribe.ID), &feedbacks)
+	assert.Len(t, feedbacks, 1)
+	getList(t, fmt.Sprintf("/feedback/?team_id=%d&rollup=true", tribe.ID), &feedbacks)
+	assert.Len(t, feedbacks, 2)
+	assert.Equal(t, http.StatusBadRequest, serve("GET", fmt.Sprintf("/feedback/?team_id=%d&rollup=maybe", tribe.ID), "", testCallerID).Code)
+
+	var counts []repository.TagCount
+	getList(t, fmt.Sprintf("/teams/%d/tags?rollup=true", tribe.ID), &counts)
+	assert.ElementsMatch(t, []repository.TagCount{{Tag: "demo", Count: 1}, {Tag: "sync", Count: 1}}, counts)
+
+	// Moving the squad to the top level
+	w = serve("PUT", fmt.Sprintf("/teams/%d", squad.ID), `{"parentid": 0}`, testCallerID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &squad))
+	assert.Nil(t, squad.ParentID)
+	getList(t, "/teams/orgchart", &chart)
+	assert.Len(t, chart, 3)
+}
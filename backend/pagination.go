@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 500
+)
+
+// parseListParams reads page, page_size and sort from the query string.
+// sort takes one of the sortable column names, optionally prefixed with '-'
+// for descending order.
+func parseListParams(c *gin.Context, sortable ...string) (repository.ListOptions, error) {
+	opts := repository.ListOptions{Page: 1, PageSize: defaultPageSize}
+
+	if page := c.Query("page"); page != "" {
+		value, err := strconv.Atoi(page)
+		if err != nil || value < 1 {
+			return opts, errors.New("page must be a positive integer")
+		}
+		opts.Page = value
+	}
+
+	if pageSize := c.Query("page_size"); pageSize != "" {
+		value, err := strconv.Atoi(pageSize)
+		if err != nil || value < 1 || value > maxPageSize {
+			return opts, fmt.Errorf("page_size must be between 1 and %d", maxPageSize)
+		}
+		opts.PageSize = value
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		column, desc := strings.CutPrefix(sort, "-")
+		if !slices.Contains(sortable, column) {
+			return opts, fmt.Errorf("sort must be one of %s (prefix with '-' for descending)", strings.Join(sortable, ", "))
+		}
+		opts.SortBy = column
+		opts.Desc = desc
+	}
+
+	return opts, nil
+}
+
+// optionalIDQuery reads an optional numeric query parameter
+func optionalIDQuery(c *gin.Context, name string) (*uint64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a numeric ID", name)
+	}
+	return &id, nil
+}
+
+// optionalBoolQuery reads an optional true/false query parameter
+func optionalBoolQuery(c *gin.Context, name string) (*bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be true or false", name)
+	}
+	return &b, nil
+}
+
+// setPageHeaders exposes the paging metadata of a list response
+func setPageHeaders(c *gin.Context, opts repository.ListOptions, total int64) {
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.Header("X-Page", strconv.Itoa(opts.Page))
+	c.Header("X-Page-Size", strconv.Itoa(opts.PageSize))
+}
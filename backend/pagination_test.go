@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coaching-app/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getList(t *testing.T, path string, out any) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", path, nil)
+	authorize(req)
+	w := httptest.NewRecorder()
+	GlobalTestRouter.ServeHTTP(w, req)
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), out))
+	}
+	return w
+}
+
+func TestGetTeamMembersPaginationAndSorting(t *testing.T) {
+	setupTestDatabase()
+
+	for _, name := range []string{"Carol", "Alice", "Eve", "Bob", "Dave"} {
+		testDB.Create(&models.TeamMember{Name: name, Email: name + "@example.com"})
+	}
+
+	var members []models.TeamMember
+	w := getList(t, "/members/?page=2&page_size=2&sort=name", &members)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "5", w.Header().Get("X-Total-Count"))
+	assert.Equal(t, "2", w.Header().Get("X-Page"))
+	assert.Equal(t, "2", w.Header().Get("X-Page-Size"))
+	if assert.Len(t, members, 2) {
+		assert.Equal(t, "Carol", members[0].Name)
+		assert.Equal(t, "Dave", members[1].Name)
+	}
+
+	members = nil
+	getList(t, "/members/?page=3&page_size=2&sort=-name", &members)
+	if assert.Len(t, members, 1) {
+		assert.Equal(t, "Alice", members[0].Name)
+	}
+
+	for _, query := range []string{"page=0", "page_size=100000", "sort=password"} {
+		w = getList(t, "/members/?"+query, &members)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestGetTeamMembersFilters(t *testing.T) {
+	setupTestDatabase()
+
+	alice := models.TeamMember{Name: "Alice", Email: "alice@acme.io"}
+	alfred := models.TeamMember{Name: "Alfred", Email: "alfred@other.io"}
+	bob := models.TeamMember{Name: "Bob", Email: "bob@acme.io"}
+	percent := models.TeamMember{Name: "100% Sure", Email: "sure@acme_io.com"}
+	testDB.Create(&alice)
+	testDB.Create(&alfred)
+	testDB.Create(&bob)
+	testDB.Create(&percent)
+	testDB.Create(&models.Team{Name: "Acme", Members: []models.TeamMember{alice, bob}})
+
+	names := func(query string) []string {
+		var members []models.TeamMember
+		w := getList(t, "/members/?"+query, &members)
+		assert.Equal(t, http.StatusOK, w.Code)
+		result := []string{}
+		for _, m := range members {
+			result = append(result, m.Name)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"Alice", "Alfred"}, names("name=Al"))
+	assert.Equal(t, []string{"Alice", "Bob"}, names("email_domain=acme.io"))
+	assert.Equal(t, []string{"Alice"}, names("name=A&email_domain=acme.io"))
+	assert.Equal(t, []string{"100% Sure"}, names("name=100%25"))
+	assert.Empty(t, names("email_domain=acme_io"))
+
+	var team models.Team
+	testDB.Where("name = ?", "Acme").First(&team)
+	assert.Equal(t, []string{"Alice", "Bob"}, names(fmt.Sprintf("team_id=%d", team.ID)))
+}
+
+func TestGetTeamsPaginationAndFilters(t *testing.T) {
+	setupTestDatabase()
+
+	alice := models.TeamMember{Name: "Alice", Email: "alice@example.com"}
+	testDB.Create(&alice)
+	testDB.Create(&models.Team{Name: "Platform"})
+	testDB.Create(&models.Team{Name: "Payments", Members: []models.TeamMember{alice}})
+	testDB.Create(&models.Team{Name: "Mobile", Members: []models.TeamMember{alice}})
+
+	var teams []models.Team
+	w := getList(t, "/teams/?name=P&sort=name&page_size=1", &teams)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-Total-Count"))
+	if assert.Len(t, teams, 1) {
+		assert.Equal(t, "Payments", teams[0].Name)
+		assert.Len(t, teams[0].Members, 1)
+	}
+
+	teams = nil
+	getList(t, fmt.Sprintf("/teams/?member_id=%d&sort=-name", alice.ID), &teams)
+	if assert.Len(t, teams, 2) {
+		assert.Equal(t, "Payments", teams[0].Name)
+		assert.Equal(t, "Mobile", teams[1].Name)
+	}
+}
+
+func TestGetFeedbacksPagination(t *testing.T) {
+	setupTestDatabase()
+
+	target := models.TeamMember{Name: "Target", Email: "target@example.com"}
+	testDB.Create(&target)
+	for i := 1; i <= 7; i++ {
+		testDB.Create(&models.Feedback{Content: fmt.Sprintf("Feedback %d", i), TargetID: target.ID, TargetType: "member"})
+	}
+
+	var feedbacks []models.Feedback
+	w := getList(t, "/feedback/?page=2&page_size=3&sort=-created_at", &feedbacks)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "7", w.Header().Get("X-Total-Count"))
+	if assert.Len(t, feedbacks, 3) {
+		assert.Equal(t, "Feedback 4", feedbacks[0].Content)
+	}
+
+	w = getList(t, "/feedback/?sort=name", &feedbacks)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
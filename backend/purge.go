@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"coaching-app/repository"
+)
+
+// defaultSoftDeleteRetention is how long soft-deleted rows are kept when
+// SOFT_DELETE_RETENTION is not set
+const defaultSoftDeleteRetention = 30 * 24 * time.Hour
+
+// purgeInterval is how often the background purge runs
+const purgeInterval = time.Hour
+
+// purgeCounts is the number of rows removed by a purge
+type purgeCounts struct {
+	Feedback, Members, Teams int64
+}
+
+// purgeDeleted permanently removes the feedback, members and teams soft-deleted
+// before the given time. Feedback goes first, so that the feedback deleted with
+// its member or team never outlives it.
+func purgeDeleted(repos repository.Repositories, before time.Time) (purgeCounts, error) {
+	var counts purgeCounts
+	var err error
+	if counts.Feedback, err = repos.Feedback.Purge(before); err != nil {
+		return counts, fmt.Errorf("purging feedback: %w", err)
+	}
+	if counts.Members, err = repos.Members.Purge(before); err != nil {
+		return counts, fmt.Errorf("purging members: %w", err)
+	}
+	if counts.Teams, err = repos.Teams.Purge(before); err != nil {
+		return counts, fmt.Errorf("purging teams: %w", err)
+	}
+	return counts, nil
+}
+
+// runPurgeCommand implements "server purge": it purges the rows deleted more
+// than retention ago once and reports how many there were
+func runPurgeCommand(repos repository.Repositories, retention time.Duration, out io.Writer) error {
+	counts, err := purgeDeleted(repos, time.Now().Add(-retention))
+	fmt.Fprintf(out, "Purged %d feedback entries, %d members and %d teams\n", counts.Feedback, counts.Members, counts.Teams)
+	return err
+}
+
+// startPurgeJob purges the rows deleted more than retention ago every
+// purgeInterval until the process exits
+func startPurgeJob(repos repository.Repositories, retention time.Duration) {
+	go func() {
+		for range time.Tick(purgeInterval) {
+			counts, err := purgeDeleted(repos, time.Now().Add(-retention))
+			if err != nil {
+				log.Printf("Failed to purge deleted rows: %v", err)
+				continue
+			}
+			if counts != (purgeCounts{}) {
+				log.Printf("Purged %d feedback entries, %d members and %d teams", counts.Feedback, counts.Members, counts.Teams)
+			}
+		}
+	}()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoreDeletedTargets(t *testing.T) {
+	setupTestDatabase()
+
+	member := models.TeamMember{Name: "Member", Email: "member@example.com"}
+	testDB.Create(&member)
+	team := models.Team{Name: "Team", Members: []models.TeamMember{member}}
+	testDB.Create(&team)
+	feedback := models.Feedback{Content: "Team", TargetType: "team", TargetID: team.ID}
+	testDB.Create(&feedback)
+
+	assert.Equal(t, http.StatusNoContent, serve("DELETE", fmt.Sprintf("/teams/%d?on_feedback=cascade", team.ID), "", testCallerID).Code)
+	assert.Equal(t, http.StatusNotFound, serve("GET", fmt.Sprintf("/teams/%d", team.ID), "", testCallerID).Code)
+	var teams []models.Team
+	getList(t, "/teams/", &teams)
+	assert.Empty(t, teams)
+	getList(t, "/teams/?include_deleted=true", &teams)
+	assert.Len(t, teams, 1)
+	assert.Equal(t, http.StatusForbidden, serve("GET", "/teams/?include_deleted=true", "", member.ID).Code)
+	assert.Equal(t, http.StatusForbidden, serve("GET", "/feedback/?include_deleted=true", "", member.ID).Code)
+	var feedbacks []models.Feedback
+	getList(t, "/feedback/?include_deleted=true", &feedbacks)
+	assert.Len(t, feedbacks, 1)
+
+	path := fmt.Sprintf("/feedback/%d/restore", feedback.ID)
+	assert.Equal(t, http.StatusConflict, serve("POST", path, "", testCallerID).Code)
+	assert.Equal(t, http.StatusForbidden, serve("POST", fmt.Sprintf("/teams/%d/restore", team.ID), "", member.ID).Code)
+	assert.Equal(t, http.StatusNotFound, serve("POST", "/teams/99999/restore", "", testCallerID).Code)
+	w := serve("POST", fmt.Sprintf("/teams/%d/restore", team.ID), "", testCallerID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Name":"Member"`, "the memberships come back with the team")
+
+	// The feedback deleted with the team comes back with it
+	getList(t, "/feedback/", &feedbacks)
+	assert.Len(t, feedbacks, 1)
+	assert.Equal(t, http.StatusNoContent, serve("POST", path, "", testCallerID).Code)
+
+	assert.Equal(t, http.StatusNoContent, serve("DELETE", fmt.Sprintf("/members/%d", member.ID), "", testCallerID).Code)
+	assert.Equal(t, http.StatusOK, serve("POST", fmt.Sprintf("/members/%d/restore", member.ID), "", testCallerID).Code)
+	assert.Equal(t, http.StatusOK, serve("GET", fmt.Sprintf("/members/%d", member.ID), "", testCallerID).Code)
+}
+
+func TestPurgeCommand(t *testing.T) {
+	setupTestDatabase()
+
+	members := []models.TeamMember{{Name: "Old", Email: "old@example.com"}, {Name: "Recent", Email: "recent@example.com"}}
+	testDB.Create(&members)
+	testDB.Create(&models.Feedback{Content: "Old", TargetType: "member", TargetID: members[0].ID})
+	repos := repository.NewGormRepositories(testDB)
+	for _, member := range members {
+		assert.NoError(t, repos.Members.Delete(member.ID, repository.TargetDeletion{Policy: repository.FeedbackCascade}))
+	}
+	longAgo := time.Now().Add(-48 * time.Hour)
+	testDB.Unscoped().Model(&models.TeamMember{}).Where("id = ?", members[0].ID).Update("deleted_at", longAgo)
+	testDB.Unscoped().Model(&models.Feedback{}).Where("target_id = ?", members[0].ID).Update("deleted_at", longAgo)
+
+	var out strings.Builder
+	assert.NoError(t, runPurgeCommand(repos, 24*time.Hour, &out))
+	assert.Equal(t, "Purged 1 feedback entries, 1 members and 0 teams\n", out.String())
+	var remaining int64
+	testDB.Unscoped().Model(&models.TeamMember{}).Count(&remaining)
+	assert.Equal(t, int64(1), remaining)
+}
+
+func TestUpdateCannotDelete(t *testing.T) {
+	setupTestDatabase()
+
+	member := models.TeamMember{Name: "Member", Email: "member@example.com"}
+	testDB.Create(&member)
+	team := models.Team{Name: "Team"}
+	testDB.Create(&team)
+
+	deletedAt := `{"name": "Renamed", "DeletedAt": "2020-01-01T00:00:00Z"}`
+	assert.Equal(t, http.StatusOK, serve("PUT", fmt.Sprintf("/members/%d", member.ID), deletedAt, member.ID).Code)
+	assert.Equal(t, http.StatusOK, serve("GET", fmt.Sprintf("/members/%d", member.ID), "", member.ID).Code)
+	assert.Equal(t, http.StatusOK, serve("PUT", fmt.Sprintf("/teams/%d", team.ID), deletedAt, testCallerID).Code)
+	assert.Equal(t, http.StatusOK, serve("GET", fmt.Sprintf("/teams/%d", team.ID), "", member.ID).Code)
+}
+
+func TestCreateIgnoresServerFields(t *testing.T) {
+	setupTestDatabase()
+
+	existing := models.TeamMember{Name: "Existing", Email: "existing@example.com"}
+	testDB.Create(&existing)
+
+	payload := fmt.Sprintf(`{"ID": %d, "name": "Newcomer", "email": "newcomer@example.com", "DeletedAt": "2020-01-01T00:00:00Z"}`, existing.ID)
+	var member models.TeamMember
+	w := serve("POST", "/members/", payload, testCallerID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &member))
+	assert.NotEqual(t, existing.ID, member.ID)
+	assert.Equal(t, http.StatusOK, serve("GET", fmt.Sprintf("/members/%d", member.ID), "", testCallerID).Code)
+
+	payload = fmt.Sprintf(`{"name": "Team", "DeletedAt": "2020-01-01T00:00:00Z", "members": [{"ID": %d}]}`, existing.ID)
+	var team models.Team
+	w = serve("POST", "/teams/", payload, existing.ID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &team))
+	assert.Equal(t, http.StatusOK, serve("GET", fmt.Sprintf("/teams/%d", team.ID), "", testCallerID).Code)
+	var roster []models.TeamAssignment
+	getList(t, fmt.Sprintf("/teams/%d/roster", team.ID), &roster)
+	assert.Empty(t, roster, "members join a team by assignment only")
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"time"
+
+	"coaching-app/models"
+
+	"gorm.io/gorm"
+)
+
+// ScoreFilter selects the competency scores to aggregate. TeamIDs matches the
+// feedback addressed to the teams and to their current members.
+type ScoreFilter struct {
+	MemberID      *uint64
+	TeamIDs       []uint64
+	CreatedFrom   *time.Time // inclusive
+	CreatedBefore *time.Time // exclusive
+	Reader        *FeedbackReader
+}
+
+// ScoreSample is one competency score with the creation time and the giver of
+// its feedback. Anonymous givers only have a GiverHash.
+type ScoreSample struct {
+	CompetencyID uint64
+	Score        int
+	CreatedAt    time.Time
+	GiverID      *uint64
+	GiverHash    string
+}
+
+type CompetencyRepository interface {
+	Create(competency *models.Competency) error
+	Get(id uint64) (*models.Competency, error)
+	// List returns the competencies ordered by name
+	List() ([]models.Competency, error)
+	// Update applies the given column changes and returns the updated competency
+	Update(id uint64, changes map[string]any) (*models.Competency, error)
+
+	// ListScores returns the scores matched by the filter, oldest first
+	ListScores(filter ScoreFilter) ([]ScoreSample, error)
+}
+
+type gormCompetencyRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormCompetencyRepository) Create(competency *models.Competency) error {
+	return r.db.Create(competency).Error
+}
+
+func (r *gormCompetencyRepository) Get(id uint64) (*models.Competency, error) {
+	var competency models.Competency
+	if err := r.db.First(&competency, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &competency, nil
+}
+
+func (r *gormCompetencyRepository) List() ([]models.Competency, error) {
+	competencies := []models.Competency{}
+	err := r.db.Order("name").Find(&competencies).Error
+	return competencies, err
+}
+
+func (r *gormCompetencyRepository) Update(id uint64, changes map[string]any) (*models.Competency, error) {
+	competency, err := r.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(competency).Updates(changes).Error; err != nil {
+		return nil, err
+	}
+	return competency, nil
+}
+
+func (r *gormCompetencyRepository) ListScores(filter ScoreFilter) ([]ScoreSample, error) {
+	query := r.db.Table("feedback_scores").
+		Select("feedback_scores.competency_id, feedback_scores.score, feedbacks.created_at, feedbacks.giver_id, feedbacks.giver_hash").
+		Joins("JOIN feedbacks ON feedbacks.id = feedback_scores.feedback_id AND feedbacks.deleted_at IS NULL")
+
+	if filter.MemberID != nil {
+		query = query.Where("feedbacks.target_type = ? AND feedbacks.target_id = ?", "member", *filter.MemberID)
+	}
+	if len(filter.TeamIDs) > 0 {
+		members := currentAssignments(r.db).Select("team_member_id").Where("team_id IN ?", filter.TeamIDs)
+		query = query.Where(r.db.Where("feedbacks.target_type = ? AND feedbacks.target_id IN ?", "team", filter.TeamIDs).
+			Or("feedbacks.target_type = ? AND feedbacks.target_id IN (?)", "member", members))
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("feedbacks.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("feedbacks.created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.Reader != nil {
+		query = query.Where(readerScope(r.db, *filter.Reader))
+	}
+
+	samples := []ScoreSample{}
+	err := query.Order("feedbacks.created_at, feedback_scores.id").Scan(&samples).Error
+	return samples, err
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"coaching-app/models"
+
+	"gorm.io/gorm"
+)
+
+type GoalFilter struct {
+	OwnerType string
+	OwnerID   *uint64
+	Status    string
+}
+
+type GoalRepository interface {
+	// Create stores the goal together with its KeyResults
+	Create(goal *models.Goal) error
+	// Get returns the goal with its KeyResults
+	Get(id uint64) (*models.Goal, error)
+	// List returns one page of goals with their KeyResults
+	List(filter GoalFilter, opts ListOptions) ([]models.Goal, int64, error)
+	// Update applies the given column changes and returns the updated goal
+	Update(id uint64, changes map[string]any) (*models.Goal, error)
+	// Delete removes the goal with its key results and check-ins, and unlinks its feedback
+	Delete(id uint64) error
+
+	AddKeyResult(keyResult *models.KeyResult) error
+	UpdateKeyResult(goalID, keyResultID uint64, changes map[string]any) (*models.KeyResult, error)
+	DeleteKeyResult(goalID, keyResultID uint64) error
+
+	// AddCheckIn stores the check-in and applies its value and status to the
+	// key result and the goal in one transaction
+	AddCheckIn(checkIn *models.GoalCheckIn) error
+	ListCheckIns(goalID uint64) ([]models.GoalCheckIn, error)
+}
+
+type gormGoalRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormGoalRepository) Create(goal *models.Goal) error {
+	return r.db.Create(goal).Error
+}
+
+func (r *gormGoalRepository) Get(id uint64) (*models.Goal, error) {
+	var goal models.Goal
+	if err := r.db.Preload("KeyResults").First(&goal, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &goal, nil
+}
+
+func (r *gormGoalRepository) List(filter GoalFilter, opts ListOptions) ([]models.Goal, int64, error) {
+	query := r.db.Model(&models.Goal{})
+	if filter.OwnerType != "" {
+		query = query.Where("owner_type = ?", filter.OwnerType)
+	}
+	if filter.OwnerID != nil {
+		query = query.Where("owner_id = ?", *filter.OwnerID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var goals []models.Goal
+	total, err := findPage(query.Preload("KeyResults"), opts, &goals)
+	return goals, total, err
+}
+
+func (r *gormGoalRepository) Update(id uint64, changes map[string]any) (*models.Goal, error) {
+	result := r.db.Model(&models.Goal{ID: id}).Updates(changes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return r.Get(id)
+}
+
+func (r *gormGoalRepository) Delete(id uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Feedback{}).Where("goal_id = ?", id).Update("goal_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("goal_id = ?", id).Delete(&models.GoalCheckIn{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("goal_id = ?", id).Delete(&models.KeyResult{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Goal{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *gormGoalRepository) AddKeyResult(keyResult *models.KeyResult) error {
+	return r.db.Create(keyResult).Error
+}
+
+func (r *gormGoalRepository) getKeyResult(db *gorm.DB, goalID, keyResultID uint64) (*models.KeyResult, error) {
+	var keyResult models.KeyResult
+	if err := db.Where("goal_id = ?", goalID).First(&keyResult, keyResultID).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &keyResult, nil
+}
+
+func (r *gormGoalRepository) UpdateKeyResult(goalID, keyResultID uint64, changes map[string]any) (*models.KeyResult, error) {
+	keyResult, err := r.getKeyResult(r.db, goalID, keyResultID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(keyResult).Updates(changes).Error; err != nil {
+		return nil, err
+	}
+	return keyResult, nil
+}
+
+func (r *gormGoalRepository) DeleteKeyResult(goalID, keyResultID uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.GoalCheckIn{}).Where("key_result_id = ?", keyResultID).Update("key_result_id", nil).Error; err != nil {
+			return err
+		}
+		result := tx.Where("goal_id = ?", goalID).Delete(&models.KeyResult{}, keyResultID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *gormGoalRepository) AddCheckIn(checkIn *models.GoalCheckIn) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if checkIn.KeyResultID != nil && checkIn.Value != nil {
+			keyResult, err := r.getKeyResult(tx, checkIn.GoalID, *checkIn.KeyResultID)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(keyResult).Update("current_value", *checkIn.Value).Error; err != nil {
+				return err
+			}
+		}
+		if checkIn.Status != "" {
+			if err := tx.Model(&models.Goal{ID: checkIn.GoalID}).Update("status", checkIn.Status).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(checkIn).Error
+	})
+}
+
+func (r *gormGoalRepository) ListCheckIns(goalID uint64) ([]models.GoalCheckIn, error) {
+	checkIns := []models.GoalCheckIn{}
+	err := r.db.Where("goal_id = ?", goalID).Order("created_at, id").Find(&checkIns).Error
+	return checkIns, err
+}
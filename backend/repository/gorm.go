@@ -0,0 +1,1131 @@
+package repository
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"coaching-app/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NewGormRepositories returns repositories backed by a MySQL or SQLite database
+func NewGormRepositories(db *gorm.DB) Repositories {
+	feedback := &gormFeedbackRepository{db: db}
+	return Repositories{
+		Members:      &gormMemberRepository{db: db},
+		Teams:        &gormTeamRepository{db: db},
+		Feedback:     feedback,
+		Reviews:      &gormReviewRepository{db: db},
+		Competencies: &gormCompetencyRepository{db: db},
+		Goals:        &gormGoalRepository{db: db},
+		OneOnOnes:    &gormOneOnOneRepository{db: db},
+		Threads:      &gormThreadRepository{db: db},
+		Requests:     &gormFeedbackRequestRepository{db: db},
+		Templates:    &gormTemplateRepository{db: db},
+		Search:       newSearchRepository(db, feedback),
+		Integrity:    &gormIntegrityRepository{db: db},
+	}
+}
+
+// translateError maps GORM errors to the errors of this package
+func translateError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// currentAssignments selects the open assignment periods, that is the members
+// on each team now
+func currentAssignments(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.TeamAssignment{}).Where("ended_at IS NULL")
+}
+
+// findPage counts the rows matched by query and loads the requested page into out
+func findPage(query *gorm.DB, opts ListOptions, out any) (int64, error) {
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	order := []clause.OrderByColumn{}
+	if opts.SortBy != "" {
+		order = append(order, clause.OrderByColumn{Column: clause.Column{Name: opts.SortBy}, Desc: opts.Desc})
+	}
+	// Keep pages stable when the sort column has duplicates
+	order = append(order, clause.OrderByColumn{Column: clause.Column{Name: "id"}})
+
+	err := query.Order(clause.OrderBy{Columns: order}).
+		Limit(opts.PageSize).
+		Offset(opts.Offset()).
+		Find(out).Error
+	return total, err
+}
+
+// likeEscaper escapes the LIKE wildcards of user input; '!' is used as the
+// escape character because it means the same thing in MySQL and SQLite
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// whereLike adds a LIKE condition on column for the pattern built by format,
+// where %s is replaced by the escaped value
+func whereLike(query *gorm.DB, column, format, value string) *gorm.DB {
+	return query.Where(column+" LIKE ? ESCAPE '!'", fmt.Sprintf(format, likeEscaper.Replace(value)))
+}
+
+type gormMemberRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormMemberRepository) Create(member *models.TeamMember) error {
+	if member.ManagerID != nil && *member.ManagerID == 0 {
+		member.ManagerID = nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if member.ManagerID != nil {
+			if err := lockManager(tx, *member.ManagerID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(member).Error; err != nil {
+			return err
+		}
+		if member.ManagerID == nil {
+			return nil
+		}
+		return tx.Create(&models.ManagerPeriod{MemberID: member.ID, ManagerID: *member.ManagerID, StartedAt: member.CreatedAt}).Error
+	})
+}
+
+func (r *gormMemberRepository) Get(id uint64) (*models.TeamMember, error) {
+	var member models.TeamMember
+	if err := r.db.First(&member, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &member, nil
+}
+
+func (r *gormMemberRepository) GetByEmail(email string) (*models.TeamMember, error) {
+	var member models.TeamMember
+	if err := r.db.Where("email = ?", email).First(&member).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &member, nil
+}
+
+func (r *gormMemberRepository) List(filter MemberFilter, opts ListOptions) ([]models.TeamMember, int64, error) {
+	query := r.db.Model(&models.TeamMember{})
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.NamePrefix != "" {
+		query = whereLike(query, "name", "%s%%", filter.NamePrefix)
+	}
+	if filter.EmailDomain != "" {
+		query = whereLike(query, "email", "%%@%s", filter.EmailDomain)
+	}
+	if filter.TeamID != nil {
+		query = query.Where("id IN (?)", currentAssignments(r.db).Select("team_member_id").Where("team_id = ?", *filter.TeamID))
+	}
+
+	var members []models.TeamMember
+	total, err := findPage(query, opts, &members)
+	return members, total, err
+}
+
+func (r *gormMemberRepository) Update(id uint64, changes models.TeamMember) (*models.TeamMember, error) {
+	member, err := r.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	changes.ID = member.ID
+	// The manager is changed through SetManager only, which keeps its history,
+	// and members are deleted through Delete only
+	changes.ManagerID = nil
+	changes.DeletedAt = gorm.DeletedAt{}
+	if err := r.db.Model(member).Updates(changes).Error; err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (r *gormMemberRepository) Delete(id uint64, deletion TargetDeletion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := disposeFeedback(tx, "member", id, deletion, now); err != nil {
+			return err
+		}
+		var member models.TeamMember
+		if err := tx.First(&member, id).Error; err != nil {
+			return translateError(err)
+		}
+		// The reports of the member move up to their manager
+		var reports []models.TeamMember
+		if err := tx.Where("manager_id = ?", id).Find(&reports).Error; err != nil {
+			return err
+		}
+		for i := range reports {
+			if err := changeManager(tx, &reports[i], member.ManagerID, now); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.TeamMember{}).Where("id = ?", id).Update("deleted_at", now).Error
+	})
+}
+
+func (r *gormMemberRepository) Restore(id uint64) (*models.TeamMember, error) {
+	var member models.TeamMember
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().First(&member, id).Error; err != nil {
+			return translateError(err)
+		}
+		if !member.DeletedAt.Valid {
+			return nil
+		}
+		deletedAt := tx.Unscoped().Model(&models.TeamMember{}).Select("deleted_at").Where("id = ?", id)
+		if err := restoreFeedback(tx, "member", id, deletedAt); err != nil {
+			return err
+		}
+		member.DeletedAt = gorm.DeletedAt{}
+		return tx.Unscoped().Model(&models.TeamMember{}).Where("id = ?", id).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *gormMemberRepository) Purge(before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// SQLite does not enforce the ON DELETE CASCADE of the memberships
+		deleted := tx.Unscoped().Model(&models.TeamMember{}).Select("id").Where("deleted_at < ?", before)
+		if err := tx.Exec("DELETE FROM team_member_assignments WHERE team_member_id IN (?)", deleted).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM manager_periods WHERE member_id IN (?) OR manager_id IN (?)", deleted, deleted).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.TeamMember{}).Where("manager_id IN (?)", deleted).Update("manager_id", nil).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("deleted_at < ?", before).Delete(&models.TeamMember{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+func (r *gormMemberRepository) SetManager(memberID uint64, managerID *uint64, at time.Time) (*models.TeamMember, error) {
+	if managerID != nil && *managerID == 0 {
+		managerID = nil
+	}
+	var member models.TeamMember
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).First(&member, memberID).Error; err != nil {
+			return translateError(err)
+		}
+		if managerID != nil {
+			if err := checkManager(tx, memberID, *managerID); err != nil {
+				return err
+			}
+		}
+		return changeManager(tx, &member, managerID, at)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// lockManager locks a member about to become a manager until the end of the
+// transaction, or returns ErrManagerNotFound when there is none
+func lockManager(tx *gorm.DB, managerID uint64) error {
+	if err := lockTarget(tx, "member", managerID, clause.LockingStrengthShare); err != nil {
+		if errors.Is(err, ErrTargetNotFound) {
+			return ErrManagerNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// checkManager makes sure that a member can report to managerID: the manager
+// exists and the member is not above them. Like checkParent, it locks the
+// managers above managerID, deleted ones included.
+func checkManager(tx *gorm.DB, memberID, managerID uint64) error {
+	if err := lockManager(tx, managerID); err != nil {
+		return err
+	}
+	for current := &managerID; current != nil; {
+		if *current == memberID {
+			return ErrCycle
+		}
+		var manager models.TeamMember
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Select("id", "manager_id").First(&manager, *current).Error
+		if err != nil {
+			return translateError(err)
+		}
+		current = manager.ManagerID
+	}
+	return nil
+}
+
+// changeManager closes the open manager period of the member at the given time
+// and opens one with managerID, unless it is already their manager
+func changeManager(tx *gorm.DB, member *models.TeamMember, managerID *uint64, at time.Time) error {
+	if sameID(member.ManagerID, managerID) {
+		return nil
+	}
+	err := tx.Model(&models.ManagerPeriod{}).Where("member_id = ? AND ended_at IS NULL", member.ID).Update("ended_at", at).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(member).Update("manager_id", managerID).Error; err != nil {
+		return err
+	}
+	member.ManagerID = managerID
+	if managerID == nil {
+		return nil
+	}
+	return tx.Create(&models.ManagerPeriod{MemberID: member.ID, ManagerID: *managerID, StartedAt: at}).Error
+}
+
+func (r *gormMemberRepository) ListManagerPeriods(memberID uint64) ([]models.ManagerPeriod, error) {
+	periods := []models.ManagerPeriod{}
+	if err := r.db.Where("member_id = ?", memberID).Order("started_at, id").Find(&periods).Error; err != nil {
+		return nil, err
+	}
+	if len(periods) == 0 {
+		return periods, nil
+	}
+
+	// Former managers may have been deleted since
+	ids := make([]uint64, len(periods))
+	for i, period := range periods {
+		ids[i] = period.ManagerID
+	}
+	var managers []models.TeamMember
+	if err := r.db.Unscoped().Where("id IN ?", ids).Find(&managers).Error; err != nil {
+		return nil, err
+	}
+	for i := range periods {
+		for j := range managers {
+			if managers[j].ID == periods[i].ManagerID {
+				periods[i].Manager = &managers[j]
+			}
+		}
+	}
+	return periods, nil
+}
+
+func (r *gormMemberRepository) ListReports(managerID uint64, transitive bool) ([]models.TeamMember, error) {
+	query := r.db.Where("manager_id = ?", managerID)
+	if transitive {
+		reports := r.db.Raw(`WITH RECURSIVE reports(id) AS (
+			SELECT id FROM team_members WHERE manager_id = ? AND deleted_at IS NULL
+			UNION
+			SELECT team_members.id FROM team_members JOIN reports ON team_members.manager_id = reports.id WHERE team_members.deleted_at IS NULL
+		) SELECT id FROM reports`, managerID)
+		query = r.db.Where("id IN (?)", reports)
+	}
+	members := []models.TeamMember{}
+	err := query.Order("name, id").Find(&members).Error
+	return members, err
+}
+
+// lockTarget locks the row of the member or team feedback is about until the
+// end of the transaction, or returns ErrTargetNotFound when there is none.
+// Feedback is created under a shared lock and targets are deleted under an
+// exclusive one, so that no feedback is left about a deleted target.
+func lockTarget(tx *gorm.DB, targetType string, targetID uint64, strength string) error {
+	var model any = &models.TeamMember{}
+	if targetType == "team" {
+		model = &models.Team{}
+	}
+	var ids []uint64
+	err := tx.Model(model).Clauses(clause.Locking{Strength: strength}).Where("id = ?", targetID).Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return ErrTargetNotFound
+	}
+	return nil
+}
+
+// disposeFeedback applies the deletion policy to the feedback about a member or
+// team about to be deleted, after locking it. It returns ErrNotFound when the
+// member or team does not exist.
+func disposeFeedback(tx *gorm.DB, targetType string, targetID uint64, deletion TargetDeletion, now time.Time) error {
+	if err := lockTarget(tx, targetType, targetID, clause.LockingStrengthUpdate); err != nil {
+		if errors.Is(err, ErrTargetNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	feedback := tx.Model(&models.Feedback{}).Where("target_type = ? AND target_id = ?", targetType, targetID)
+	switch deletion.Policy {
+	case FeedbackCascade:
+		return feedback.Update("deleted_at", now).Error
+	case FeedbackReassign:
+		if deletion.ReassignTo == targetID {
+			return ErrTargetNotFound
+		}
+		if err := lockTarget(tx, targetType, deletion.ReassignTo, clause.LockingStrengthShare); err != nil {
+			return err
+		}
+		return feedback.Update("target_id", deletion.ReassignTo).Error
+	case FeedbackArchive:
+		return feedback.Where("archived_at IS NULL").Update("archived_at", now).Error
+	default:
+		return fmt.Errorf("unknown feedback deletion policy %q", deletion.Policy)
+	}
+}
+
+// restoreFeedback brings back the feedback about a member or team that was
+// deleted or archived when it was soft-deleted, that is at the time selected
+// by deletedAt
+func restoreFeedback(tx *gorm.DB, targetType string, targetID uint64, deletedAt *gorm.DB) error {
+	err := tx.Unscoped().Model(&models.Feedback{}).
+		Where("target_type = ? AND target_id = ? AND deleted_at = (?)", targetType, targetID, deletedAt).
+		Update("deleted_at", nil).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.Feedback{}).
+		Where("target_type = ? AND target_id = ? AND archived_at = (?)", targetType, targetID, deletedAt).
+		Update("archived_at", nil).Error
+}
+
+func (r *gormMemberRepository) GetAccount(memberID uint64) (*models.Account, error) {
+	var account models.Account
+	if err := r.db.Where("member_id = ?", memberID).First(&account).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &account, nil
+}
+
+func (r *gormMemberRepository) SaveAccount(account *models.Account) error {
+	return r.db.Save(account).Error
+}
+
+func (r *gormMemberRepository) CountAccounts() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Account{}).Count(&count).Error
+	return count, err
+}
+
+func (r *gormMemberRepository) ListRoles(memberID uint64) ([]models.MemberRole, error) {
+	var roles []models.MemberRole
+	err := r.db.Where("member_id = ?", memberID).Order("id").Find(&roles).Error
+	return roles, err
+}
+
+func (r *gormMemberRepository) GrantRole(role *models.MemberRole) error {
+	query := r.db.Where("member_id = ? AND role = ?", role.MemberID, role.Role)
+	if role.TeamID == nil {
+		query = query.Where("team_id IS NULL")
+	} else {
+		query = query.Where("team_id = ?", *role.TeamID)
+	}
+	return query.FirstOrCreate(role).Error
+}
+
+func (r *gormMemberRepository) RevokeRole(memberID, roleID uint64) error {
+	result := r.db.Where("id = ? AND member_id = ?", roleID, memberID).Delete(&models.MemberRole{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type gormTeamRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormTeamRepository) Create(team *models.Team) error {
+	if team.ParentID != nil && *team.ParentID == 0 {
+		team.ParentID = nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if team.ParentID != nil {
+			if err := checkParent(tx, 0, *team.ParentID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Omit("Members").Create(team).Error; err != nil {
+			return err
+		}
+		for _, member := range team.Members {
+			assignment := newAssignment(team.ID, member.ID, team.CreatedAt)
+			if err := tx.Create(&assignment).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// checkParent makes sure that a team can be placed under parentID: the parent
+// exists and teamID is not among its ancestors. The ancestors are locked until
+// the end of the transaction, so that concurrent moves cannot make a cycle.
+// Deleted ancestors count, since they may be restored.
+func checkParent(tx *gorm.DB, teamID, parentID uint64) error {
+	var ids []uint64
+	err := tx.Model(&models.Team{}).Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Where("id = ?", parentID).Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return ErrParentNotFound
+	}
+
+	for current := &parentID; current != nil; {
+		if *current == teamID {
+			return ErrCycle
+		}
+		var team models.Team
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Select("id", "parent_id").First(&team, *current).Error
+		if err != nil {
+			return translateError(err)
+		}
+		current = team.ParentID
+	}
+	return nil
+}
+
+// newAssignment is the default assignment period of a member joining a team
+func newAssignment(teamID, memberID uint64, at time.Time) models.TeamAssignment {
+	return models.TeamAssignment{TeamID: teamID, MemberID: memberID, Role: models.TeamRoleMember, Allocation: 100, StartedAt: at}
+}
+
+// loadMembers fills in the Members of the teams with the members on them now
+func loadMembers(db *gorm.DB, teams []models.Team) error {
+	ids := make([]uint64, len(teams))
+	for i, team := range teams {
+		ids[i] = team.ID
+		teams[i].Members = []models.TeamMember{}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var assignments []models.TeamAssignment
+	err := currentAssignments(db).Preload("Member").Where("team_id IN ?", ids).Order("team_member_id").Find(&assignments).Error
+	if err != nil {
+		return err
+	}
+	for _, assignment := range assignments {
+		// Soft-deleted members are not loaded
+		if assignment.Member == nil {
+			continue
+		}
+		i := slices.Index(ids, assignment.TeamID)
+		teams[i].Members = append(teams[i].Members, *assignment.Member)
+	}
+	return nil
+}
+
+func (r *gormTeamRepository) Get(id uint64) (*models.Team, error) {
+	var team models.Team
+	if err := r.db.First(&team, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	teams := []models.Team{team}
+	if err := loadMembers(r.db, teams); err != nil {
+		return nil, err
+	}
+	return &teams[0], nil
+}
+
+func (r *gormTeamRepository) List(filter TeamFilter, opts ListOptions) ([]models.Team, int64, error) {
+	query := r.db.Model(&models.Team{})
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.NamePrefix != "" {
+		query = whereLike(query, "name", "%s%%", filter.NamePrefix)
+	}
+	if filter.MemberID != nil {
+		query = query.Where("id IN (?)", currentAssignments(r.db).Select("team_id").Where("team_member_id = ?", *filter.MemberID))
+	}
+
+	var teams []models.Team
+	total, err := findPage(query, opts, &teams)
+	if err != nil {
+		return nil, 0, err
+	}
+	return teams, total, loadMembers(r.db, teams)
+}
+
+func (r *gormTeamRepository) Update(id uint64, changes models.Team) (*models.Team, error) {
+	var team models.Team
+	if err := r.db.First(&team, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	changes.ID = team.ID
+	// Members are managed through AddMember and RemoveMember only, and teams
+	// are deleted through Delete only
+	changes.Members = nil
+	changes.DeletedAt = gorm.DeletedAt{}
+	parentID := changes.ParentID
+	changes.ParentID = nil
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if parentID != nil {
+			var parent any
+			if *parentID != 0 {
+				if err := checkParent(tx, id, *parentID); err != nil {
+					return err
+				}
+				parent = *parentID
+			}
+			if err := tx.Model(&team).Update("parent_id", parent).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&team).Updates(changes).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (r *gormTeamRepository) Delete(id uint64, deletion TargetDeletion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := disposeFeedback(tx, "team", id, deletion, now); err != nil {
+			return err
+		}
+		return tx.Model(&models.Team{}).Where("id = ?", id).Update("deleted_at", now).Error
+	})
+}
+
+func (r *gormTeamRepository) Restore(id uint64) (*models.Team, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var team models.Team
+		if err := tx.Unscoped().First(&team, id).Error; err != nil {
+			return translateError(err)
+		}
+		if !team.DeletedAt.Valid {
+			return nil
+		}
+		deletedAt := tx.Unscoped().Model(&models.Team{}).Select("deleted_at").Where("id = ?", id)
+		if err := restoreFeedback(tx, "team", id, deletedAt); err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Team{}).Where("id = ?", id).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(id)
+}
+
+func (r *gormTeamRepository) Purge(before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		deleted := tx.Unscoped().Model(&models.Team{}).Select("id").Where("deleted_at < ?", before)
+		if err := tx.Exec("DELETE FROM team_member_assignments WHERE team_id IN (?)", deleted).Error; err != nil {
+			return err
+		}
+		// Like ON DELETE SET NULL, the teams below become top-level teams
+		if err := tx.Unscoped().Model(&models.Team{}).Where("parent_id IN (?)", deleted).Update("parent_id", nil).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("deleted_at < ?", before).Delete(&models.Team{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+func (r *gormTeamRepository) AddMember(assignment *models.TeamAssignment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return addMember(tx, assignment)
+	})
+}
+
+// addMember opens an assignment period within a transaction, see AddMember
+func addMember(tx *gorm.DB, assignment *models.TeamAssignment) error {
+	defaultAssignment(assignment)
+	// Locking the team keeps concurrent assignments from opening two periods
+	if err := lockTeam(tx, assignment.TeamID); err != nil {
+		return err
+	}
+	if err := lockTarget(tx, "member", assignment.MemberID, clause.LockingStrengthShare); err != nil {
+		if errors.Is(err, ErrTargetNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	var open models.TeamAssignment
+	err := currentAssignments(tx).Where("team_id = ? AND team_member_id = ?", assignment.TeamID, assignment.MemberID).First(&open).Error
+	switch {
+	case err == nil:
+		if open.Role == assignment.Role && open.Allocation == assignment.Allocation {
+			*assignment = open
+			return nil
+		}
+		if err := tx.Model(&open).Update("ended_at", assignment.StartedAt).Error; err != nil {
+			return err
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+	return tx.Create(assignment).Error
+}
+
+// lockTeam locks a team whose memberships are about to change, or returns
+// ErrNotFound when there is none
+func lockTeam(tx *gorm.DB, teamID uint64) error {
+	if err := lockTarget(tx, "team", teamID, clause.LockingStrengthUpdate); err != nil {
+		if errors.Is(err, ErrTargetNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// closeAssignment closes the open assignment period of the member on the team
+// at the given time, or returns ErrNotAssigned when there is none
+func closeAssignment(tx *gorm.DB, teamID, memberID uint64, at time.Time) error {
+	result := currentAssignments(tx).
+		Where("team_id = ? AND team_member_id = ?", teamID, memberID).
+		Update("ended_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotAssigned
+	}
+	return nil
+}
+
+// defaultAssignment fills in the role, allocation and start left out of an assignment
+func defaultAssignment(assignment *models.TeamAssignment) {
+	defaults := newAssignment(assignment.TeamID, assignment.MemberID, time.Now())
+	assignment.Role = cmp.Or(assignment.Role, defaults.Role)
+	assignment.Allocation = cmp.Or(assignment.Allocation, defaults.Allocation)
+	if assignment.StartedAt.IsZero() {
+		assignment.StartedAt = defaults.StartedAt
+	}
+}
+
+func (r *gormTeamRepository) RemoveMember(teamID, memberID uint64, at time.Time) error {
+	if err := closeAssignment(r.db, teamID, memberID, at); err != nil && !errors.Is(err, ErrNotAssigned) {
+		return err
+	}
+	return nil
+}
+
+func (r *gormTeamRepository) MoveMember(fromTeamID uint64, assignment *models.TeamAssignment) error {
+	defaultAssignment(assignment)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockTeam(tx, fromTeamID); err != nil {
+			return err
+		}
+		if err := closeAssignment(tx, fromTeamID, assignment.MemberID, assignment.StartedAt); err != nil {
+			return err
+		}
+		return addMember(tx, assignment)
+	})
+}
+
+func (r *gormTeamRepository) AddMembers(assignments []models.TeamAssignment, atomic bool) ([]error, error) {
+	return applyEach(r.db, len(assignments), atomic, func(tx *gorm.DB, i int) error {
+		return addMember(tx, &assignments[i])
+	})
+}
+
+func (r *gormTeamRepository) RemoveMembers(teamID uint64, memberIDs []uint64, at time.Time, atomic bool) ([]error, error) {
+	return applyEach(r.db, len(memberIDs), atomic, func(tx *gorm.DB, i int) error {
+		if err := lockTeam(tx, teamID); err != nil {
+			return err
+		}
+		return closeAssignment(tx, teamID, memberIDs[i], at)
+	})
+}
+
+// errRollback ends the transaction of an atomic bulk change that had failures
+var errRollback = errors.New("rollback")
+
+// applyEach applies n changes in one transaction, each under a savepoint so
+// that a failed change is undone alone, and returns the error of each. With
+// atomic, every change is rolled back when one of them failed.
+func applyEach(db *gorm.DB, n int, atomic bool, apply func(tx *gorm.DB, i int) error) ([]error, error) {
+	errs := make([]error, n)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		failed := false
+		for i := range n {
+			errs[i] = tx.Transaction(func(tx *gorm.DB) error { return apply(tx, i) })
+			failed = failed || errs[i] != nil
+		}
+		if atomic && failed {
+			return errRollback
+		}
+		return nil
+	})
+	if errors.Is(err, errRollback) {
+		err = nil
+	}
+	return errs, err
+}
+
+func (r *gormTeamRepository) ListSubtree(id *uint64) ([]models.Team, error) {
+	query := r.db
+	if id != nil {
+		subtree := r.db.Raw(`WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM teams WHERE id = ? AND deleted_at IS NULL
+			UNION
+			SELECT teams.id FROM teams JOIN subtree ON teams.parent_id = subtree.id WHERE teams.deleted_at IS NULL
+		) SELECT id FROM subtree`, *id)
+		query = query.Where("id IN (?)", subtree)
+	}
+	teams := []models.Team{}
+	if err := query.Order("name").Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	return teams, loadMembers(r.db, teams)
+}
+
+func (r *gormTeamRepository) ListAncestors(id uint64) ([]models.Team, error) {
+	teams := []models.Team{}
+	err := r.db.Raw(`WITH RECURSIVE ancestors(id, depth) AS (
+		SELECT parents.id, 1 FROM teams JOIN teams parents ON parents.id = teams.parent_id
+		WHERE teams.id = ? AND parents.deleted_at IS NULL
+		UNION
+		SELECT parents.id, ancestors.depth + 1 FROM ancestors
+		JOIN teams ON teams.id = ancestors.id
+		JOIN teams parents ON parents.id = teams.parent_id
+		WHERE parents.deleted_at IS NULL
+	) SELECT teams.* FROM teams JOIN ancestors ON teams.id = ancestors.id ORDER BY ancestors.depth`, id).
+		Scan(&teams).Error
+	return teams, err
+}
+
+func (r *gormTeamRepository) ListAssignments(filter MembershipFilter) ([]models.TeamAssignment, error) {
+	query := r.db.Preload("Team").Preload("Member")
+	if filter.TeamID != nil {
+		query = query.Where("team_id = ?", *filter.TeamID)
+	}
+	if filter.MemberID != nil {
+		query = query.Where("team_member_id = ?", *filter.MemberID)
+	}
+	if filter.At != nil {
+		query = query.Where("started_at <= ? AND (ended_at IS NULL OR ended_at > ?)", *filter.At, *filter.At)
+	}
+	assignments := []models.TeamAssignment{}
+	err := query.Order("started_at, id").Find(&assignments).Error
+	return assignments, err
+}
+
+func (r *gormTeamRepository) ListLeadIDs(teamID uint64) ([]uint64, error) {
+	var granted, assigned []uint64
+	err := r.db.Model(&models.MemberRole{}).
+		Where("role = ? AND team_id = ?", models.RoleTeamLead, teamID).
+		Pluck("member_id", &granted).Error
+	if err != nil {
+		return nil, err
+	}
+	err = currentAssignments(r.db).
+		Where("team_id = ? AND role = ?", teamID, models.TeamRoleLead).
+		Pluck("team_member_id", &assigned).Error
+	if err != nil {
+		return nil, err
+	}
+	ids := append(granted, assigned...)
+	slices.Sort(ids)
+	return slices.Compact(ids), nil
+}
+
+type gormFeedbackRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormFeedbackRepository) Create(feedback *models.Feedback) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createFeedback(tx, feedback)
+	})
+}
+
+// createFeedback stores the feedback with its scores and answers. Its tags are
+// looked up by name and created when new; every path creating feedback goes
+// through here so that tags are never duplicated.
+func createFeedback(tx *gorm.DB, feedback *models.Feedback) error {
+	if err := lockTarget(tx, feedback.TargetType, feedback.TargetID, clause.LockingStrengthShare); err != nil {
+		return err
+	}
+	for i, tag := range feedback.Tags {
+		if err := tx.Where(models.Tag{Name: tag.Name}).FirstOrCreate(&feedback.Tags[i]).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Omit("Tags.*").Create(feedback).Error
+}
+
+func (r *gormFeedbackRepository) Get(id uint64) (*models.Feedback, error) {
+	var feedback models.Feedback
+	if err := r.db.Scopes(withFeedbackDetails).First(&feedback, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &feedback, nil
+}
+
+// withFeedbackDetails preloads the scores, answers and tags of feedback
+func withFeedbackDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Scores").Preload("Answers").
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("tags.name") })
+}
+
+func (r *gormFeedbackRepository) List(filter FeedbackFilter, opts ListOptions) ([]models.Feedback, int64, error) {
+	var feedbacks []models.Feedback
+	total, err := findPage(r.filtered(filter).Scopes(withFeedbackDetails), opts, &feedbacks)
+	return feedbacks, total, err
+}
+
+// filtered selects the feedback matched by the filter
+func (r *gormFeedbackRepository) filtered(filter FeedbackFilter) *gorm.DB {
+	query := r.db.Model(&models.Feedback{})
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+	if filter.Reader != nil {
+		query = query.Where(readerScope(r.db, *filter.Reader))
+	}
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		query = query.Where("target_id = ?", *filter.TargetID)
+	}
+	if len(filter.TargetIDs) > 0 {
+		query = query.Where("target_id IN ?", filter.TargetIDs)
+	}
+	if filter.GiverID != nil {
+		query = query.Where("giver_id = ?", *filter.GiverID)
+	}
+	if filter.CycleID != nil {
+		query = query.Where("review_cycle_id = ?", *filter.CycleID)
+	}
+	if filter.GoalID != nil {
+		query = query.Where("goal_id = ?", *filter.GoalID)
+	}
+	if filter.Visibility != "" {
+		query = query.Where("visibility = ?", filter.Visibility)
+	}
+	if filter.Acknowledged != nil {
+		if *filter.Acknowledged {
+			query = query.Where("acknowledged_at IS NOT NULL")
+		} else {
+			query = query.Where("acknowledged_at IS NULL")
+		}
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Archived != nil {
+		if *filter.Archived {
+			query = query.Where("archived_at IS NOT NULL")
+		} else {
+			query = query.Where("archived_at IS NULL")
+		}
+	}
+	for _, tag := range filter.Tags {
+		tagged := r.db.Table("feedback_tags").Select("feedback_tags.feedback_id").
+			Joins("JOIN tags ON tags.id = feedback_tags.tag_id").
+			Where("tags.name = ?", tag)
+		query = query.Where("id IN (?)", tagged)
+	}
+	return query
+}
+
+func (r *gormFeedbackRepository) Restore(id uint64) (*models.Feedback, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var feedback models.Feedback
+		if err := tx.Unscoped().First(&feedback, id).Error; err != nil {
+			return translateError(err)
+		}
+		if !feedback.DeletedAt.Valid {
+			return nil
+		}
+		if err := lockTarget(tx, feedback.TargetType, feedback.TargetID, clause.LockingStrengthShare); err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Feedback{}).Where("id = ?", id).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(id)
+}
+
+func (r *gormFeedbackRepository) Purge(before time.Time) (int64, error) {
+	result := r.db.Unscoped().Where("deleted_at < ?", before).Delete(&models.Feedback{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *gormFeedbackRepository) ListTags(prefix string, limit int, reader *FeedbackReader) ([]models.Tag, error) {
+	query := whereLike(r.db, "name", "%s%%", prefix)
+	if reader != nil {
+		readable := r.filtered(FeedbackFilter{Reader: reader}).Select("id")
+		query = query.Where("id IN (?)", r.db.Table("feedback_tags").Select("tag_id").Where("feedback_id IN (?)", readable))
+	}
+	tags := []models.Tag{}
+	err := query.Order("name").Limit(limit).Find(&tags).Error
+	return tags, err
+}
+
+func (r *gormFeedbackRepository) CountTags(filter FeedbackFilter) ([]TagCount, error) {
+	counts := []TagCount{}
+	// Anonymous givers are told apart by their hash, and the givers that were
+	// deleted count as one
+	err := r.db.Table("feedback_tags").
+		Select(`tags.name AS tag, COUNT(*) AS count, COUNT(DISTINCT CASE
+			WHEN feedbacks.giver_hash <> '' THEN feedbacks.giver_hash
+			WHEN feedbacks.giver_id IS NOT NULL THEN CAST(feedbacks.giver_id AS CHAR)
+			ELSE '' END) AS givers`).
+		Joins("JOIN tags ON tags.id = feedback_tags.tag_id").
+		Joins("JOIN feedbacks ON feedbacks.id = feedback_tags.feedback_id").
+		Where("feedback_tags.feedback_id IN (?)", r.filtered(filter).Select("id")).
+		Group("tags.name").
+		Order("count DESC, tags.name").
+		Scan(&counts).Error
+	return counts, err
+}
+
+func (r *gormFeedbackRepository) SetVisibility(change *models.VisibilityChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Feedback{}).Where("id = ?", change.FeedbackID).Update("visibility", change.NewVisibility)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return tx.Create(change).Error
+	})
+}
+
+func (r *gormFeedbackRepository) ListVisibilityChanges(feedbackID uint64) ([]models.VisibilityChange, error) {
+	changes := []models.VisibilityChange{}
+	err := r.db.Where("feedback_id = ?", feedbackID).Order("id").Find(&changes).Error
+	return changes, err
+}
+
+func (r *gormFeedbackRepository) Acknowledge(id, memberID uint64, at time.Time) (*models.Feedback, error) {
+	// UpdateColumns leaves updated_at alone: acknowledging is not an edit
+	err := r.db.Model(&models.Feedback{}).
+		Where("id = ? AND acknowledged_at IS NULL", id).
+		UpdateColumns(map[string]any{"acknowledged_at": at, "acknowledged_by_id": memberID}).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(id)
+}
+
+func (r *gormFeedbackRepository) Revise(id uint64, content string, changedByID *uint64) (*models.Feedback, error) {
+	var feedback models.Feedback
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Scopes(withFeedbackDetails).First(&feedback, id).Error; err != nil {
+			return translateError(err)
+		}
+		if err := tx.Create(&models.FeedbackVersion{FeedbackID: id, Version: feedback.Version, Content: feedback.Content, ChangedByID: changedByID}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&feedback).Updates(map[string]any{"content": content, "version": feedback.Version + 1}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &feedback, nil
+}
+
+func (r *gormFeedbackRepository) Retract(id uint64, changedByID *uint64, at time.Time) (*models.Feedback, error) {
+	var feedback models.Feedback
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&feedback, id).Error; err != nil {
+			return translateError(err)
+		}
+		if err := tx.Create(&models.FeedbackVersion{FeedbackID: id, Version: feedback.Version, Content: feedback.Content, ChangedByID: changedByID}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("feedback_id = ?", id).Delete(&models.FeedbackScore{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("feedback_id = ?", id).Delete(&models.FeedbackAnswer{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&feedback).Association("Tags").Clear(); err != nil {
+			return err
+		}
+		return tx.Model(&feedback).Updates(map[string]any{"content": "", "version": feedback.Version + 1, "retracted_at": at}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	feedback.Scores = []models.FeedbackScore{}
+	feedback.Answers = []models.FeedbackAnswer{}
+	feedback.Tags = []models.Tag{}
+	return &feedback, nil
+}
+
+func (r *gormFeedbackRepository) ListVersions(feedbackID uint64) ([]models.FeedbackVersion, error) {
+	versions := []models.FeedbackVersion{}
+	err := r.db.Where("feedback_id = ?", feedbackID).Order("version").Find(&versions).Error
+	return versions, err
+}
+
+// readerScope is the condition matching the feedback a FeedbackReader may read
+func readerScope(db *gorm.DB, reader FeedbackReader) *gorm.DB {
+	if reader.All {
+		return db.Where("feedbacks.visibility <> ?", models.VisibilityPrivate).
+			Or("feedbacks.giver_id = ?", reader.MemberID)
+	}
+
+	shared := []string{models.VisibilityRecipient, models.VisibilityTeam}
+	myTeams := currentAssignments(db).Select("team_id").Where("team_member_id = ?", reader.MemberID)
+	teammates := currentAssignments(db).Select("team_member_id").Where("team_id IN (?)", myTeams)
+	scope := db.Where("feedbacks.giver_id = ?", reader.MemberID).
+		Or("feedbacks.visibility IN ? AND feedbacks.target_type = ? AND feedbacks.target_id = ?", shared, "member", reader.MemberID).
+		Or("feedbacks.visibility IN ? AND feedbacks.target_type = ? AND feedbacks.target_id IN (?)", shared, "team", myTeams).
+		Or("feedbacks.visibility = ? AND feedbacks.target_type = ? AND feedbacks.target_id IN (?)", models.VisibilityTeam, "member", teammates)
+
+	reports := db.Model(&models.TeamMember{}).Select("id").Where("manager_id = ?", reader.MemberID)
+	scope = scope.Or("feedbacks.visibility <> ? AND feedbacks.target_type = ? AND feedbacks.target_id IN (?)", models.VisibilityPrivate, "member", reports)
+
+	if len(reader.LedTeamIDs) > 0 {
+		ledMembers := currentAssignments(db).Select("team_member_id").Where("team_id IN ?", reader.LedTeamIDs)
+		scope = scope.Or("feedbacks.visibility <> ? AND feedbacks.target_type = ? AND feedbacks.target_id IN ?", models.VisibilityPrivate, "team", reader.LedTeamIDs).
+			Or("feedbacks.visibility <> ? AND feedbacks.target_type = ? AND feedbacks.target_id IN (?)", models.VisibilityPrivate, "member", ledMembers)
+	}
+	return scope
+}
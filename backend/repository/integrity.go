@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"time"
+
+	"coaching-app/models"
+
+	"gorm.io/gorm"
+)
+
+// Orphan is feedback, not archived, about a member or team that does not exist
+type Orphan struct {
+	FeedbackID uint64
+	TargetType string
+	TargetID   uint64
+}
+
+type IntegrityRepository interface {
+	// FindOrphans lists the orphaned feedback ordered by ID
+	FindOrphans() ([]Orphan, error)
+	// RepairOrphans deletes (FeedbackCascade) or archives (any other policy) the
+	// orphaned feedback and returns how many entries it repaired
+	RepairOrphans(policy string) (int64, error)
+}
+
+type gormIntegrityRepository struct {
+	db *gorm.DB
+}
+
+// orphans selects the orphaned feedback, including feedback with an unknown target type
+func (r *gormIntegrityRepository) orphans(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Feedback{}).
+		Where("archived_at IS NULL").
+		Where(db.Where("target_type = ? AND target_id NOT IN (?)", "member", db.Model(&models.TeamMember{}).Select("id")).
+			Or("target_type = ? AND target_id NOT IN (?)", "team", db.Model(&models.Team{}).Select("id")).
+			Or("target_type NOT IN ?", []string{"member", "team"}))
+}
+
+func (r *gormIntegrityRepository) FindOrphans() ([]Orphan, error) {
+	orphans := []Orphan{}
+	err := r.orphans(r.db).Select("id AS feedback_id, target_type, target_id").Order("id").Scan(&orphans).Error
+	return orphans, err
+}
+
+func (r *gormIntegrityRepository) RepairOrphans(policy string) (int64, error) {
+	var repaired int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint64
+		if err := r.orphans(tx).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		var result *gorm.DB
+		if policy == FeedbackCascade {
+			result = tx.Where("id IN ?", ids).Delete(&models.Feedback{})
+		} else {
+			result = tx.Model(&models.Feedback{}).Where("id IN ?", ids).Update("archived_at", time.Now())
+		}
+		repaired = result.RowsAffected
+		return result.Error
+	})
+	return repaired, err
+}
@@ -0,0 +1,1488 @@
+package repository
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"coaching-app/models"
+
+	"gorm.io/gorm"
+)
+
+// memoryStore holds the rows shared by the in-memory repositories
+type memoryStore struct {
+	mu          sync.Mutex
+	nextID      uint64
+	members     map[uint64]models.TeamMember
+	accounts    map[uint64]models.Account // by member ID
+	roles       map[uint64]models.MemberRole
+	teams       map[uint64]models.Team     // without Members
+	memberships map[uint64]map[uint64]bool // the members on each team now
+	assignments map[uint64]models.TeamAssignment
+	managers    map[uint64]models.ManagerPeriod
+	feedbacks   map[uint64]models.Feedback
+	tags        map[string]models.Tag // by name
+	// visibilityChanges are kept in the order they were made
+	visibilityChanges []models.VisibilityChange
+	// feedbackVersions are kept in the order they were recorded
+	feedbackVersions []models.FeedbackVersion
+	// The soft-deleted rows are kept apart until they are restored or purged
+	deletedMembers   map[uint64]models.TeamMember
+	deletedTeams     map[uint64]models.Team
+	deletedFeedbacks map[uint64]models.Feedback
+}
+
+// NewMemoryRepositories returns repositories that keep everything in memory.
+// They behave like the GORM repositories and let tests run fully isolated.
+// Only the member, team and feedback repositories have an in-memory version.
+func NewMemoryRepositories() Repositories {
+	store := &memoryStore{
+		members:     map[uint64]models.TeamMember{},
+		accounts:    map[uint64]models.Account{},
+		roles:       map[uint64]models.MemberRole{},
+		teams:       map[uint64]models.Team{},
+		memberships: map[uint64]map[uint64]bool{},
+		assignments: map[uint64]models.TeamAssignment{},
+		managers:    map[uint64]models.ManagerPeriod{},
+		feedbacks:   map[uint64]models.Feedback{},
+		tags:        map[string]models.Tag{},
+
+		deletedMembers:   map[uint64]models.TeamMember{},
+		deletedTeams:     map[uint64]models.Team{},
+		deletedFeedbacks: map[uint64]models.Feedback{},
+	}
+	return Repositories{
+		Members:  &memoryMemberRepository{store},
+		Teams:    &memoryTeamRepository{store},
+		Feedback: &memoryFeedbackRepository{store},
+	}
+}
+
+// targetExists tells whether the member or team feedback is about exists
+func (s *memoryStore) targetExists(targetType string, targetID uint64) bool {
+	if targetType == "team" {
+		_, ok := s.teams[targetID]
+		return ok
+	}
+	_, ok := s.members[targetID]
+	return ok
+}
+
+// disposeFeedback applies the deletion policy to the feedback about a member
+// or team about to be deleted
+func (s *memoryStore) disposeFeedback(targetType string, targetID uint64, deletion TargetDeletion, now time.Time) error {
+	if !slices.Contains(TargetDeletionPolicies, deletion.Policy) {
+		return fmt.Errorf("unknown feedback deletion policy %q", deletion.Policy)
+	}
+	if deletion.Policy == FeedbackReassign && (deletion.ReassignTo == targetID || !s.targetExists(targetType, deletion.ReassignTo)) {
+		return ErrTargetNotFound
+	}
+
+	for id, feedback := range s.feedbacks {
+		if feedback.TargetType != targetType || feedback.TargetID != targetID {
+			continue
+		}
+		switch deletion.Policy {
+		case FeedbackCascade:
+			delete(s.feedbacks, id)
+			feedback.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+			s.deletedFeedbacks[id] = feedback
+			continue
+		case FeedbackReassign:
+			feedback.TargetID = deletion.ReassignTo
+		case FeedbackArchive:
+			if feedback.ArchivedAt == nil {
+				feedback.ArchivedAt = &now
+			}
+		}
+		s.feedbacks[id] = feedback
+	}
+	return nil
+}
+
+// restoreFeedback brings back the feedback about a member or team that was
+// deleted or archived when it was soft-deleted at deletedAt
+func (s *memoryStore) restoreFeedback(targetType string, targetID uint64, deletedAt time.Time) {
+	for id, feedback := range s.deletedFeedbacks {
+		if feedback.TargetType == targetType && feedback.TargetID == targetID && feedback.DeletedAt.Time.Equal(deletedAt) {
+			delete(s.deletedFeedbacks, id)
+			feedback.DeletedAt = gorm.DeletedAt{}
+			s.feedbacks[id] = feedback
+		}
+	}
+	for id, feedback := range s.feedbacks {
+		if feedback.TargetType == targetType && feedback.TargetID == targetID && feedback.ArchivedAt != nil && feedback.ArchivedAt.Equal(deletedAt) {
+			feedback.ArchivedAt = nil
+			s.feedbacks[id] = feedback
+		}
+	}
+}
+
+// clearGiver mirrors the ON DELETE SET NULL foreign key of the feedback giver
+func (s *memoryStore) clearGiver(memberID uint64) {
+	for _, feedbacks := range []map[uint64]models.Feedback{s.feedbacks, s.deletedFeedbacks} {
+		for id, feedback := range feedbacks {
+			if feedback.GiverID != nil && *feedback.GiverID == memberID {
+				feedback.GiverID = nil
+				feedbacks[id] = feedback
+			}
+		}
+	}
+}
+
+func (s *memoryStore) newID() uint64 {
+	s.nextID++
+	return s.nextID
+}
+
+// withMembers returns a copy of the team with its Members filled in
+func (s *memoryStore) withMembers(team models.Team) models.Team {
+	team.Members = []models.TeamMember{}
+	for memberID := range s.memberships[team.ID] {
+		if member, ok := s.members[memberID]; ok {
+			team.Members = append(team.Members, member)
+		}
+	}
+	slices.SortFunc(team.Members, func(a, b models.TeamMember) int { return cmp.Compare(a.ID, b.ID) })
+	return team
+}
+
+// sortAndPage orders items like the SQL implementation would and returns the
+// requested page with the total number of items. keys maps the sortable
+// columns to comparison functions.
+func sortAndPage[T any](items []T, opts ListOptions, id func(T) uint64, keys map[string]func(a, b T) int) ([]T, int64) {
+	compareKey := keys[opts.SortBy]
+	slices.SortStableFunc(items, func(a, b T) int {
+		if compareKey != nil {
+			result := compareKey(a, b)
+			if opts.Desc {
+				result = -result
+			}
+			if result != 0 {
+				return result
+			}
+		}
+		return cmp.Compare(id(a), id(b))
+	})
+
+	total := int64(len(items))
+	start := min(opts.Offset(), len(items))
+	end := min(start+opts.PageSize, len(items))
+	return items[start:end], total
+}
+
+func compareTime(a, b time.Time) int {
+	return a.Compare(b)
+}
+
+type memoryMemberRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryMemberRepository) Create(member *models.TeamMember) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if member.Email != "" && s.emailTaken(member.Email) {
+		return ErrDuplicate
+	}
+	if member.ManagerID != nil && *member.ManagerID == 0 {
+		member.ManagerID = nil
+	}
+	if member.ManagerID != nil {
+		if _, ok := s.members[*member.ManagerID]; !ok {
+			return ErrManagerNotFound
+		}
+	}
+	member.ID = s.newID()
+	if member.CreatedAt.IsZero() {
+		member.CreatedAt = time.Now()
+	}
+	s.members[member.ID] = *member
+	if member.ManagerID != nil {
+		period := models.ManagerPeriod{ID: s.newID(), MemberID: member.ID, ManagerID: *member.ManagerID, StartedAt: member.CreatedAt}
+		s.managers[period.ID] = period
+	}
+	return nil
+}
+
+// emailTaken tells whether a member, even soft-deleted, has the email; the
+// caller holds the lock
+func (s *memoryStore) emailTaken(email string) bool {
+	for _, members := range []map[uint64]models.TeamMember{s.members, s.deletedMembers} {
+		for _, existing := range members {
+			if existing.Email == email {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *memoryMemberRepository) Get(id uint64) (*models.TeamMember, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, ok := s.members[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &member, nil
+}
+
+func (r *memoryMemberRepository) GetByEmail(email string) (*models.TeamMember, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, member := range s.members {
+		if member.Email == email {
+			return &member, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryMemberRepository) List(filter MemberFilter, opts ListOptions) ([]models.TeamMember, int64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []map[uint64]models.TeamMember{s.members}
+	if filter.IncludeDeleted {
+		rows = append(rows, s.deletedMembers)
+	}
+	var members []models.TeamMember
+	for member := range allValues(rows) {
+		if len(filter.IDs) > 0 && !slices.Contains(filter.IDs, member.ID) {
+			continue
+		}
+		if filter.NamePrefix != "" && !strings.HasPrefix(member.Name, filter.NamePrefix) {
+			continue
+		}
+		if filter.EmailDomain != "" && !strings.HasSuffix(member.Email, "@"+filter.EmailDomain) {
+			continue
+		}
+		if filter.TeamID != nil && !s.memberships[*filter.TeamID][member.ID] {
+			continue
+		}
+		members = append(members, member)
+	}
+
+	page, total := sortAndPage(members, opts, func(m models.TeamMember) uint64 { return m.ID }, map[string]func(a, b models.TeamMember) int{
+		"name":       func(a, b models.TeamMember) int { return strings.Compare(a.Name, b.Name) },
+		"email":      func(a, b models.TeamMember) int { return strings.Compare(a.Email, b.Email) },
+		"created_at": func(a, b models.TeamMember) int { return compareTime(a.CreatedAt, b.CreatedAt) },
+	})
+	return page, total, nil
+}
+
+func (r *memoryMemberRepository) Update(id uint64, changes models.TeamMember) (*models.TeamMember, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, ok := s.members[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if changes.Email != "" && changes.Email != member.Email {
+		if s.emailTaken(changes.Email) {
+			return nil, ErrDuplicate
+		}
+		member.Email = changes.Email
+	}
+	if changes.Name != "" {
+		member.Name = changes.Name
+	}
+	if changes.PictureURL != "" {
+		member.PictureURL = changes.PictureURL
+	}
+	s.members[id] = member
+	return &member, nil
+}
+
+func (r *memoryMemberRepository) Delete(id uint64, deletion TargetDeletion) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, ok := s.members[id]
+	if !ok {
+		return ErrNotFound
+	}
+	now := time.Now()
+	if err := s.disposeFeedback("member", id, deletion, now); err != nil {
+		return err
+	}
+	for _, report := range s.members {
+		if sameID(report.ManagerID, &id) {
+			s.changeManager(report, member.ManagerID, now)
+		}
+	}
+	delete(s.members, id)
+	member.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	s.deletedMembers[id] = member
+	return nil
+}
+
+func (r *memoryMemberRepository) Restore(id uint64) (*models.TeamMember, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if member, ok := s.members[id]; ok {
+		return &member, nil
+	}
+	member, ok := s.deletedMembers[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	s.restoreFeedback("member", id, member.DeletedAt.Time)
+	delete(s.deletedMembers, id)
+	member.DeletedAt = gorm.DeletedAt{}
+	s.members[id] = member
+	return &member, nil
+}
+
+func (r *memoryMemberRepository) Purge(before time.Time) (int64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, member := range s.deletedMembers {
+		if !member.DeletedAt.Time.Before(before) {
+			continue
+		}
+		// Mirror the ON DELETE CASCADE and SET NULL foreign keys
+		delete(s.deletedMembers, id)
+		delete(s.accounts, id)
+		for roleID, role := range s.roles {
+			if role.MemberID == id {
+				delete(s.roles, roleID)
+			}
+		}
+		for _, members := range s.memberships {
+			delete(members, id)
+		}
+		for assignmentID, assignment := range s.assignments {
+			if assignment.MemberID == id {
+				delete(s.assignments, assignmentID)
+			}
+		}
+		for periodID, period := range s.managers {
+			if period.MemberID == id || period.ManagerID == id {
+				delete(s.managers, periodID)
+			}
+		}
+		for _, members := range []map[uint64]models.TeamMember{s.members, s.deletedMembers} {
+			for reportID, report := range members {
+				if sameID(report.ManagerID, &id) {
+					report.ManagerID = nil
+					members[reportID] = report
+				}
+			}
+		}
+		s.clearGiver(id)
+		purged++
+	}
+	return purged, nil
+}
+
+func (r *memoryMemberRepository) SetManager(memberID uint64, managerID *uint64, at time.Time) (*models.TeamMember, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, ok := s.members[memberID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if managerID != nil && *managerID == 0 {
+		managerID = nil
+	}
+	if managerID != nil {
+		if err := s.checkManager(memberID, *managerID); err != nil {
+			return nil, err
+		}
+	}
+	member = s.changeManager(member, managerID, at)
+	return &member, nil
+}
+
+// checkManager makes sure that a member can report to managerID; the caller
+// holds the lock
+func (s *memoryStore) checkManager(memberID, managerID uint64) error {
+	if _, ok := s.members[managerID]; !ok {
+		return ErrManagerNotFound
+	}
+	for current := &managerID; current != nil; {
+		if *current == memberID {
+			return ErrCycle
+		}
+		manager, ok := s.members[*current]
+		if !ok {
+			manager = s.deletedMembers[*current]
+		}
+		current = manager.ManagerID
+	}
+	return nil
+}
+
+// changeManager closes the open manager period of the member at the given time
+// and opens one with managerID, unless it is already their manager; the caller
+// holds the lock
+func (s *memoryStore) changeManager(member models.TeamMember, managerID *uint64, at time.Time) models.TeamMember {
+	if sameID(member.ManagerID, managerID) {
+		return member
+	}
+	for id, period := range s.managers {
+		if period.MemberID == member.ID && period.EndedAt == nil {
+			period.EndedAt = &at
+			s.managers[id] = period
+		}
+	}
+	member.ManagerID = managerID
+	s.members[member.ID] = member
+	if managerID != nil {
+		period := models.ManagerPeriod{ID: s.newID(), MemberID: member.ID, ManagerID: *managerID, StartedAt: at}
+		s.managers[period.ID] = period
+	}
+	return member
+}
+
+func (r *memoryMemberRepository) ListManagerPeriods(memberID uint64) ([]models.ManagerPeriod, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	periods := []models.ManagerPeriod{}
+	for _, period := range s.managers {
+		if period.MemberID != memberID {
+			continue
+		}
+		manager, ok := s.members[period.ManagerID]
+		if !ok {
+			manager, ok = s.deletedMembers[period.ManagerID]
+		}
+		if ok {
+			period.Manager = &manager
+		}
+		periods = append(periods, period)
+	}
+	slices.SortFunc(periods, func(a, b models.ManagerPeriod) int {
+		return cmp.Or(a.StartedAt.Compare(b.StartedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return periods, nil
+}
+
+func (r *memoryMemberRepository) ListReports(managerID uint64, transitive bool) ([]models.TeamMember, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reports := []models.TeamMember{}
+	for _, member := range s.members {
+		if sameID(member.ManagerID, &managerID) || transitive && s.reportsTo(member, managerID) {
+			reports = append(reports, member)
+		}
+	}
+	slices.SortFunc(reports, func(a, b models.TeamMember) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return reports, nil
+}
+
+// reportsTo tells whether the member reports to managerID, directly or through
+// members that are not deleted; the caller holds the lock
+func (s *memoryStore) reportsTo(member models.TeamMember, managerID uint64) bool {
+	for member.ManagerID != nil {
+		if *member.ManagerID == managerID {
+			return true
+		}
+		manager, ok := s.members[*member.ManagerID]
+		if !ok {
+			return false
+		}
+		member = manager
+	}
+	return false
+}
+
+func (r *memoryMemberRepository) GetAccount(memberID uint64) (*models.Account, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[memberID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &account, nil
+}
+
+func (r *memoryMemberRepository) SaveAccount(account *models.Account) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if account.ID == 0 {
+		if _, exists := s.accounts[account.MemberID]; exists {
+			return ErrDuplicate
+		}
+		account.ID = s.newID()
+		account.CreatedAt = now
+	}
+	account.UpdatedAt = now
+	s.accounts[account.MemberID] = *account
+	return nil
+}
+
+func (r *memoryMemberRepository) CountAccounts() (int64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.accounts)), nil
+}
+
+func (r *memoryMemberRepository) ListRoles(memberID uint64) ([]models.MemberRole, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	roles := []models.MemberRole{}
+	for _, role := range s.roles {
+		if role.MemberID == memberID {
+			roles = append(roles, role)
+		}
+	}
+	slices.SortFunc(roles, func(a, b models.MemberRole) int { return cmp.Compare(a.ID, b.ID) })
+	return roles, nil
+}
+
+func (r *memoryMemberRepository) GrantRole(role *models.MemberRole) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.roles {
+		if existing.MemberID == role.MemberID && existing.Role == role.Role && sameID(existing.TeamID, role.TeamID) {
+			*role = existing
+			return nil
+		}
+	}
+	role.ID = s.newID()
+	role.CreatedAt = time.Now()
+	s.roles[role.ID] = *role
+	return nil
+}
+
+// sameID tells whether two optional IDs are equal
+func sameID(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func (r *memoryMemberRepository) RevokeRole(memberID, roleID uint64) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	role, ok := s.roles[roleID]
+	if !ok || role.MemberID != memberID {
+		return ErrNotFound
+	}
+	delete(s.roles, roleID)
+	return nil
+}
+
+type memoryTeamRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryTeamRepository) Create(team *models.Team) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.teamNameTaken(team.Name) {
+		return ErrDuplicate
+	}
+	if team.ParentID != nil && *team.ParentID == 0 {
+		team.ParentID = nil
+	}
+	if team.ParentID != nil {
+		if err := s.checkParent(0, *team.ParentID); err != nil {
+			return err
+		}
+	}
+	team.ID = s.newID()
+	if team.CreatedAt.IsZero() {
+		team.CreatedAt = time.Now()
+	}
+	members := map[uint64]bool{}
+	for _, member := range team.Members {
+		members[member.ID] = true
+		assignment := newAssignment(team.ID, member.ID, team.CreatedAt)
+		assignment.ID = s.newID()
+		s.assignments[assignment.ID] = assignment
+	}
+	s.memberships[team.ID] = members
+
+	stored := *team
+	stored.Members = nil
+	s.teams[team.ID] = stored
+	return nil
+}
+
+// teamNameTaken tells whether a team, even soft-deleted, has the name; the
+// caller holds the lock
+func (s *memoryStore) teamNameTaken(name string) bool {
+	for _, teams := range []map[uint64]models.Team{s.teams, s.deletedTeams} {
+		for _, existing := range teams {
+			if existing.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *memoryTeamRepository) Get(id uint64) (*models.Team, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, ok := s.teams[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	team = s.withMembers(team)
+	return &team, nil
+}
+
+func (r *memoryTeamRepository) List(filter TeamFilter, opts ListOptions) ([]models.Team, int64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []map[uint64]models.Team{s.teams}
+	if filter.IncludeDeleted {
+		rows = append(rows, s.deletedTeams)
+	}
+	var teams []models.Team
+	for team := range allValues(rows) {
+		if len(filter.IDs) > 0 && !slices.Contains(filter.IDs, team.ID) {
+			continue
+		}
+		if filter.NamePrefix != "" && !strings.HasPrefix(team.Name, filter.NamePrefix) {
+			continue
+		}
+		if filter.MemberID != nil && !s.memberships[team.ID][*filter.MemberID] {
+			continue
+		}
+		teams = append(teams, team)
+	}
+
+	page, total := sortAndPage(teams, opts, func(t models.Team) uint64 { return t.ID }, map[string]func(a, b models.Team) int{
+		"name":       func(a, b models.Team) int { return strings.Compare(a.Name, b.Name) },
+		"created_at": func(a, b models.Team) int { return compareTime(a.CreatedAt, b.CreatedAt) },
+	})
+	for i := range page {
+		page[i] = s.withMembers(page[i])
+	}
+	return page, total, nil
+}
+
+func (r *memoryTeamRepository) Update(id uint64, changes models.Team) (*models.Team, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, ok := s.teams[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if changes.Name != "" && changes.Name != team.Name {
+		if s.teamNameTaken(changes.Name) {
+			return nil, ErrDuplicate
+		}
+		team.Name = changes.Name
+	}
+	if changes.LogoURL != "" {
+		team.LogoURL = changes.LogoURL
+	}
+	if changes.ParentID != nil {
+		if *changes.ParentID == 0 {
+			team.ParentID = nil
+		} else if err := s.checkParent(id, *changes.ParentID); err != nil {
+			return nil, err
+		} else {
+			team.ParentID = changes.ParentID
+		}
+	}
+	s.teams[id] = team
+	return &team, nil
+}
+
+// checkParent makes sure that a team can be placed under parentID; the caller
+// holds the lock
+func (s *memoryStore) checkParent(teamID, parentID uint64) error {
+	if _, ok := s.teams[parentID]; !ok {
+		return ErrParentNotFound
+	}
+	for current := &parentID; current != nil; {
+		if *current == teamID {
+			return ErrCycle
+		}
+		team, ok := s.teams[*current]
+		if !ok {
+			team = s.deletedTeams[*current]
+		}
+		current = team.ParentID
+	}
+	return nil
+}
+
+func (r *memoryTeamRepository) Delete(id uint64, deletion TargetDeletion) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, ok := s.teams[id]
+	if !ok {
+		return ErrNotFound
+	}
+	now := time.Now()
+	if err := s.disposeFeedback("team", id, deletion, now); err != nil {
+		return err
+	}
+	delete(s.teams, id)
+	team.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	s.deletedTeams[id] = team
+	return nil
+}
+
+func (r *memoryTeamRepository) Restore(id uint64) (*models.Team, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, ok := s.teams[id]
+	if !ok {
+		if team, ok = s.deletedTeams[id]; !ok {
+			return nil, ErrNotFound
+		}
+		s.restoreFeedback("team", id, team.DeletedAt.Time)
+		delete(s.deletedTeams, id)
+		team.DeletedAt = gorm.DeletedAt{}
+		s.teams[id] = team
+	}
+	team = s.withMembers(team)
+	return &team, nil
+}
+
+func (r *memoryTeamRepository) Purge(before time.Time) (int64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, team := range s.deletedTeams {
+		if !team.DeletedAt.Time.Before(before) {
+			continue
+		}
+		delete(s.deletedTeams, id)
+		delete(s.memberships, id)
+		for _, teams := range []map[uint64]models.Team{s.teams, s.deletedTeams} {
+			for childID, child := range teams {
+				if child.ParentID != nil && *child.ParentID == id {
+					child.ParentID = nil
+					teams[childID] = child
+				}
+			}
+		}
+		for assignmentID, assignment := range s.assignments {
+			if assignment.TeamID == id {
+				delete(s.assignments, assignmentID)
+			}
+		}
+		for roleID, role := range s.roles {
+			if role.TeamID != nil && *role.TeamID == id {
+				delete(s.roles, roleID)
+			}
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (r *memoryTeamRepository) AddMember(assignment *models.TeamAssignment) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addMember(assignment)
+}
+
+// addMember opens an assignment period, see AddMember; the caller holds the lock
+func (s *memoryStore) addMember(assignment *models.TeamAssignment) error {
+	if _, ok := s.teams[assignment.TeamID]; !ok {
+		return ErrNotFound
+	}
+	if _, ok := s.members[assignment.MemberID]; !ok {
+		return ErrNotFound
+	}
+	defaultAssignment(assignment)
+	if open, ok := s.openAssignment(assignment.TeamID, assignment.MemberID); ok {
+		if open.Role == assignment.Role && open.Allocation == assignment.Allocation {
+			*assignment = open
+			return nil
+		}
+		open.EndedAt = &assignment.StartedAt
+		s.assignments[open.ID] = open
+	}
+	assignment.ID = s.newID()
+	s.assignments[assignment.ID] = *assignment
+	s.memberships[assignment.TeamID][assignment.MemberID] = true
+	return nil
+}
+
+// openAssignment returns the open assignment period of a member on a team; the
+// caller holds the lock
+func (s *memoryStore) openAssignment(teamID, memberID uint64) (models.TeamAssignment, bool) {
+	for _, assignment := range s.assignments {
+		if assignment.TeamID == teamID && assignment.MemberID == memberID && assignment.EndedAt == nil {
+			return assignment, true
+		}
+	}
+	return models.TeamAssignment{}, false
+}
+
+func (r *memoryTeamRepository) RemoveMember(teamID, memberID uint64, at time.Time) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closeAssignment(teamID, memberID, at)
+	return nil
+}
+
+// closeAssignment closes the open assignment period of the member on the team
+// at the given time, or returns ErrNotAssigned when there is none; the caller
+// holds the lock
+func (s *memoryStore) closeAssignment(teamID, memberID uint64, at time.Time) error {
+	open, ok := s.openAssignment(teamID, memberID)
+	if !ok {
+		return ErrNotAssigned
+	}
+	open.EndedAt = &at
+	s.assignments[open.ID] = open
+	delete(s.memberships[teamID], memberID)
+	return nil
+}
+
+func (r *memoryTeamRepository) MoveMember(fromTeamID uint64, assignment *models.TeamAssignment) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.teams[fromTeamID]; !ok {
+		return ErrNotFound
+	}
+	defaultAssignment(assignment)
+	restore := s.saveMemberships()
+	if err := s.closeAssignment(fromTeamID, assignment.MemberID, assignment.StartedAt); err != nil {
+		return err
+	}
+	if err := s.addMember(assignment); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+func (r *memoryTeamRepository) AddMembers(assignments []models.TeamAssignment, atomic bool) ([]error, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.applyEach(len(assignments), atomic, func(i int) error {
+		return s.addMember(&assignments[i])
+	}), nil
+}
+
+func (r *memoryTeamRepository) RemoveMembers(teamID uint64, memberIDs []uint64, at time.Time, atomic bool) ([]error, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.applyEach(len(memberIDs), atomic, func(i int) error {
+		if _, ok := s.teams[teamID]; !ok {
+			return ErrNotFound
+		}
+		return s.closeAssignment(teamID, memberIDs[i], at)
+	}), nil
+}
+
+// applyEach applies n changes and returns the error of each. A failed change
+// leaves nothing behind, and with atomic every change is undone when one of
+// them failed. The caller holds the lock.
+func (s *memoryStore) applyEach(n int, atomic bool, apply func(i int) error) []error {
+	restore := s.saveMemberships()
+	errs := make([]error, n)
+	failed := false
+	for i := range n {
+		errs[i] = apply(i)
+		failed = failed || errs[i] != nil
+	}
+	if atomic && failed {
+		restore()
+	}
+	return errs
+}
+
+// saveMemberships copies the memberships and assignment periods, and returns a
+// function putting the copy back; the caller holds the lock
+func (s *memoryStore) saveMemberships() func() {
+	assignments := maps.Clone(s.assignments)
+	memberships := make(map[uint64]map[uint64]bool, len(s.memberships))
+	for teamID, members := range s.memberships {
+		memberships[teamID] = maps.Clone(members)
+	}
+	return func() {
+		s.assignments = assignments
+		s.memberships = memberships
+	}
+}
+
+func (r *memoryTeamRepository) ListSubtree(id *uint64) ([]models.Team, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	teams := []models.Team{}
+	for _, team := range s.teams {
+		if id == nil || s.isBelow(team, *id) {
+			teams = append(teams, s.withMembers(team))
+		}
+	}
+	slices.SortFunc(teams, func(a, b models.Team) int { return strings.Compare(a.Name, b.Name) })
+	return teams, nil
+}
+
+// isBelow tells whether the team is rootID or below it, through teams that are
+// not deleted; the caller holds the lock
+func (s *memoryStore) isBelow(team models.Team, rootID uint64) bool {
+	for {
+		if team.ID == rootID {
+			return true
+		}
+		if team.ParentID == nil {
+			return false
+		}
+		parent, ok := s.teams[*team.ParentID]
+		if !ok {
+			return false
+		}
+		team = parent
+	}
+}
+
+func (r *memoryTeamRepository) ListAncestors(id uint64) ([]models.Team, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ancestors := []models.Team{}
+	team, ok := s.teams[id]
+	if !ok {
+		team = s.deletedTeams[id]
+	}
+	for team.ParentID != nil {
+		parent, ok := s.teams[*team.ParentID]
+		if !ok {
+			break
+		}
+		ancestors = append(ancestors, parent)
+		team = parent
+	}
+	return ancestors, nil
+}
+
+func (r *memoryTeamRepository) ListAssignments(filter MembershipFilter) ([]models.TeamAssignment, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	assignments := []models.TeamAssignment{}
+	for _, assignment := range s.assignments {
+		if filter.TeamID != nil && assignment.TeamID != *filter.TeamID {
+			continue
+		}
+		if filter.MemberID != nil && assignment.MemberID != *filter.MemberID {
+			continue
+		}
+		if filter.At != nil && !assignment.Active(*filter.At) {
+			continue
+		}
+		if team, ok := s.teams[assignment.TeamID]; ok {
+			assignment.Team = &team
+		}
+		if member, ok := s.members[assignment.MemberID]; ok {
+			assignment.Member = &member
+		}
+		assignments = append(assignments, assignment)
+	}
+	slices.SortFunc(assignments, func(a, b models.TeamAssignment) int {
+		return cmp.Or(a.StartedAt.Compare(b.StartedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return assignments, nil
+}
+
+func (r *memoryTeamRepository) ListLeadIDs(teamID uint64) ([]uint64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := []uint64{}
+	for _, role := range s.roles {
+		if role.Role == models.RoleTeamLead && role.TeamID != nil && *role.TeamID == teamID {
+			ids = append(ids, role.MemberID)
+		}
+	}
+	for _, assignment := range s.assignments {
+		if assignment.TeamID == teamID && assignment.EndedAt == nil && assignment.Role == models.TeamRoleLead {
+			ids = append(ids, assignment.MemberID)
+		}
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids), nil
+}
+
+type memoryFeedbackRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryFeedbackRepository) Create(feedback *models.Feedback) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.targetExists(feedback.TargetType, feedback.TargetID) {
+		return ErrTargetNotFound
+	}
+	now := time.Now()
+	feedback.ID = s.newID()
+	for i := range feedback.Scores {
+		feedback.Scores[i].ID = s.newID()
+		feedback.Scores[i].FeedbackID = feedback.ID
+	}
+	for i := range feedback.Answers {
+		feedback.Answers[i].ID = s.newID()
+		feedback.Answers[i].FeedbackID = feedback.ID
+	}
+	for i, tag := range feedback.Tags {
+		stored, ok := s.tags[tag.Name]
+		if !ok {
+			stored = models.Tag{ID: s.newID(), Name: tag.Name}
+			s.tags[tag.Name] = stored
+		}
+		feedback.Tags[i] = stored
+	}
+	if feedback.CreatedAt.IsZero() {
+		feedback.CreatedAt = now
+	}
+	if feedback.UpdatedAt.IsZero() {
+		feedback.UpdatedAt = now
+	}
+	if feedback.Visibility == "" {
+		feedback.Visibility = models.VisibilityRecipient
+	}
+	if feedback.Version == 0 {
+		feedback.Version = 1
+	}
+	s.feedbacks[feedback.ID] = *feedback
+	return nil
+}
+
+func (r *memoryFeedbackRepository) Get(id uint64) (*models.Feedback, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feedback, ok := s.feedbacks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &feedback, nil
+}
+
+func (r *memoryFeedbackRepository) List(filter FeedbackFilter, opts ListOptions) ([]models.Feedback, int64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var feedbacks []models.Feedback
+	for feedback := range s.filterRows(filter) {
+		if s.matches(filter, feedback) {
+			feedbacks = append(feedbacks, feedback)
+		}
+	}
+
+	page, total := sortAndPage(feedbacks, opts, func(f models.Feedback) uint64 { return f.ID }, map[string]func(a, b models.Feedback) int{
+		"created_at": func(a, b models.Feedback) int { return compareTime(a.CreatedAt, b.CreatedAt) },
+		"updated_at": func(a, b models.Feedback) int { return compareTime(a.UpdatedAt, b.UpdatedAt) },
+	})
+	return page, total, nil
+}
+
+// filterRows yields the feedback a filter looks at, including the soft-deleted
+// entries when it asks for them
+func (s *memoryStore) filterRows(filter FeedbackFilter) iter.Seq[models.Feedback] {
+	rows := []map[uint64]models.Feedback{s.feedbacks}
+	if filter.IncludeDeleted {
+		rows = append(rows, s.deletedFeedbacks)
+	}
+	return allValues(rows)
+}
+
+// allValues yields the values of all the maps
+func allValues[K comparable, V any](maps []map[K]V) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, m := range maps {
+			for _, value := range m {
+				if !yield(value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// matches reports whether the feedback is selected by the filter; the caller holds the lock
+func (s *memoryStore) matches(filter FeedbackFilter, feedback models.Feedback) bool {
+	if filter.Reader != nil && !s.canRead(*filter.Reader, feedback) {
+		return false
+	}
+	if len(filter.IDs) > 0 && !slices.Contains(filter.IDs, feedback.ID) {
+		return false
+	}
+	if filter.TargetType != "" && feedback.TargetType != filter.TargetType {
+		return false
+	}
+	if filter.TargetID != nil && feedback.TargetID != *filter.TargetID {
+		return false
+	}
+	if len(filter.TargetIDs) > 0 && !slices.Contains(filter.TargetIDs, feedback.TargetID) {
+		return false
+	}
+	if filter.GiverID != nil && (feedback.GiverID == nil || *feedback.GiverID != *filter.GiverID) {
+		return false
+	}
+	if filter.CycleID != nil && (feedback.ReviewCycleID == nil || *feedback.ReviewCycleID != *filter.CycleID) {
+		return false
+	}
+	if filter.GoalID != nil && (feedback.GoalID == nil || *feedback.GoalID != *filter.GoalID) {
+		return false
+	}
+	if filter.Visibility != "" && feedback.Visibility != filter.Visibility {
+		return false
+	}
+	if filter.Acknowledged != nil && (feedback.AcknowledgedAt != nil) != *filter.Acknowledged {
+		return false
+	}
+	if filter.CreatedFrom != nil && feedback.CreatedAt.Before(*filter.CreatedFrom) {
+		return false
+	}
+	if filter.CreatedBefore != nil && !feedback.CreatedAt.Before(*filter.CreatedBefore) {
+		return false
+	}
+	if filter.Category != "" && feedback.Category != filter.Category {
+		return false
+	}
+	if filter.Archived != nil && (feedback.ArchivedAt != nil) != *filter.Archived {
+		return false
+	}
+	for _, tag := range filter.Tags {
+		if !slices.ContainsFunc(feedback.Tags, func(t models.Tag) bool { return t.Name == tag }) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *memoryFeedbackRepository) SetVisibility(change *models.VisibilityChange) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feedback, ok := s.feedbacks[change.FeedbackID]
+	if !ok {
+		return ErrNotFound
+	}
+	feedback.Visibility = change.NewVisibility
+	s.feedbacks[feedback.ID] = feedback
+
+	change.ID = s.newID()
+	if change.CreatedAt.IsZero() {
+		change.CreatedAt = time.Now()
+	}
+	s.visibilityChanges = append(s.visibilityChanges, *change)
+	return nil
+}
+
+func (r *memoryFeedbackRepository) ListVisibilityChanges(feedbackID uint64) ([]models.VisibilityChange, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes := []models.VisibilityChange{}
+	for _, change := range s.visibilityChanges {
+		if change.FeedbackID == feedbackID {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func (r *memoryFeedbackRepository) Acknowledge(id, memberID uint64, at time.Time) (*models.Feedback, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feedback, ok := s.feedbacks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if feedback.AcknowledgedAt == nil {
+		feedback.AcknowledgedAt = &at
+		feedback.AcknowledgedByID = &memberID
+		s.feedbacks[id] = feedback
+	}
+	return &feedback, nil
+}
+
+func (r *memoryFeedbackRepository) Revise(id uint64, content string, changedByID *uint64) (*models.Feedback, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feedback, ok := s.feedbacks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	s.recordVersion(feedback, changedByID)
+	feedback.Content = content
+	feedback.Version++
+	feedback.UpdatedAt = time.Now()
+	s.feedbacks[id] = feedback
+	return &feedback, nil
+}
+
+func (r *memoryFeedbackRepository) Retract(id uint64, changedByID *uint64, at time.Time) (*models.Feedback, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feedback, ok := s.feedbacks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	s.recordVersion(feedback, changedByID)
+	feedback.Content = ""
+	feedback.Scores = []models.FeedbackScore{}
+	feedback.Answers = []models.FeedbackAnswer{}
+	feedback.Tags = []models.Tag{}
+	feedback.Version++
+	feedback.RetractedAt = &at
+	feedback.UpdatedAt = time.Now()
+	s.feedbacks[id] = feedback
+	return &feedback, nil
+}
+
+// recordVersion keeps the current content of the feedback; the caller holds the lock
+func (s *memoryStore) recordVersion(feedback models.Feedback, changedByID *uint64) {
+	s.feedbackVersions = append(s.feedbackVersions, models.FeedbackVersion{
+		ID:          s.newID(),
+		FeedbackID:  feedback.ID,
+		Version:     feedback.Version,
+		Content:     feedback.Content,
+		ChangedByID: changedByID,
+		CreatedAt:   time.Now(),
+	})
+}
+
+func (r *memoryFeedbackRepository) ListVersions(feedbackID uint64) ([]models.FeedbackVersion, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := []models.FeedbackVersion{}
+	for _, version := range s.feedbackVersions {
+		if version.FeedbackID == feedbackID {
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
+
+func (r *memoryFeedbackRepository) Restore(id uint64) (*models.Feedback, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if feedback, ok := s.feedbacks[id]; ok {
+		return &feedback, nil
+	}
+	feedback, ok := s.deletedFeedbacks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if !s.targetExists(feedback.TargetType, feedback.TargetID) {
+		return nil, ErrTargetNotFound
+	}
+	delete(s.deletedFeedbacks, id)
+	feedback.DeletedAt = gorm.DeletedAt{}
+	s.feedbacks[id] = feedback
+	return &feedback, nil
+}
+
+func (r *memoryFeedbackRepository) Purge(before time.Time) (int64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := map[uint64]bool{}
+	for id, feedback := range s.deletedFeedbacks {
+		if feedback.DeletedAt.Time.Before(before) {
+			delete(s.deletedFeedbacks, id)
+			purged[id] = true
+		}
+	}
+	// Mirror the ON DELETE CASCADE foreign keys
+	s.visibilityChanges = slices.DeleteFunc(s.visibilityChanges, func(change models.VisibilityChange) bool { return purged[change.FeedbackID] })
+	s.feedbackVersions = slices.DeleteFunc(s.feedbackVersions, func(version models.FeedbackVersion) bool { return purged[version.FeedbackID] })
+	return int64(len(purged)), nil
+}
+
+func (r *memoryFeedbackRepository) ListTags(prefix string, limit int, reader *FeedbackReader) ([]models.Tag, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var readable map[string]bool
+	if reader != nil {
+		readable = map[string]bool{}
+		filter := FeedbackFilter{Reader: reader}
+		for feedback := range s.filterRows(filter) {
+			if s.matches(filter, feedback) {
+				for _, tag := range feedback.Tags {
+					readable[tag.Name] = true
+				}
+			}
+		}
+	}
+	tags := []models.Tag{}
+	for name, tag := range s.tags {
+		if strings.HasPrefix(name, prefix) && (readable == nil || readable[name]) {
+			tags = append(tags, tag)
+		}
+	}
+	slices.SortFunc(tags, func(a, b models.Tag) int { return strings.Compare(a.Name, b.Name) })
+	if len(tags) > limit {
+		tags = tags[:limit]
+	}
+	return tags, nil
+}
+
+func (r *memoryFeedbackRepository) CountTags(filter FeedbackFilter) ([]TagCount, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byTag := map[string]int64{}
+	givers := map[string]map[string]bool{}
+	for feedback := range s.filterRows(filter) {
+		if !s.matches(filter, feedback) {
+			continue
+		}
+		giver := feedback.GiverHash
+		if giver == "" && feedback.GiverID != nil {
+			giver = strconv.FormatUint(*feedback.GiverID, 10)
+		}
+		for _, tag := range feedback.Tags {
+			byTag[tag.Name]++
+			if givers[tag.Name] == nil {
+				givers[tag.Name] = map[string]bool{}
+			}
+			givers[tag.Name][giver] = true
+		}
+	}
+	counts := []TagCount{}
+	for tag, count := range byTag {
+		counts = append(counts, TagCount{Tag: tag, Count: count, Givers: int64(len(givers[tag]))})
+	}
+	slices.SortFunc(counts, func(a, b TagCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Tag, b.Tag))
+	})
+	return counts, nil
+}
+
+// canRead is the in-memory version of the GORM reader scope
+func (s *memoryStore) canRead(reader FeedbackReader, feedback models.Feedback) bool {
+	if feedback.GiverID != nil && *feedback.GiverID == reader.MemberID {
+		return true
+	}
+	if feedback.Visibility == models.VisibilityPrivate {
+		return false
+	}
+	if reader.All {
+		return true
+	}
+
+	recipientVisible := feedback.Visibility == models.VisibilityRecipient || feedback.Visibility == models.VisibilityTeam
+	switch feedback.TargetType {
+	case "member":
+		if target, ok := s.members[feedback.TargetID]; ok && sameID(target.ManagerID, &reader.MemberID) {
+			return true
+		}
+		for _, teamID := range reader.LedTeamIDs {
+			if s.memberships[teamID][feedback.TargetID] {
+				return true
+			}
+		}
+		if recipientVisible && feedback.TargetID == reader.MemberID {
+			return true
+		}
+		if feedback.Visibility == models.VisibilityTeam {
+			for _, members := range s.memberships {
+				if members[reader.MemberID] && members[feedback.TargetID] {
+					return true
+				}
+			}
+		}
+	case "team":
+		if slices.Contains(reader.LedTeamIDs, feedback.TargetID) {
+			return true
+		}
+		if recipientVisible && s.memberships[feedback.TargetID][reader.MemberID] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"coaching-app/models"
+
+	"gorm.io/gorm"
+)
+
+// OneOnOneFilter selects one-on-ones. MemberID matches either side of the pair.
+type OneOnOneFilter struct {
+	MemberID *uint64
+	Active   *bool
+}
+
+type ActionItemFilter struct {
+	OneOnOneID uint64
+	OwnerID    *uint64
+	Open       *bool
+}
+
+type OneOnOneRepository interface {
+	// Create stores the one-on-one, or returns ErrDuplicate when the pair already has one
+	Create(oneOnOne *models.OneOnOne) error
+	Get(id uint64) (*models.OneOnOne, error)
+	List(filter OneOnOneFilter, opts ListOptions) ([]models.OneOnOne, int64, error)
+	// Update applies the given column changes and returns the updated one-on-one
+	Update(id uint64, changes map[string]any) (*models.OneOnOne, error)
+
+	CreateMeeting(meeting *models.OneOnOneMeeting) error
+	// GetMeeting returns the meeting with its agenda, notes and action items,
+	// and the action items carried over from earlier meetings
+	GetMeeting(oneOnOneID, meetingID uint64) (*models.OneOnOneMeeting, error)
+	// ListMeetings returns the meetings of a one-on-one, latest first
+	ListMeetings(oneOnOneID uint64) ([]models.OneOnOneMeeting, error)
+	UpdateMeeting(oneOnOneID, meetingID uint64, changes map[string]any) error
+
+	AddAgendaItem(item *models.AgendaItem) error
+	UpdateAgendaItem(meetingID, itemID uint64, changes map[string]any) (*models.AgendaItem, error)
+
+	AddNote(note *models.MeetingNote) error
+	GetNote(meetingID, noteID uint64) (*models.MeetingNote, error)
+	UpdateNote(meetingID, noteID uint64, changes map[string]any) (*models.MeetingNote, error)
+
+	AddActionItem(item *models.ActionItem) error
+	// ListActionItems returns the action items of a one-on-one, oldest first
+	ListActionItems(filter ActionItemFilter) ([]models.ActionItem, error)
+	UpdateActionItem(oneOnOneID, itemID uint64, changes map[string]any) (*models.ActionItem, error)
+}
+
+type gormOneOnOneRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormOneOnOneRepository) Create(oneOnOne *models.OneOnOne) error {
+	var count int64
+	if err := r.db.Model(&models.OneOnOne{}).
+		Where("host_id = ? AND member_id = ?", oneOnOne.HostID, oneOnOne.MemberID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicate
+	}
+	return r.db.Create(oneOnOne).Error
+}
+
+func (r *gormOneOnOneRepository) Get(id uint64) (*models.OneOnOne, error) {
+	var oneOnOne models.OneOnOne
+	if err := r.db.First(&oneOnOne, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &oneOnOne, nil
+}
+
+func (r *gormOneOnOneRepository) List(filter OneOnOneFilter, opts ListOptions) ([]models.OneOnOne, int64, error) {
+	query := r.db.Model(&models.OneOnOne{})
+	if filter.MemberID != nil {
+		query = query.Where("host_id = ? OR member_id = ?", *filter.MemberID, *filter.MemberID)
+	}
+	if filter.Active != nil {
+		query = query.Where("active = ?", *filter.Active)
+	}
+
+	var oneOnOnes []models.OneOnOne
+	total, err := findPage(query, opts, &oneOnOnes)
+	return oneOnOnes, total, err
+}
+
+func (r *gormOneOnOneRepository) Update(id uint64, changes map[string]any) (*models.OneOnOne, error) {
+	oneOnOne, err := r.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(oneOnOne).Updates(changes).Error; err != nil {
+		return nil, err
+	}
+	return oneOnOne, nil
+}
+
+func (r *gormOneOnOneRepository) CreateMeeting(meeting *models.OneOnOneMeeting) error {
+	return r.db.Create(meeting).Error
+}
+
+func (r *gormOneOnOneRepository) GetMeeting(oneOnOneID, meetingID uint64) (*models.OneOnOneMeeting, error) {
+	var meeting models.OneOnOneMeeting
+	err := r.db.Where("one_on_one_id = ?", oneOnOneID).
+		Preload("AgendaItems", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Notes", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("ActionItems", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&meeting, meetingID).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	// Action items of earlier meetings that were not closed before this one
+	meeting.CarriedOver = []models.ActionItem{}
+	err = r.db.Table("action_items").
+		Select("action_items.*").
+		Joins("JOIN one_on_one_meetings ON one_on_one_meetings.id = action_items.meeting_id").
+		Where("action_items.one_on_one_id = ? AND one_on_one_meetings.scheduled_at < ?", oneOnOneID, meeting.ScheduledAt).
+		Where("action_items.closed_at IS NULL OR action_items.closed_at > ?", meeting.ScheduledAt).
+		Order("action_items.id").
+		Find(&meeting.CarriedOver).Error
+	return &meeting, err
+}
+
+func (r *gormOneOnOneRepository) ListMeetings(oneOnOneID uint64) ([]models.OneOnOneMeeting, error) {
+	meetings := []models.OneOnOneMeeting{}
+	err := r.db.Where("one_on_one_id = ?", oneOnOneID).Order("scheduled_at DESC, id DESC").Find(&meetings).Error
+	return meetings, err
+}
+
+func (r *gormOneOnOneRepository) UpdateMeeting(oneOnOneID, meetingID uint64, changes map[string]any) error {
+	var meeting models.OneOnOneMeeting
+	if err := r.db.Where("one_on_one_id = ?", oneOnOneID).First(&meeting, meetingID).Error; err != nil {
+		return translateError(err)
+	}
+	return r.db.Model(&meeting).Updates(changes).Error
+}
+
+func (r *gormOneOnOneRepository) AddAgendaItem(item *models.AgendaItem) error {
+	return r.db.Create(item).Error
+}
+
+func (r *gormOneOnOneRepository) UpdateAgendaItem(meetingID, itemID uint64, changes map[string]any) (*models.AgendaItem, error) {
+	var item models.AgendaItem
+	if err := r.db.Where("meeting_id = ?", meetingID).First(&item, itemID).Error; err != nil {
+		return nil, translateError(err)
+	}
+	if err := r.db.Model(&item).Updates(changes).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *gormOneOnOneRepository) AddNote(note *models.MeetingNote) error {
+	return r.db.Create(note).Error
+}
+
+func (r *gormOneOnOneRepository) GetNote(meetingID, noteID uint64) (*models.MeetingNote, error) {
+	var note models.MeetingNote
+	if err := r.db.Where("meeting_id = ?", meetingID).First(&note, noteID).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &note, nil
+}
+
+func (r *gormOneOnOneRepository) UpdateNote(meetingID, noteID uint64, changes map[string]any) (*models.MeetingNote, error) {
+	note, err := r.GetNote(meetingID, noteID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(note).Updates(changes).Error; err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+func (r *gormOneOnOneRepository) AddActionItem(item *models.ActionItem) error {
+	return r.db.Create(item).Error
+}
+
+func (r *gormOneOnOneRepository) ListActionItems(filter ActionItemFilter) ([]models.ActionItem, error) {
+	query := r.db.Where("one_on_one_id = ?", filter.OneOnOneID)
+	if filter.OwnerID != nil {
+		query = query.Where("owner_id = ?", *filter.OwnerID)
+	}
+	if filter.Open != nil {
+		if *filter.Open {
+			query = query.Where("closed_at IS NULL")
+		} else {
+			query = query.Where("closed_at IS NOT NULL")
+		}
+	}
+
+	items := []models.ActionItem{}
+	err := query.Order("id").Find(&items).Error
+	return items, err
+}
+
+func (r *gormOneOnOneRepository) UpdateActionItem(oneOnOneID, itemID uint64, changes map[string]any) (*models.ActionItem, error) {
+	var item models.ActionItem
+	if err := r.db.Where("one_on_one_id = ?", oneOnOneID).First(&item, itemID).Error; err != nil {
+		return nil, translateError(err)
+	}
+	if err := r.db.Model(&item).Updates(changes).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
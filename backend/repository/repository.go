@@ -0,0 +1,291 @@
+// Package repository defines the storage interfaces used by the HTTP handlers,
+// with GORM implementations for MySQL/SQLite and in-memory implementations for tests.
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"coaching-app/models"
+)
+
+var (
+	ErrNotFound  = errors.New("record not found")
+	ErrDuplicate = errors.New("record already exists")
+	// ErrTargetNotFound is returned when feedback is created for, or reassigned
+	// to, a member or team that does not exist
+	ErrTargetNotFound = errors.New("feedback target not found")
+	// ErrParentNotFound is returned when a team is placed under a team that
+	// does not exist
+	ErrParentNotFound = errors.New("parent team not found")
+	// ErrManagerNotFound is returned when a member is placed under a manager
+	// that does not exist
+	ErrManagerNotFound = errors.New("manager not found")
+	// ErrNotAssigned is returned when a member is moved or removed from a team
+	// they are not on
+	ErrNotAssigned = errors.New("member is not on the team")
+	// ErrCycle is returned when a change would make a team or member its own
+	// ancestor
+	ErrCycle = errors.New("hierarchy cycle")
+)
+
+// What becomes of the feedback about a member or team when it is deleted
+const (
+	// FeedbackCascade deletes the feedback
+	FeedbackCascade = "cascade"
+	// FeedbackReassign moves the feedback to another member or team
+	FeedbackReassign = "reassign"
+	// FeedbackArchive keeps the feedback and sets its ArchivedAt
+	FeedbackArchive = "archive"
+)
+
+// TargetDeletionPolicies lists the valid TargetDeletion policies
+var TargetDeletionPolicies = []string{FeedbackCascade, FeedbackReassign, FeedbackArchive}
+
+// TargetDeletion says what to do with the feedback about a member or team
+// that is deleted
+type TargetDeletion struct {
+	Policy string
+	// ReassignTo is the member or team receiving the feedback with FeedbackReassign
+	ReassignTo uint64
+}
+
+// ListOptions selects one page of a list. SortBy is a column name chosen by the
+// caller from a fixed set; an empty SortBy orders by ID.
+type ListOptions struct {
+	Page     int
+	PageSize int
+	SortBy   string
+	Desc     bool
+}
+
+// Offset is the number of rows skipped before the page
+func (o ListOptions) Offset() int {
+	return (o.Page - 1) * o.PageSize
+}
+
+type MemberFilter struct {
+	IDs         []uint64
+	NamePrefix  string
+	EmailDomain string
+	TeamID      *uint64
+	// IncludeDeleted also lists the soft-deleted members
+	IncludeDeleted bool
+}
+
+type TeamFilter struct {
+	IDs        []uint64
+	NamePrefix string
+	MemberID   *uint64
+	// IncludeDeleted also lists the soft-deleted teams
+	IncludeDeleted bool
+}
+
+type MembershipFilter struct {
+	TeamID   *uint64
+	MemberID *uint64
+	// At selects the periods during which the member was on the team at that time
+	At *time.Time
+}
+
+// FeedbackReader limits a feedback list to what a member may read, following
+// the visibility of each entry. A member always reads the feedback they gave.
+// Beyond that they read the feedback addressed to them or to their teams (unless
+// it is manager-only), team-visible feedback about their teammates, the
+// feedback addressed to the teams they lead or to the members of those teams,
+// and the feedback about their direct reports.
+// All readers (admins and coaches) read everything but the private feedback
+// of others.
+type FeedbackReader struct {
+	MemberID   uint64
+	LedTeamIDs []uint64
+	All        bool
+}
+
+type FeedbackFilter struct {
+	IDs          []uint64
+	TargetType   string
+	TargetID     *uint64
+	TargetIDs    []uint64 // any of them, with TargetType
+	GiverID      *uint64
+	CycleID      *uint64
+	GoalID       *uint64
+	Visibility   string
+	Acknowledged *bool
+	Category     string
+	// Archived selects the archived feedback, or the rest, when set
+	Archived *bool
+	// Tags selects the feedback carrying all of them
+	Tags          []string
+	CreatedFrom   *time.Time // inclusive
+	CreatedBefore *time.Time // exclusive
+	Reader        *FeedbackReader
+	// IncludeDeleted also lists the soft-deleted feedback
+	IncludeDeleted bool
+}
+
+// TagCount is how many feedback entries carry a tag, and how many distinct
+// givers they come from
+type TagCount struct {
+	Tag    string
+	Count  int64
+	Givers int64 `json:"-"`
+}
+
+type MemberRepository interface {
+	// Create stores the member and opens a period with their manager, if any. It
+	// returns ErrManagerNotFound when the manager does not exist.
+	Create(member *models.TeamMember) error
+	Get(id uint64) (*models.TeamMember, error)
+	GetByEmail(email string) (*models.TeamMember, error)
+	List(filter MemberFilter, opts ListOptions) ([]models.TeamMember, int64, error)
+	// Update applies the non-zero fields of changes, but ManagerID, and returns
+	// the updated member
+	Update(id uint64, changes models.TeamMember) (*models.TeamMember, error)
+	// Delete soft-deletes the member and deals with the feedback about them in
+	// the same transaction. Their account, roles and memberships are kept, and
+	// their reports move to their own manager.
+	Delete(id uint64, deletion TargetDeletion) error
+	// Restore brings a soft-deleted member back, with the feedback about them
+	// that was deleted or archived along with them
+	Restore(id uint64) (*models.TeamMember, error)
+	// Purge permanently removes the members soft-deleted before the given time
+	// and returns how many there were
+	Purge(before time.Time) (int64, error)
+
+	// SetManager closes the open manager period of the member at the given time
+	// and opens one with managerID, or leaves the member without a manager when
+	// managerID is nil. It returns ErrManagerNotFound when the manager does not
+	// exist and ErrCycle when the manager is the member or one of their reports.
+	SetManager(memberID uint64, managerID *uint64, at time.Time) (*models.TeamMember, error)
+	// ListManagerPeriods returns the manager periods of a member with their
+	// Manager, oldest first
+	ListManagerPeriods(memberID uint64) ([]models.ManagerPeriod, error)
+	// ListReports returns the members reporting to the manager, ordered by
+	// name. With transitive, the reports of the reports are included too.
+	ListReports(managerID uint64, transitive bool) ([]models.TeamMember, error)
+
+	GetAccount(memberID uint64) (*models.Account, error)
+	SaveAccount(account *models.Account) error
+	CountAccounts() (int64, error)
+
+	ListRoles(memberID uint64) ([]models.MemberRole, error)
+	// GrantRole stores the role unless the member already has it, in which
+	// case role is filled with the existing grant
+	GrantRole(role *models.MemberRole) error
+	RevokeRole(memberID, roleID uint64) error
+}
+
+type TeamRepository interface {
+	// Create stores the team together with the memberships of its Members. It
+	// returns ErrParentNotFound when its parent does not exist.
+	Create(team *models.Team) error
+	// Get returns the team with its Members
+	Get(id uint64) (*models.Team, error)
+	// List returns one page of teams with their Members
+	List(filter TeamFilter, opts ListOptions) ([]models.Team, int64, error)
+	// Update applies the non-zero fields of changes and returns the updated
+	// team. A ParentID of 0 makes the team a top-level one. It returns
+	// ErrParentNotFound when the parent does not exist and ErrCycle when the
+	// parent is the team itself or one of the teams below it.
+	Update(id uint64, changes models.Team) (*models.Team, error)
+	// Delete soft-deletes the team and deals with the feedback about it in the
+	// same transaction. Its memberships are kept.
+	Delete(id uint64, deletion TargetDeletion) error
+	// Restore brings a soft-deleted team back with its memberships, and with
+	// the feedback about it that was deleted or archived along with it
+	Restore(id uint64) (*models.Team, error)
+	// Purge permanently removes the teams soft-deleted before the given time,
+	// with their memberships, and returns how many there were
+	Purge(before time.Time) (int64, error)
+
+	// AddMember opens an assignment period starting at assignment.StartedAt.
+	// A member already on the team with the same role and allocation keeps
+	// their period, which fills assignment; otherwise their period is closed
+	// and a new one records the change. It returns ErrNotFound when the team
+	// or the member does not exist.
+	AddMember(assignment *models.TeamAssignment) error
+	// RemoveMember closes the open assignment period of the member, if any, at the given time
+	RemoveMember(teamID, memberID uint64, at time.Time) error
+	// MoveMember closes the open assignment period of the member on fromTeamID
+	// and opens assignment, at assignment.StartedAt and in one transaction. It
+	// returns ErrNotAssigned when the member is not on fromTeamID.
+	MoveMember(fromTeamID uint64, assignment *models.TeamAssignment) error
+	// AddMembers applies AddMember to each assignment and returns the error of
+	// each, nil for those applied. With atomic, nothing is applied unless every
+	// assignment is.
+	AddMembers(assignments []models.TeamAssignment, atomic bool) ([]error, error)
+	// RemoveMembers closes the open assignment periods of the members at the
+	// given time and returns the error of each, ErrNotAssigned for those not on
+	// the team. With atomic, nothing is applied unless every removal is.
+	RemoveMembers(teamID uint64, memberIDs []uint64, at time.Time, atomic bool) ([]error, error)
+	// ListAssignments returns the assignment periods matched by the filter with
+	// their Team and Member, oldest first
+	ListAssignments(filter MembershipFilter) ([]models.TeamAssignment, error)
+	// ListLeadIDs returns the members holding the team_lead role of the team or
+	// leading it in their open assignment period
+	ListLeadIDs(teamID uint64) ([]uint64, error)
+
+	// ListSubtree returns the team and all the teams below it with their
+	// Members, ordered by name, or every team when id is nil. Teams below a
+	// deleted team are left out.
+	ListSubtree(id *uint64) ([]models.Team, error)
+	// ListAncestors returns the teams above the team, its parent first, up to
+	// the first deleted one
+	ListAncestors(id uint64) ([]models.Team, error)
+}
+
+type FeedbackRepository interface {
+	// Create stores the feedback, or returns ErrTargetNotFound when its target
+	// does not exist
+	Create(feedback *models.Feedback) error
+	Get(id uint64) (*models.Feedback, error)
+	List(filter FeedbackFilter, opts ListOptions) ([]models.Feedback, int64, error)
+
+	// SetVisibility applies the change to its feedback and records it
+	SetVisibility(change *models.VisibilityChange) error
+	// ListVisibilityChanges returns the visibility changes of a feedback, oldest first
+	ListVisibilityChanges(feedbackID uint64) ([]models.VisibilityChange, error)
+	// Acknowledge records that memberID acknowledged the feedback at the given
+	// time and returns the feedback. Feedback is only acknowledged once: later
+	// calls return it unchanged.
+	Acknowledge(id, memberID uint64, at time.Time) (*models.Feedback, error)
+
+	// Revise records the current content of the feedback as a version and
+	// replaces it with content
+	Revise(id uint64, content string, changedByID *uint64) (*models.Feedback, error)
+	// Retract records the current content of the feedback as a version, then
+	// empties it, drops its scores and answers and marks the feedback as retracted
+	Retract(id uint64, changedByID *uint64, at time.Time) (*models.Feedback, error)
+	// ListVersions returns the earlier versions of a feedback, oldest first
+	ListVersions(feedbackID uint64) ([]models.FeedbackVersion, error)
+
+	// ListTags returns at most limit tags whose name starts with prefix, ordered
+	// by name. With a reader, only the tags of the feedback they may read.
+	ListTags(prefix string, limit int, reader *FeedbackReader) ([]models.Tag, error)
+	// CountTags counts the feedback matched by the filter per tag, most used first
+	CountTags(filter FeedbackFilter) ([]TagCount, error)
+
+	// Restore brings soft-deleted feedback back, or returns ErrTargetNotFound
+	// while its member or team is deleted
+	Restore(id uint64) (*models.Feedback, error)
+	// Purge permanently removes the feedback soft-deleted before the given time
+	// and returns how many entries there were
+	Purge(before time.Time) (int64, error)
+}
+
+// Repositories groups the storage used by the server
+type Repositories struct {
+	Members      MemberRepository
+	Teams        TeamRepository
+	Feedback     FeedbackRepository
+	Reviews      ReviewRepository
+	Competencies CompetencyRepository
+	Goals        GoalRepository
+	OneOnOnes    OneOnOneRepository
+	Threads      ThreadRepository
+	Requests     FeedbackRequestRepository
+	Templates    TemplateRepository
+	Search       SearchRepository
+	Integrity    IntegrityRepository
+}
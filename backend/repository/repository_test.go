@@ -0,0 +1,948 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"coaching-app/migrations"
+	"coaching-app/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// implementations returns a fresh instance of every Repositories implementation,
+// so the same behaviour is checked against the in-memory store and GORM
+func implementations(t *testing.T) map[string]Repositories {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	return map[string]Repositories{
+		"memory": NewMemoryRepositories(),
+		"gorm":   NewGormRepositories(db),
+	}
+}
+
+func firstPage() ListOptions {
+	return ListOptions{Page: 1, PageSize: 100}
+}
+
+func TestMembers(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			alice := models.TeamMember{Name: "Alice", Email: "alice@acme.io"}
+			bob := models.TeamMember{Name: "Bob", Email: "bob@other.io"}
+			assert.NoError(t, repos.Members.Create(&alice))
+			assert.NoError(t, repos.Members.Create(&bob))
+			assert.NotZero(t, alice.ID)
+
+			found, err := repos.Members.GetByEmail("bob@other.io")
+			if assert.NoError(t, err) {
+				assert.Equal(t, bob.ID, found.ID)
+			}
+
+			members, total, err := repos.Members.List(MemberFilter{EmailDomain: "acme.io"}, firstPage())
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), total)
+			if assert.Len(t, members, 1) {
+				assert.Equal(t, "Alice", members[0].Name)
+			}
+
+			members, total, err = repos.Members.List(MemberFilter{}, ListOptions{Page: 1, PageSize: 1, SortBy: "name", Desc: true})
+			assert.NoError(t, err)
+			assert.Equal(t, int64(2), total)
+			if assert.Len(t, members, 1) {
+				assert.Equal(t, "Bob", members[0].Name)
+			}
+
+			updated, err := repos.Members.Update(alice.ID, models.TeamMember{Name: "Alice Smith"})
+			if assert.NoError(t, err) {
+				assert.Equal(t, "Alice Smith", updated.Name)
+				assert.Equal(t, "alice@acme.io", updated.Email)
+			}
+
+			assert.NoError(t, repos.Members.Delete(bob.ID, TargetDeletion{Policy: FeedbackArchive}))
+			_, err = repos.Members.Get(bob.ID)
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.ErrorIs(t, repos.Members.Delete(bob.ID, TargetDeletion{Policy: FeedbackArchive}), ErrNotFound)
+			_, err = repos.Members.Update(bob.ID, models.TeamMember{Name: "Ghost"})
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestAccountsAndRoles(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			member := models.TeamMember{Name: "Alice", Email: "alice@example.com"}
+			assert.NoError(t, repos.Members.Create(&member))
+
+			_, err := repos.Members.GetAccount(member.ID)
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.NoError(t, repos.Members.SaveAccount(&models.Account{MemberID: member.ID, PasswordHash: "hash"}))
+			account, err := repos.Members.GetAccount(member.ID)
+			if assert.NoError(t, err) {
+				assert.Equal(t, "hash", account.PasswordHash)
+			}
+			count, err := repos.Members.CountAccounts()
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+
+			team := models.Team{Name: "Platform"}
+			assert.NoError(t, repos.Teams.Create(&team))
+
+			first := models.MemberRole{MemberID: member.ID, Role: models.RoleTeamLead, TeamID: &team.ID}
+			again := models.MemberRole{MemberID: member.ID, Role: models.RoleTeamLead, TeamID: &team.ID}
+			assert.NoError(t, repos.Members.GrantRole(&first))
+			assert.NoError(t, repos.Members.GrantRole(&again))
+			assert.Equal(t, first.ID, again.ID, "granting a role twice is idempotent")
+
+			roles, err := repos.Members.ListRoles(member.ID)
+			assert.NoError(t, err)
+			assert.Len(t, roles, 1)
+
+			assert.ErrorIs(t, repos.Members.RevokeRole(member.ID+1, first.ID), ErrNotFound)
+			assert.NoError(t, repos.Members.RevokeRole(member.ID, first.ID))
+			roles, err = repos.Members.ListRoles(member.ID)
+			assert.NoError(t, err)
+			assert.Empty(t, roles)
+		})
+	}
+}
+
+func TestTeams(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			alice := models.TeamMember{Name: "Alice", Email: "alice@example.com"}
+			bob := models.TeamMember{Name: "Bob", Email: "bob@example.com"}
+			assert.NoError(t, repos.Members.Create(&alice))
+			assert.NoError(t, repos.Members.Create(&bob))
+
+			team := models.Team{Name: "Platform", Members: []models.TeamMember{alice}}
+			assert.NoError(t, repos.Teams.Create(&team))
+			assert.NoError(t, repos.Teams.AddMember(&models.TeamAssignment{TeamID: team.ID, MemberID: bob.ID}))
+			assert.NoError(t, repos.Teams.AddMember(&models.TeamAssignment{TeamID: team.ID, MemberID: bob.ID}))
+
+			loaded, err := repos.Teams.Get(team.ID)
+			if assert.NoError(t, err) {
+				assert.Len(t, loaded.Members, 2)
+			}
+
+			teams, total, err := repos.Teams.List(TeamFilter{MemberID: &bob.ID}, firstPage())
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), total)
+			assert.Len(t, teams, 1)
+
+			members, _, err := repos.Members.List(MemberFilter{TeamID: &team.ID}, firstPage())
+			assert.NoError(t, err)
+			assert.Len(t, members, 2)
+
+			assert.NoError(t, repos.Teams.RemoveMember(team.ID, alice.ID, time.Now()))
+			loaded, err = repos.Teams.Get(team.ID)
+			if assert.NoError(t, err) && assert.Len(t, loaded.Members, 1) {
+				assert.Equal(t, bob.ID, loaded.Members[0].ID)
+			}
+
+			assert.NoError(t, repos.Teams.Delete(team.ID, TargetDeletion{Policy: FeedbackArchive}))
+			_, err = repos.Teams.Get(team.ID)
+			assert.ErrorIs(t, err, ErrNotFound)
+			teams, _, err = repos.Teams.List(TeamFilter{MemberID: &bob.ID}, firstPage())
+			assert.NoError(t, err)
+			assert.Empty(t, teams)
+		})
+	}
+}
+
+func TestFeedbackFilters(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			alice := models.TeamMember{Name: "Alice", Email: "alice@example.com"}
+			bob := models.TeamMember{Name: "Bob", Email: "bob@example.com"}
+			carol := models.TeamMember{Name: "Carol", Email: "carol@example.com"}
+			assert.NoError(t, repos.Members.Create(&alice))
+			assert.NoError(t, repos.Members.Create(&bob))
+			assert.NoError(t, repos.Members.Create(&carol))
+			aliceTeam := models.Team{Name: "Alice Team", Members: []models.TeamMember{alice}}
+			bobTeam := models.Team{Name: "Bob Team", Members: []models.TeamMember{bob}}
+			assert.NoError(t, repos.Teams.Create(&aliceTeam))
+			assert.NoError(t, repos.Teams.Create(&bobTeam))
+
+			january := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+			march := time.Date(2024, 3, 5, 15, 30, 0, 0, time.UTC)
+			for _, feedback := range []models.Feedback{
+				{Content: "To Alice", TargetType: "member", TargetID: alice.ID, GiverID: &carol.ID, CreatedAt: january},
+				{Content: "To Alice Team", TargetType: "team", TargetID: aliceTeam.ID, GiverID: &carol.ID, CreatedAt: march},
+				{Content: "To Bob", TargetType: "member", TargetID: bob.ID, GiverID: &carol.ID, CreatedAt: march},
+				{Content: "To Bob Team", TargetType: "team", TargetID: bobTeam.ID, GiverID: &alice.ID, CreatedAt: march},
+			} {
+				assert.NoError(t, repos.Feedback.Create(&feedback))
+			}
+
+			list := func(filter FeedbackFilter) []string {
+				feedbacks, total, err := repos.Feedback.List(filter, ListOptions{Page: 1, PageSize: 100, SortBy: "content"})
+				assert.NoError(t, err)
+				assert.Equal(t, int64(len(feedbacks)), total)
+				contents := []string{}
+				for _, feedback := range feedbacks {
+					contents = append(contents, feedback.Content)
+				}
+				return contents
+			}
+
+			assert.Equal(t, []string{"To Alice Team", "To Bob Team"}, list(FeedbackFilter{TargetType: "team"}))
+			assert.Equal(t, []string{"To Bob Team"}, list(FeedbackFilter{GiverID: &alice.ID}))
+			assert.Equal(t, []string{"To Alice"}, list(FeedbackFilter{CreatedBefore: &march}))
+			assert.Len(t, list(FeedbackFilter{CreatedFrom: &march}), 3)
+
+			// Alice reads what is addressed to her or her team and what she gave
+			assert.Equal(t, []string{"To Alice", "To Alice Team", "To Bob Team"},
+				list(FeedbackFilter{Reader: &FeedbackReader{MemberID: alice.ID}}))
+			// Leading Bob's team also opens the feedback of Bob himself
+			assert.Equal(t, []string{"To Alice", "To Alice Team", "To Bob", "To Bob Team"},
+				list(FeedbackFilter{Reader: &FeedbackReader{MemberID: alice.ID, LedTeamIDs: []uint64{bobTeam.ID}}}))
+
+		})
+	}
+}
+
+func TestFeedbackVisibility(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			coach := models.TeamMember{Name: "Coach", Email: "coach@example.com"}
+			alice := models.TeamMember{Name: "Alice", Email: "alice@example.com"}
+			bob := models.TeamMember{Name: "Bob", Email: "bob@example.com"}
+			lead := models.TeamMember{Name: "Lead", Email: "lead@example.com"}
+			for _, member := range []*models.TeamMember{&coach, &alice, &bob, &lead} {
+				assert.NoError(t, repos.Members.Create(member))
+			}
+			team := models.Team{Name: "Team", Members: []models.TeamMember{alice, bob}}
+			assert.NoError(t, repos.Teams.Create(&team))
+
+			for _, feedback := range []models.Feedback{
+				{Content: "Manager", TargetType: "member", TargetID: alice.ID, GiverID: &coach.ID, Visibility: models.VisibilityManager},
+				{Content: "Private", TargetType: "member", TargetID: alice.ID, GiverID: &coach.ID, Visibility: models.VisibilityPrivate},
+				{Content: "Recipient", TargetType: "member", TargetID: alice.ID, GiverID: &coach.ID},
+				{Content: "Team", TargetType: "member", TargetID: alice.ID, GiverID: &coach.ID, Visibility: models.VisibilityTeam},
+			} {
+				assert.NoError(t, repos.Feedback.Create(&feedback))
+			}
+
+			list := func(reader FeedbackReader) []string {
+				feedbacks, _, err := repos.Feedback.List(FeedbackFilter{Reader: &reader}, firstPage())
+				assert.NoError(t, err)
+				contents := []string{}
+				for _, feedback := range feedbacks {
+					contents = append(contents, feedback.Content)
+				}
+				return contents
+			}
+
+			assert.Equal(t, []string{"Manager", "Private", "Recipient", "Team"}, list(FeedbackReader{MemberID: coach.ID}))
+			assert.Equal(t, []string{"Manager", "Recipient", "Team"}, list(FeedbackReader{MemberID: lead.ID, All: true}))
+			assert.Equal(t, []string{"Manager", "Recipient", "Team"}, list(FeedbackReader{MemberID: lead.ID, LedTeamIDs: []uint64{team.ID}}))
+			assert.Equal(t, []string{"Recipient", "Team"}, list(FeedbackReader{MemberID: alice.ID}))
+			assert.Equal(t, []string{"Team"}, list(FeedbackReader{MemberID: bob.ID}))
+
+			feedbacks, _, err := repos.Feedback.List(FeedbackFilter{Visibility: models.VisibilityPrivate}, firstPage())
+			assert.NoError(t, err)
+			if !assert.Len(t, feedbacks, 1) {
+				return
+			}
+			change := models.VisibilityChange{FeedbackID: feedbacks[0].ID, ChangedByID: &coach.ID, OldVisibility: models.VisibilityPrivate, NewVisibility: models.VisibilityRecipient}
+			assert.NoError(t, repos.Feedback.SetVisibility(&change))
+			assert.ErrorIs(t, repos.Feedback.SetVisibility(&models.VisibilityChange{FeedbackID: 99999, NewVisibility: models.VisibilityTeam}), ErrNotFound)
+
+			assert.Equal(t, []string{"Private", "Recipient", "Team"}, list(FeedbackReader{MemberID: alice.ID}))
+			changes, err := repos.Feedback.ListVisibilityChanges(feedbacks[0].ID)
+			assert.NoError(t, err)
+			if assert.Len(t, changes, 1) {
+				assert.Equal(t, models.VisibilityRecipient, changes[0].NewVisibility)
+			}
+			stored, err := repos.Feedback.Get(feedbacks[0].ID)
+			assert.NoError(t, err)
+			assert.Equal(t, models.VisibilityRecipient, stored.Visibility)
+		})
+	}
+}
+
+func TestReviseAndRetractFeedback(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			alice := models.TeamMember{Name: "Alice", Email: "alice@example.com"}
+			assert.NoError(t, repos.Members.Create(&alice))
+			feedback := models.Feedback{Content: "First", TargetType: "member", TargetID: alice.ID, GiverID: &alice.ID,
+				Scores: []models.FeedbackScore{{CompetencyID: 1, Score: 3}}}
+			assert.NoError(t, repos.Feedback.Create(&feedback))
+
+			revised, err := repos.Feedback.Revise(feedback.ID, "Second", &alice.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, "Second", revised.Content)
+			assert.Equal(t, 2, revised.Version)
+			_, err = repos.Feedback.Revise(99999, "Nothing", nil)
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			retracted, err := repos.Feedback.Retract(feedback.ID, nil, time.Now())
+			assert.NoError(t, err)
+			assert.NotNil(t, retracted.RetractedAt)
+			assert.Equal(t, 3, retracted.Version)
+			stored, err := repos.Feedback.Get(feedback.ID)
+			assert.NoError(t, err)
+			assert.Empty(t, stored.Content)
+			assert.Empty(t, stored.Scores)
+
+			versions, err := repos.Feedback.ListVersions(feedback.ID)
+			assert.NoError(t, err)
+			if assert.Len(t, versions, 2) {
+				assert.Equal(t, []int{1, 2}, []int{versions[0].Version, versions[1].Version})
+				assert.Equal(t, []string{"First", "Second"}, []string{versions[0].Content, versions[1].Content})
+				assert.Equal(t, &alice.ID, versions[0].ChangedByID)
+				assert.Nil(t, versions[1].ChangedByID)
+			}
+		})
+	}
+}
+
+func TestAcknowledgeFeedback(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			alice := models.TeamMember{Name: "Alice", Email: "alice@example.com"}
+			assert.NoError(t, repos.Members.Create(&alice))
+			read := models.Feedback{Content: "Read", TargetType: "member", TargetID: alice.ID}
+			unread := models.Feedback{Content: "Unread", TargetType: "member", TargetID: alice.ID}
+			assert.NoError(t, repos.Feedback.Create(&read))
+			assert.NoError(t, repos.Feedback.Create(&unread))
+
+			first := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+			acknowledged, err := repos.Feedback.Acknowledge(read.ID, alice.ID, first)
+			assert.NoError(t, err)
+			if assert.NotNil(t, acknowledged.AcknowledgedAt) {
+				assert.True(t, first.Equal(*acknowledged.AcknowledgedAt))
+			}
+			acknowledged, err = repos.Feedback.Acknowledge(read.ID, alice.ID, first.Add(time.Hour))
+			assert.NoError(t, err)
+			assert.True(t, first.Equal(*acknowledged.AcknowledgedAt))
+			_, err = repos.Feedback.Acknowledge(99999, alice.ID, first)
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			list := func(filter FeedbackFilter) []string {
+				feedbacks, _, err := repos.Feedback.List(filter, firstPage())
+				assert.NoError(t, err)
+				contents := []string{}
+				for _, feedback := range feedbacks {
+					contents = append(contents, feedback.Content)
+				}
+				return contents
+			}
+			yes, no := true, false
+			assert.Equal(t, []string{"Read"}, list(FeedbackFilter{Acknowledged: &yes}))
+			assert.Equal(t, []string{"Unread"}, list(FeedbackFilter{Acknowledged: &no}))
+			assert.Equal(t, []string{"Unread"}, list(FeedbackFilter{IDs: []uint64{unread.ID}}))
+		})
+	}
+}
+
+func TestFeedbackTags(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			alice := models.TeamMember{Name: "Alice", Email: "alice@example.com"}
+			assert.NoError(t, repos.Members.Create(&alice))
+			first := models.Feedback{Content: "First", TargetType: "member", TargetID: alice.ID, Category: models.CategoryPraise,
+				Anonymous: true, GiverHash: "hash", Tags: []models.Tag{{Name: "communication"}, {Name: "delivery"}}}
+			second := models.Feedback{Content: "Second", TargetType: "member", TargetID: alice.ID, Category: models.CategoryImprovement,
+				GiverID: &alice.ID, Tags: []models.Tag{{Name: "communication"}}}
+			assert.NoError(t, repos.Feedback.Create(&first))
+			assert.NoError(t, repos.Feedback.Create(&second))
+			assert.Equal(t, first.Tags[0].ID, second.Tags[0].ID)
+
+			stored, err := repos.Feedback.Get(first.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, models.CategoryPraise, stored.Category)
+			if assert.Len(t, stored.Tags, 2) {
+				assert.Equal(t, "communication", stored.Tags[0].Name)
+			}
+
+			list := func(filter FeedbackFilter) []string {
+				feedbacks, _, err := repos.Feedback.List(filter, firstPage())
+				assert.NoError(t, err)
+				contents := []string{}
+				for _, feedback := range feedbacks {
+					contents = append(contents, feedback.Content)
+				}
+				return contents
+			}
+			assert.ElementsMatch(t, []string{"First", "Second"}, list(FeedbackFilter{Tags: []string{"communication"}}))
+			assert.Equal(t, []string{"First"}, list(FeedbackFilter{Tags: []string{"communication", "delivery"}}))
+			assert.Equal(t, []string{"Second"}, list(FeedbackFilter{Category: models.CategoryImprovement}))
+			assert.Empty(t, list(FeedbackFilter{Tags: []string{"unknown"}}))
+
+			counts, err := repos.Feedback.CountTags(FeedbackFilter{TargetType: "member", TargetID: &alice.ID})
+			assert.NoError(t, err)
+			assert.Equal(t, []TagCount{{Tag: "communication", Count: 2, Givers: 2}, {Tag: "delivery", Count: 1, Givers: 1}}, counts)
+			counts, err = repos.Feedback.CountTags(FeedbackFilter{Category: models.CategoryImprovement})
+			assert.NoError(t, err)
+			assert.Equal(t, []TagCount{{Tag: "communication", Count: 1, Givers: 1}}, counts)
+
+			tags, err := repos.Feedback.ListTags("d", 10, nil)
+			assert.NoError(t, err)
+			if assert.Len(t, tags, 1) {
+				assert.Equal(t, "delivery", tags[0].Name)
+			}
+			tags, err = repos.Feedback.ListTags("", 1, nil)
+			assert.NoError(t, err)
+			assert.Len(t, tags, 1)
+
+			// The tags of a private note are only suggested to its author
+			note := models.Feedback{Content: "Note", TargetType: "member", TargetID: alice.ID, GiverID: &alice.ID,
+				Visibility: models.VisibilityPrivate, Tags: []models.Tag{{Name: "secret"}}}
+			assert.NoError(t, repos.Feedback.Create(&note))
+			tags, err = repos.Feedback.ListTags("s", 10, &FeedbackReader{MemberID: alice.ID})
+			assert.NoError(t, err)
+			assert.Len(t, tags, 1)
+			tags, err = repos.Feedback.ListTags("s", 10, &FeedbackReader{MemberID: alice.ID + 1, All: true})
+			assert.NoError(t, err)
+			assert.Empty(t, tags)
+			tags, err = repos.Feedback.ListTags("", 10, &FeedbackReader{MemberID: alice.ID + 1})
+			assert.NoError(t, err)
+			assert.Empty(t, tags)
+		})
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	repos := implementations(t)["gorm"]
+	alice := models.TeamMember{Name: "Alice Oncall", Email: "alice@example.com"}
+	bob := models.TeamMember{Name: "Bob", Email: "bob@example.com"}
+	assert.NoError(t, repos.Members.Create(&alice))
+	assert.NoError(t, repos.Members.Create(&bob))
+	team := models.Team{Name: "Platform"}
+	assert.NoError(t, repos.Teams.Create(&team))
+	incident := models.Feedback{Content: "Great handling of the on-call incident, the incident review was clear", TargetType: "member", TargetID: alice.ID}
+	other := models.Feedback{Content: "Incidents aside, the roadmap slipped", TargetType: "team", TargetID: team.ID, Category: models.CategoryImprovement}
+	unrelated := models.Feedback{Content: "Nice demo", TargetType: "member", TargetID: bob.ID}
+	for _, feedback := range []*models.Feedback{&incident, &other, &unrelated} {
+		assert.NoError(t, repos.Feedback.Create(feedback))
+	}
+
+	hits, err := repos.Search.Search(SearchQuery{Text: "Incident", Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 2) {
+		assert.Equal(t, incident.ID, hits[0].ID)
+		assert.Equal(t, SearchFeedback, hits[0].Kind)
+		assert.Greater(t, hits[0].Score, hits[1].Score)
+		assert.Contains(t, hits[0].Snippet, "on-call <mark>incident</mark>")
+		assert.Contains(t, hits[1].Snippet, "<mark>Incidents</mark>")
+	}
+
+	// Narrowing down the feedback searched does not change the scores
+	scores := map[uint64]float64{}
+	for _, hit := range hits {
+		scores[hit.ID] = hit.Score
+	}
+	hits, err = repos.Search.Search(SearchQuery{Text: "incident", Feedback: FeedbackFilter{Category: models.CategoryImprovement}, Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, other.ID, hits[0].ID)
+		assert.Equal(t, scores[other.ID], hits[0].Score)
+	}
+
+	// Nor does the feedback hidden from the caller
+	reader := &FeedbackReader{MemberID: alice.ID}
+	hits, err = repos.Search.Search(SearchQuery{Text: "incident", Feedback: FeedbackFilter{Reader: reader}, Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, incident.ID, hits[0].ID)
+		scores[incident.ID] = hits[0].Score
+	}
+	for _, visibility := range []string{models.VisibilityPrivate, models.VisibilityManager} {
+		hidden := models.Feedback{Content: "Incident incident incident", TargetType: "member", TargetID: alice.ID, GiverID: &bob.ID, Visibility: visibility}
+		assert.NoError(t, repos.Feedback.Create(&hidden))
+	}
+	hits, err = repos.Search.Search(SearchQuery{Text: "incident", Feedback: FeedbackFilter{Reader: reader}, Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, scores[incident.ID], hits[0].Score)
+	}
+
+	hits, err = repos.Search.Search(SearchQuery{Text: "oncall platform", Kinds: []string{SearchMember, SearchTeam}, Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 2) {
+		assert.ElementsMatch(t, []string{SearchMember, SearchTeam}, []string{hits[0].Kind, hits[1].Kind})
+	}
+
+	hits, err = repos.Search.Search(SearchQuery{Text: "incident", Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+	hits, err = repos.Search.Search(SearchQuery{Text: " -- ", Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+}
+
+func TestHighlight(t *testing.T) {
+	assert.Equal(t, "The <mark>on</mark>-<mark>call</mark> &lt;b&gt; rota", highlight("The on-call <b> rota", []string{"on", "call"}))
+
+	long := strings.Repeat("filler ", 30) + "the outage was handled well " + strings.Repeat("filler ", 30)
+	snippet := highlight(long, []string{"outage"})
+	assert.True(t, strings.HasPrefix(snippet, "…"))
+	assert.True(t, strings.HasSuffix(snippet, "…"))
+	assert.Contains(t, snippet, "the <mark>outage</mark> was")
+	assert.LessOrEqual(t, len([]rune(snippet)), snippetLength+len("<mark></mark>")+2)
+}
+
+func TestFeedbackOnTargetDeletion(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			members := make([]models.TeamMember, 4)
+			for i := range members {
+				members[i] = models.TeamMember{Name: fmt.Sprintf("Member %d", i), Email: fmt.Sprintf("member%d@example.com", i)}
+				assert.NoError(t, repos.Members.Create(&members[i]))
+			}
+			give := func(targetID uint64) models.Feedback {
+				feedback := models.Feedback{Content: "Feedback", TargetType: "member", TargetID: targetID}
+				assert.NoError(t, repos.Feedback.Create(&feedback))
+				return feedback
+			}
+			cascaded, reassigned, archived := give(members[0].ID), give(members[1].ID), give(members[2].ID)
+
+			assert.NoError(t, repos.Members.Delete(members[0].ID, TargetDeletion{Policy: FeedbackCascade}))
+			_, err := repos.Feedback.Get(cascaded.ID)
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			assert.ErrorIs(t, repos.Members.Delete(members[1].ID, TargetDeletion{Policy: FeedbackReassign, ReassignTo: members[0].ID}), ErrTargetNotFound)
+			assert.ErrorIs(t, repos.Members.Delete(members[1].ID, TargetDeletion{Policy: FeedbackReassign, ReassignTo: members[1].ID}), ErrTargetNotFound)
+			_, err = repos.Members.Get(members[1].ID)
+			assert.NoError(t, err, "a failed deletion leaves the member in place")
+			assert.NoError(t, repos.Members.Delete(members[1].ID, TargetDeletion{Policy: FeedbackReassign, ReassignTo: members[3].ID}))
+			stored, err := repos.Feedback.Get(reassigned.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, members[3].ID, stored.TargetID)
+			assert.Nil(t, stored.ArchivedAt)
+
+			assert.NoError(t, repos.Members.Delete(members[2].ID, TargetDeletion{Policy: FeedbackArchive}))
+			stored, err = repos.Feedback.Get(archived.ID)
+			assert.NoError(t, err)
+			assert.NotNil(t, stored.ArchivedAt)
+
+			yes, no := true, false
+			feedbacks, _, err := repos.Feedback.List(FeedbackFilter{Archived: &yes}, firstPage())
+			assert.NoError(t, err)
+			if assert.Len(t, feedbacks, 1) {
+				assert.Equal(t, archived.ID, feedbacks[0].ID)
+			}
+			feedbacks, _, err = repos.Feedback.List(FeedbackFilter{Archived: &no}, firstPage())
+			assert.NoError(t, err)
+			assert.Len(t, feedbacks, 1)
+
+			orphan := models.Feedback{Content: "Too late", TargetType: "member", TargetID: members[2].ID}
+			assert.ErrorIs(t, repos.Feedback.Create(&orphan), ErrTargetNotFound)
+			orphan.TargetType = "team"
+			assert.ErrorIs(t, repos.Feedback.Create(&orphan), ErrTargetNotFound)
+		})
+	}
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			member := models.TeamMember{Name: "Member", Email: "member@example.com"}
+			assert.NoError(t, repos.Members.Create(&member))
+			team := models.Team{Name: "Team", Members: []models.TeamMember{member}}
+			assert.NoError(t, repos.Teams.Create(&team))
+			cascaded := models.Feedback{Content: "Cascaded", TargetType: "team", TargetID: team.ID}
+			assert.NoError(t, repos.Feedback.Create(&cascaded))
+			archived := models.Feedback{Content: "Archived", TargetType: "member", TargetID: member.ID}
+			assert.NoError(t, repos.Feedback.Create(&archived))
+
+			assert.NoError(t, repos.Teams.Delete(team.ID, TargetDeletion{Policy: FeedbackCascade}))
+			assert.NoError(t, repos.Members.Delete(member.ID, TargetDeletion{Policy: FeedbackArchive}))
+			assert.ErrorIs(t, repos.Members.Delete(member.ID, TargetDeletion{Policy: FeedbackArchive}), ErrNotFound)
+			_, err := repos.Teams.Get(team.ID)
+			assert.ErrorIs(t, err, ErrNotFound)
+			_, err = repos.Feedback.Get(cascaded.ID)
+			assert.ErrorIs(t, err, ErrNotFound)
+			_, total, err := repos.Members.List(MemberFilter{}, firstPage())
+			assert.NoError(t, err)
+			assert.Zero(t, total)
+
+			members, _, err := repos.Members.List(MemberFilter{IncludeDeleted: true}, firstPage())
+			assert.NoError(t, err)
+			if assert.Len(t, members, 1) {
+				assert.True(t, members[0].DeletedAt.Valid)
+			}
+			teams, _, err := repos.Teams.List(TeamFilter{IncludeDeleted: true}, firstPage())
+			assert.NoError(t, err)
+			assert.Len(t, teams, 1)
+			feedbacks, _, err := repos.Feedback.List(FeedbackFilter{IncludeDeleted: true}, firstPage())
+			assert.NoError(t, err)
+			assert.Len(t, feedbacks, 2)
+
+			// The email and name stay taken while the rows can be restored
+			assert.Error(t, repos.Members.Create(&models.TeamMember{Name: "Other", Email: "member@example.com"}))
+
+			_, err = repos.Feedback.Restore(cascaded.ID)
+			assert.ErrorIs(t, err, ErrTargetNotFound)
+			restoredTeam, err := repos.Teams.Restore(team.ID)
+			assert.NoError(t, err)
+			assert.Empty(t, restoredTeam.Members, "the memberships of deleted members stay hidden")
+			restoredMember, err := repos.Members.Restore(member.ID)
+			assert.NoError(t, err)
+			assert.False(t, restoredMember.DeletedAt.Valid)
+
+			restoredTeam, err = repos.Teams.Get(team.ID)
+			assert.NoError(t, err)
+			if assert.Len(t, restoredTeam.Members, 1) {
+				assert.Equal(t, member.ID, restoredTeam.Members[0].ID)
+			}
+			stored, err := repos.Feedback.Get(cascaded.ID)
+			assert.NoError(t, err)
+			assert.False(t, stored.DeletedAt.Valid)
+			stored, err = repos.Feedback.Get(archived.ID)
+			assert.NoError(t, err)
+			assert.Nil(t, stored.ArchivedAt)
+
+			_, err = repos.Members.Restore(member.ID)
+			assert.NoError(t, err, "restoring a member that is not deleted changes nothing")
+			_, err = repos.Members.Restore(99999)
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestPurge(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			members := []models.TeamMember{{Name: "Gone", Email: "gone@example.com"}, {Name: "Kept", Email: "kept@example.com"}}
+			for i := range members {
+				assert.NoError(t, repos.Members.Create(&members[i]))
+			}
+			team := models.Team{Name: "Team", Members: members}
+			assert.NoError(t, repos.Teams.Create(&team))
+			feedback := models.Feedback{Content: "Gone", TargetType: "member", TargetID: members[0].ID}
+			assert.NoError(t, repos.Feedback.Create(&feedback))
+			assert.NoError(t, repos.Members.Delete(members[0].ID, TargetDeletion{Policy: FeedbackCascade}))
+
+			purged, err := repos.Feedback.Purge(time.Now().Add(-time.Hour))
+			assert.NoError(t, err)
+			assert.Zero(t, purged, "rows deleted within the retention are kept")
+
+			before := time.Now().Add(time.Second)
+			purged, err = repos.Feedback.Purge(before)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), purged)
+			purged, err = repos.Members.Purge(before)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), purged)
+			purged, err = repos.Teams.Purge(before)
+			assert.NoError(t, err)
+			assert.Zero(t, purged)
+
+			_, err = repos.Members.Restore(members[0].ID)
+			assert.ErrorIs(t, err, ErrNotFound)
+			_, err = repos.Feedback.Restore(feedback.ID)
+			assert.ErrorIs(t, err, ErrNotFound)
+			stored, err := repos.Teams.Get(team.ID)
+			assert.NoError(t, err)
+			assert.Len(t, stored.Members, 1)
+			teams, _, err := repos.Teams.List(TeamFilter{MemberID: &members[0].ID}, firstPage())
+			assert.NoError(t, err)
+			assert.Empty(t, teams, "the memberships are purged with the member")
+		})
+	}
+}
+
+func TestMembershipPeriods(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			member := models.TeamMember{Name: "Member", Email: "member@example.com"}
+			assert.NoError(t, repos.Members.Create(&member))
+			team := models.Team{Name: "Team"}
+			assert.NoError(t, repos.Teams.Create(&team))
+			day := func(d int) time.Time { return time.Date(2024, time.January, d, 12, 0, 0, 0, time.UTC) }
+
+			joined := models.TeamAssignment{TeamID: team.ID, MemberID: member.ID, StartedAt: day(1)}
+			assert.NoError(t, repos.Teams.AddMember(&joined))
+			assert.Equal(t, models.TeamRoleMember, joined.Role)
+			assert.Equal(t, 100, joined.Allocation)
+			promoted := models.TeamAssignment{TeamID: team.ID, MemberID: member.ID, Role: models.TeamRoleLead, Allocation: 50, StartedAt: day(10)}
+			assert.NoError(t, repos.Teams.AddMember(&promoted))
+			leads, err := repos.Teams.ListLeadIDs(team.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, []uint64{member.ID}, leads, "an open lead assignment counts as leading the team")
+			assert.NoError(t, repos.Teams.RemoveMember(team.ID, member.ID, day(20)))
+			leads, err = repos.Teams.ListLeadIDs(team.ID)
+			assert.NoError(t, err)
+			assert.Empty(t, leads)
+			assert.NoError(t, repos.Teams.RemoveMember(team.ID, member.ID, day(25)), "removing a former member changes nothing")
+			rejoined := models.TeamAssignment{TeamID: team.ID, MemberID: member.ID, StartedAt: day(30)}
+			assert.NoError(t, repos.Teams.AddMember(&rejoined))
+
+			assert.ErrorIs(t, repos.Teams.AddMember(&models.TeamAssignment{TeamID: 99999, MemberID: member.ID}), ErrNotFound)
+
+			timeline, err := repos.Teams.ListAssignments(MembershipFilter{MemberID: &member.ID})
+			assert.NoError(t, err)
+			if assert.Len(t, timeline, 3) {
+				assert.Equal(t, day(10), timeline[0].EndedAt.UTC())
+				assert.Equal(t, models.TeamRoleLead, timeline[1].Role)
+				assert.Equal(t, day(20), timeline[1].EndedAt.UTC())
+				assert.Nil(t, timeline[2].EndedAt)
+				if assert.NotNil(t, timeline[2].Team) {
+					assert.Equal(t, "Team", timeline[2].Team.Name)
+				}
+			}
+
+			roster := func(at time.Time) []models.TeamAssignment {
+				assignments, err := repos.Teams.ListAssignments(MembershipFilter{TeamID: &team.ID, At: &at})
+				assert.NoError(t, err)
+				return assignments
+			}
+			if lead := roster(day(15)); assert.Len(t, lead, 1) {
+				assert.Equal(t, promoted.ID, lead[0].ID)
+				assert.Equal(t, "Member", lead[0].Member.Name)
+			}
+			assert.Len(t, roster(day(10)), 1, "periods end and start at the same time")
+			assert.Empty(t, roster(day(25)))
+			assert.Empty(t, roster(day(1).Add(-time.Hour)))
+
+			loaded, err := repos.Teams.Get(team.ID)
+			assert.NoError(t, err)
+			assert.Len(t, loaded.Members, 1)
+		})
+	}
+}
+
+func TestTeamHierarchy(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			tribe := models.Team{Name: "Tribe"}
+			assert.NoError(t, repos.Teams.Create(&tribe))
+			squads := []models.Team{{Name: "Squad B", ParentID: &tribe.ID}, {Name: "Squad A", ParentID: &tribe.ID}}
+			for i := range squads {
+				assert.NoError(t, repos.Teams.Create(&squads[i]))
+			}
+			guild := models.Team{Name: "Guild", ParentID: &squads[0].ID}
+			assert.NoError(t, repos.Teams.Create(&guild))
+			missing := uint64(99999)
+			assert.ErrorIs(t, repos.Teams.Create(&models.Team{Name: "Lost", ParentID: &missing}), ErrParentNotFound)
+
+			_, err := repos.Teams.Update(tribe.ID, models.Team{ParentID: &guild.ID})
+			assert.ErrorIs(t, err, ErrCycle)
+			_, err = repos.Teams.Update(tribe.ID, models.Team{ParentID: &tribe.ID})
+			assert.ErrorIs(t, err, ErrCycle)
+			_, err = repos.Teams.Update(tribe.ID, models.Team{ParentID: &missing})
+			assert.ErrorIs(t, err, ErrParentNotFound)
+
+			names := func(teams []models.Team) []string {
+				names := []string{}
+				for _, team := range teams {
+					names = append(names, team.Name)
+				}
+				return names
+			}
+			subtree, err := repos.Teams.ListSubtree(&tribe.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"Guild", "Squad A", "Squad B", "Tribe"}, names(subtree))
+			subtree, err = repos.Teams.ListSubtree(&squads[0].ID)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"Guild", "Squad B"}, names(subtree))
+			ancestors, err := repos.Teams.ListAncestors(guild.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"Squad B", "Tribe"}, names(ancestors))
+			// The ancestors stop at a deleted team, like the subtrees
+			assert.NoError(t, repos.Teams.Delete(squads[0].ID, TargetDeletion{Policy: FeedbackArchive}))
+			ancestors, err = repos.Teams.ListAncestors(guild.ID)
+			assert.NoError(t, err)
+			assert.Empty(t, ancestors)
+			_, err = repos.Teams.Restore(squads[0].ID)
+			assert.NoError(t, err)
+
+			// Moving the guild under the other squad, then to the top
+			moved, err := repos.Teams.Update(guild.ID, models.Team{ParentID: &squads[1].ID})
+			assert.NoError(t, err)
+			assert.Equal(t, squads[1].ID, *moved.ParentID)
+			zero := uint64(0)
+			moved, err = repos.Teams.Update(guild.ID, models.Team{ParentID: &zero})
+			assert.NoError(t, err)
+			assert.Nil(t, moved.ParentID)
+			ancestors, err = repos.Teams.ListAncestors(guild.ID)
+			assert.NoError(t, err)
+			assert.Empty(t, ancestors)
+
+			// The teams below a deleted team drop out of its subtree and become top-level when it is purged
+			assert.NoError(t, repos.Teams.Delete(tribe.ID, TargetDeletion{Policy: FeedbackArchive}))
+			subtree, err = repos.Teams.ListSubtree(&tribe.ID)
+			assert.NoError(t, err)
+			assert.Empty(t, subtree)
+			all, err := repos.Teams.ListSubtree(nil)
+			assert.NoError(t, err)
+			assert.Len(t, all, 3)
+			_, err = repos.Teams.Purge(time.Now().Add(time.Second))
+			assert.NoError(t, err)
+			squad, err := repos.Teams.Get(squads[0].ID)
+			assert.NoError(t, err)
+			assert.Nil(t, squad.ParentID)
+		})
+	}
+}
+
+func TestManagers(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			ceo := models.TeamMember{Name: "Ceo", Email: "ceo@example.com"}
+			assert.NoError(t, repos.Members.Create(&ceo))
+			vp := models.TeamMember{Name: "Vp", Email: "vp@example.com", ManagerID: &ceo.ID}
+			assert.NoError(t, repos.Members.Create(&vp))
+			dev := models.TeamMember{Name: "Dev", Email: "dev@example.com"}
+			assert.NoError(t, repos.Members.Create(&dev))
+			missing := uint64(99999)
+			assert.ErrorIs(t, repos.Members.Create(&models.TeamMember{Name: "Lost", Email: "lost@example.com", ManagerID: &missing}), ErrManagerNotFound)
+
+			updated, err := repos.Members.SetManager(dev.ID, &vp.ID, time.Now())
+			assert.NoError(t, err)
+			assert.Equal(t, vp.ID, *updated.ManagerID)
+			_, err = repos.Members.SetManager(ceo.ID, &dev.ID, time.Now())
+			assert.ErrorIs(t, err, ErrCycle)
+			_, err = repos.Members.SetManager(ceo.ID, &ceo.ID, time.Now())
+			assert.ErrorIs(t, err, ErrCycle)
+			_, err = repos.Members.SetManager(ceo.ID, &missing, time.Now())
+			assert.ErrorIs(t, err, ErrManagerNotFound)
+			_, err = repos.Members.SetManager(missing, &ceo.ID, time.Now())
+			assert.ErrorIs(t, err, ErrNotFound)
+			// Updates leave the manager alone
+			updated, err = repos.Members.Update(dev.ID, models.TeamMember{Name: "Developer", ManagerID: &ceo.ID})
+			assert.NoError(t, err)
+			assert.Equal(t, vp.ID, *updated.ManagerID)
+
+			names := func(members []models.TeamMember) []string {
+				names := []string{}
+				for _, member := range members {
+					names = append(names, member.Name)
+				}
+				return names
+			}
+			reports, err := repos.Members.ListReports(ceo.ID, false)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"Vp"}, names(reports))
+			reports, err = repos.Members.ListReports(ceo.ID, true)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"Developer", "Vp"}, names(reports))
+
+			// Deleting the vp moves the developer up to the ceo
+			assert.NoError(t, repos.Members.Delete(vp.ID, TargetDeletion{Policy: FeedbackArchive}))
+			reports, err = repos.Members.ListReports(ceo.ID, false)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"Developer"}, names(reports))
+			periods, err := repos.Members.ListManagerPeriods(dev.ID)
+			assert.NoError(t, err)
+			if assert.Len(t, periods, 2) {
+				assert.Equal(t, vp.ID, periods[0].ManagerID)
+				assert.NotNil(t, periods[0].EndedAt)
+				if assert.NotNil(t, periods[0].Manager) {
+					assert.Equal(t, "Vp", periods[0].Manager.Name)
+				}
+				assert.Equal(t, ceo.ID, periods[1].ManagerID)
+				assert.Nil(t, periods[1].EndedAt)
+			}
+
+			// Purging the ceo leaves the developer without a manager
+			updated, err = repos.Members.SetManager(dev.ID, nil, time.Now())
+			assert.NoError(t, err)
+			assert.Nil(t, updated.ManagerID)
+			updated, err = repos.Members.SetManager(dev.ID, &ceo.ID, time.Now())
+			assert.NoError(t, err)
+			assert.NoError(t, repos.Members.Delete(ceo.ID, TargetDeletion{Policy: FeedbackArchive}))
+			_, err = repos.Members.Purge(time.Now().Add(time.Second))
+			assert.NoError(t, err)
+			developer, err := repos.Members.Get(dev.ID)
+			assert.NoError(t, err)
+			assert.Nil(t, developer.ManagerID)
+			periods, err = repos.Members.ListManagerPeriods(dev.ID)
+			assert.NoError(t, err)
+			assert.Empty(t, periods)
+		})
+	}
+}
+
+func TestMoveAndBulkMemberships(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			from := models.Team{Name: "From"}
+			assert.NoError(t, repos.Teams.Create(&from))
+			to := models.Team{Name: "To"}
+			assert.NoError(t, repos.Teams.Create(&to))
+			var ids []uint64
+			for _, name := range []string{"Ann", "Ben", "Cid"} {
+				member := models.TeamMember{Name: name, Email: name + "@example.com"}
+				assert.NoError(t, repos.Members.Create(&member))
+				ids = append(ids, member.ID)
+			}
+			missing := uint64(99999)
+			onTeam := func(teamID uint64) int {
+				team, err := repos.Teams.Get(teamID)
+				assert.NoError(t, err)
+				return len(team.Members)
+			}
+
+			// A failed assignment is skipped, unless the change is atomic
+			assignments := []models.TeamAssignment{{TeamID: from.ID, MemberID: ids[0]}, {TeamID: from.ID, MemberID: missing}}
+			errs, err := repos.Teams.AddMembers(assignments, true)
+			assert.NoError(t, err)
+			assert.NoError(t, errs[0])
+			assert.ErrorIs(t, errs[1], ErrNotFound)
+			assert.Equal(t, 0, onTeam(from.ID))
+			errs, err = repos.Teams.AddMembers(assignments, false)
+			assert.NoError(t, err)
+			assert.ErrorIs(t, errs[1], ErrNotFound)
+			assert.Equal(t, 1, onTeam(from.ID))
+			errs, err = repos.Teams.AddMembers([]models.TeamAssignment{{TeamID: from.ID, MemberID: ids[1]}, {TeamID: from.ID, MemberID: ids[2], Role: models.TeamRoleLead}}, true)
+			assert.NoError(t, err)
+			assert.Equal(t, []error{nil, nil}, errs)
+			assert.Equal(t, 3, onTeam(from.ID))
+
+			// Moving closes one period and opens the other
+			assert.ErrorIs(t, repos.Teams.MoveMember(to.ID, &models.TeamAssignment{TeamID: from.ID, MemberID: ids[0]}), ErrNotAssigned)
+			assert.ErrorIs(t, repos.Teams.MoveMember(from.ID, &models.TeamAssignment{TeamID: missing, MemberID: ids[0]}), ErrNotFound)
+			assert.Equal(t, 3, onTeam(from.ID))
+			moved := models.TeamAssignment{TeamID: to.ID, MemberID: ids[0], Allocation: 50}
+			assert.NoError(t, repos.Teams.MoveMember(from.ID, &moved))
+			assert.Equal(t, 50, moved.Allocation)
+			assert.Equal(t, 2, onTeam(from.ID))
+			assert.Equal(t, 1, onTeam(to.ID))
+			periods, err := repos.Teams.ListAssignments(MembershipFilter{MemberID: &ids[0]})
+			assert.NoError(t, err)
+			if assert.Len(t, periods, 2) {
+				assert.Equal(t, from.ID, periods[0].TeamID)
+				assert.WithinDuration(t, moved.StartedAt, *periods[0].EndedAt, 0)
+				assert.Nil(t, periods[1].EndedAt)
+			}
+
+			// Removing members who are not on the team fails for them
+			errs, err = repos.Teams.RemoveMembers(from.ID, []uint64{ids[1], ids[0]}, time.Now(), true)
+			assert.NoError(t, err)
+			assert.NoError(t, errs[0])
+			assert.ErrorIs(t, errs[1], ErrNotAssigned)
+			assert.Equal(t, 2, onTeam(from.ID))
+			errs, err = repos.Teams.RemoveMembers(from.ID, []uint64{ids[1], ids[0]}, time.Now(), false)
+			assert.NoError(t, err)
+			assert.ErrorIs(t, errs[1], ErrNotAssigned)
+			assert.Equal(t, 1, onTeam(from.ID))
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"coaching-app/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrAlreadyAnswered is returned when a recipient answers a feedback request twice
+var ErrAlreadyAnswered = errors.New("feedback request already answered")
+
+type FeedbackRequestFilter struct {
+	RequesterID *uint64
+	// PendingFor selects the requests the member was asked and has not answered yet
+	PendingFor *uint64
+	// OpenAt selects the requests that have not expired at that time
+	OpenAt *time.Time
+}
+
+type FeedbackRequestRepository interface {
+	// Create stores the request with its Recipients
+	Create(request *models.FeedbackRequest) error
+	// Get returns the request with its Recipients
+	Get(id uint64) (*models.FeedbackRequest, error)
+	// List returns one page of requests with their Recipients
+	List(filter FeedbackRequestFilter, opts ListOptions) ([]models.FeedbackRequest, int64, error)
+	// Answer stores the feedback and marks the recipient as answered by it, or
+	// returns ErrAlreadyAnswered
+	Answer(recipient *models.FeedbackRequestRecipient, feedback *models.Feedback) error
+}
+
+type gormFeedbackRequestRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormFeedbackRequestRepository) Create(request *models.FeedbackRequest) error {
+	return r.db.Create(request).Error
+}
+
+func (r *gormFeedbackRequestRepository) Get(id uint64) (*models.FeedbackRequest, error) {
+	var request models.FeedbackRequest
+	if err := r.db.Preload("Recipients", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).First(&request, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &request, nil
+}
+
+func (r *gormFeedbackRequestRepository) List(filter FeedbackRequestFilter, opts ListOptions) ([]models.FeedbackRequest, int64, error) {
+	query := r.db.Model(&models.FeedbackRequest{})
+	if filter.RequesterID != nil {
+		query = query.Where("requester_id = ?", *filter.RequesterID)
+	}
+	if filter.PendingFor != nil {
+		pending := r.db.Table("feedback_request_recipients").Select("request_id").
+			Where("recipient_id = ? AND answered_at IS NULL", *filter.PendingFor)
+		query = query.Where("id IN (?)", pending)
+	}
+	if filter.OpenAt != nil {
+		query = query.Where("expires_at > ?", *filter.OpenAt)
+	}
+
+	var requests []models.FeedbackRequest
+	query = query.Preload("Recipients", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+	total, err := findPage(query, opts, &requests)
+	return requests, total, err
+}
+
+func (r *gormFeedbackRequestRepository) Answer(recipient *models.FeedbackRequestRecipient, feedback *models.Feedback) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := createFeedback(tx, feedback); err != nil {
+			return err
+		}
+
+		// The answered_at condition keeps two concurrent answers from both succeeding
+		now := time.Now()
+		result := tx.Model(&models.FeedbackRequestRecipient{}).
+			Where("id = ? AND answered_at IS NULL", recipient.ID).
+			Updates(map[string]any{"feedback_id": feedback.ID, "answered_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAlreadyAnswered
+		}
+
+		recipient.FeedbackID = &feedback.ID
+		recipient.AnsweredAt = &now
+		return nil
+	})
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"coaching-app/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrAlreadyCompleted is returned when feedback is submitted twice for the
+// same review assignment
+var ErrAlreadyCompleted = errors.New("review assignment already completed")
+
+type AssignmentFilter struct {
+	CycleID    uint64
+	ReviewerID *uint64
+	RevieweeID *uint64
+}
+
+type ReviewRepository interface {
+	// CreateCycle stores the cycle, its Participants and its assignments in one transaction
+	CreateCycle(cycle *models.ReviewCycle, assignments []models.ReviewAssignment) error
+	// GetCycle returns the cycle with its Participants
+	GetCycle(id uint64) (*models.ReviewCycle, error)
+	ListCycles(opts ListOptions) ([]models.ReviewCycle, int64, error)
+
+	ListAssignments(filter AssignmentFilter) ([]models.ReviewAssignment, error)
+	GetAssignment(cycleID, reviewerID, revieweeID uint64) (*models.ReviewAssignment, error)
+	// CompleteAssignment stores the feedback and marks the assignment as
+	// completed by it, or returns ErrAlreadyCompleted
+	CompleteAssignment(assignment *models.ReviewAssignment, feedback *models.Feedback) error
+}
+
+type gormReviewRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormReviewRepository) CreateCycle(cycle *models.ReviewCycle, assignments []models.ReviewAssignment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Participants are existing members: only the join rows are written
+		if err := tx.Omit("Participants.*").Create(cycle).Error; err != nil {
+			return err
+		}
+		if len(assignments) == 0 {
+			return nil
+		}
+		for i := range assignments {
+			assignments[i].CycleID = cycle.ID
+		}
+		return tx.Create(&assignments).Error
+	})
+}
+
+func (r *gormReviewRepository) GetCycle(id uint64) (*models.ReviewCycle, error) {
+	var cycle models.ReviewCycle
+	if err := r.db.Preload("Participants").First(&cycle, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &cycle, nil
+}
+
+func (r *gormReviewRepository) ListCycles(opts ListOptions) ([]models.ReviewCycle, int64, error) {
+	var cycles []models.ReviewCycle
+	total, err := findPage(r.db.Model(&models.ReviewCycle{}), opts, &cycles)
+	return cycles, total, err
+}
+
+func (r *gormReviewRepository) ListAssignments(filter AssignmentFilter) ([]models.ReviewAssignment, error) {
+	query := r.db.Where("cycle_id = ?", filter.CycleID)
+	if filter.ReviewerID != nil {
+		query = query.Where("reviewer_id = ?", *filter.ReviewerID)
+	}
+	if filter.RevieweeID != nil {
+		query = query.Where("reviewee_id = ?", *filter.RevieweeID)
+	}
+
+	assignments := []models.ReviewAssignment{}
+	err := query.Order("reviewee_id, reviewer_id").Find(&assignments).Error
+	return assignments, err
+}
+
+func (r *gormReviewRepository) GetAssignment(cycleID, reviewerID, revieweeID uint64) (*models.ReviewAssignment, error) {
+	var assignment models.ReviewAssignment
+	err := r.db.Where("cycle_id = ? AND reviewer_id = ? AND reviewee_id = ?", cycleID, reviewerID, revieweeID).
+		First(&assignment).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &assignment, nil
+}
+
+func (r *gormReviewRepository) CompleteAssignment(assignment *models.ReviewAssignment, feedback *models.Feedback) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := createFeedback(tx, feedback); err != nil {
+			return err
+		}
+
+		// The completed_at condition keeps two concurrent submissions from both succeeding
+		now := time.Now()
+		result := tx.Model(&models.ReviewAssignment{}).
+			Where("id = ? AND completed_at IS NULL", assignment.ID).
+			Updates(map[string]any{"feedback_id": feedback.ID, "completed_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAlreadyCompleted
+		}
+
+		assignment.FeedbackID = &feedback.ID
+		assignment.CompletedAt = &now
+		return nil
+	})
+}
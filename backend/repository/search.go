@@ -0,0 +1,354 @@
+package repository
+
+import (
+	"cmp"
+	"html"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"coaching-app/models"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of search hits
+const (
+	SearchFeedback = "feedback"
+	SearchMember   = "member"
+	SearchTeam     = "team"
+)
+
+// SearchKinds lists every kind of search hit
+var SearchKinds = []string{SearchFeedback, SearchMember, SearchTeam}
+
+const (
+	// snippetLength is the number of characters around the first match kept
+	// in a snippet, snippetContext of them before it
+	snippetLength  = 160
+	snippetContext = 40
+)
+
+// SearchQuery describes a full-text search. Words match when they start with
+// one of the terms of Text.
+type SearchQuery struct {
+	Text string
+	// Kinds restricts the search to some kinds of hits, all of them when empty
+	Kinds []string
+	// Feedback narrows down the feedback searched, including who may read it
+	Feedback FeedbackFilter
+	Limit    int
+}
+
+// SearchHit is a feedback entry, member or team matching a search. Snippet is
+// an HTML-escaped excerpt with the matching words wrapped in <mark> tags.
+type SearchHit struct {
+	Kind    string
+	ID      uint64
+	Snippet string
+	Score   float64
+}
+
+type SearchRepository interface {
+	// Search returns the Limit most relevant hits, best first
+	Search(query SearchQuery) ([]SearchHit, error)
+}
+
+// newSearchRepository picks the MySQL FULLTEXT search when available and the
+// in-process index otherwise
+func newSearchRepository(db *gorm.DB, feedback *gormFeedbackRepository) SearchRepository {
+	if db.Dialector.Name() == "mysql" {
+		return &fulltextSearchRepository{db: db, feedback: feedback}
+	}
+	return &indexSearchRepository{db: db, feedback: feedback}
+}
+
+// SearchTerms splits a search text into its distinct lower case words
+func SearchTerms(text string) []string {
+	terms := []string{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		if !slices.Contains(terms, word) {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func (q SearchQuery) wants(kind string) bool {
+	return len(q.Kinds) == 0 || slices.Contains(q.Kinds, kind)
+}
+
+// matchesTerm tells whether a lower case word starts with one of the terms
+func matchesTerm(word string, terms []string) bool {
+	return slices.ContainsFunc(terms, func(term string) bool { return strings.HasPrefix(word, term) })
+}
+
+// wordSpans returns the [start, end) rune offsets of the words of text
+func wordSpans(text []rune) [][2]int {
+	spans := [][2]int{}
+	start := -1
+	for i, r := range text {
+		if isSeparator(r) {
+			if start >= 0 {
+				spans = append(spans, [2]int{start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(text)})
+	}
+	return spans
+}
+
+// highlight cuts an excerpt of text around its first matching word and wraps
+// the matching words in <mark> tags, escaping the rest
+func highlight(text string, terms []string) string {
+	runes := []rune(text)
+	spans := wordSpans(runes)
+	matched := make([]bool, len(spans))
+	first := -1
+	for i, span := range spans {
+		if matchesTerm(strings.ToLower(string(runes[span[0]:span[1]])), terms) {
+			matched[i] = true
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+
+	start, end := 0, len(runes)
+	if len(runes) > snippetLength && len(spans) > 0 {
+		// Keep whole words, starting a little before the first match
+		from := 0
+		if first > 0 {
+			from = first
+			for from > 0 && spans[first][0]-spans[from-1][0] <= snippetContext {
+				from--
+			}
+		}
+		to := from
+		for to+1 < len(spans) && spans[to+1][1]-spans[from][0] <= snippetLength {
+			to++
+		}
+		start, end = spans[from][0], spans[to][1]
+	}
+
+	var snippet strings.Builder
+	if start > 0 {
+		snippet.WriteString("…")
+	}
+	position := start
+	for i, span := range spans {
+		if !matched[i] || span[0] < start || span[1] > end {
+			continue
+		}
+		snippet.WriteString(html.EscapeString(string(runes[position:span[0]])))
+		snippet.WriteString("<mark>" + html.EscapeString(string(runes[span[0]:span[1]])) + "</mark>")
+		position = span[1]
+	}
+	snippet.WriteString(html.EscapeString(string(runes[position:end])))
+	if end < len(runes) {
+		snippet.WriteString("…")
+	}
+	return snippet.String()
+}
+
+// bestHits orders hits by decreasing score and keeps the first limit of them
+func bestHits(hits []SearchHit, limit int) []SearchHit {
+	slices.SortStableFunc(hits, func(a, b SearchHit) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(slices.Index(SearchKinds, a.Kind), slices.Index(SearchKinds, b.Kind)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// searchRow is a candidate row with the text searched in it
+type searchRow struct {
+	ID    uint64
+	Text  string
+	Score float64
+}
+
+// fulltextSearchRepository relies on the MySQL FULLTEXT indexes of the
+// searched columns, in boolean mode so that terms match word prefixes
+type fulltextSearchRepository struct {
+	db       *gorm.DB
+	feedback *gormFeedbackRepository
+}
+
+func (r *fulltextSearchRepository) Search(query SearchQuery) ([]SearchHit, error) {
+	terms := SearchTerms(query.Text)
+	if len(terms) == 0 {
+		return []SearchHit{}, nil
+	}
+	// The terms only hold letters and digits, so they cannot carry boolean operators
+	against := strings.Join(terms, "* ") + "*"
+
+	sources := []struct {
+		kind  string
+		query *gorm.DB
+		match string
+		text  string
+	}{
+		{SearchFeedback, r.feedback.filtered(query.Feedback), "MATCH(content)", "content"},
+		{SearchMember, r.db.Model(&models.TeamMember{}), "MATCH(name, email)", "CONCAT(name, ' ', COALESCE(email, ''))"},
+		{SearchTeam, r.db.Model(&models.Team{}), "MATCH(name)", "name"},
+	}
+	hits := []SearchHit{}
+	for _, source := range sources {
+		if !query.wants(source.kind) {
+			continue
+		}
+		var rows []searchRow
+		err := source.query.
+			Select("id, "+source.text+" AS text, "+source.match+" AGAINST(? IN BOOLEAN MODE) AS score", against).
+			Where(source.match+" AGAINST(? IN BOOLEAN MODE)", against).
+			Order("score DESC").Limit(query.Limit).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			hits = append(hits, SearchHit{Kind: source.kind, ID: row.ID, Snippet: highlight(row.Text, terms), Score: row.Score})
+		}
+	}
+	return bestHits(hits, query.Limit), nil
+}
+
+// indexSearchRepository ranks the rows containing a term with an inverted
+// index built in process for each search, for databases without full-text
+// search such as SQLite
+type indexSearchRepository struct {
+	db       *gorm.DB
+	feedback *gormFeedbackRepository
+}
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+func (r *indexSearchRepository) Search(query SearchQuery) ([]SearchHit, error) {
+	terms := SearchTerms(query.Text)
+	if len(terms) == 0 {
+		return []SearchHit{}, nil
+	}
+
+	// The corpus of a kind is every row of it the caller may read, so that
+	// narrowing down a search does not change the scores and the feedback
+	// hidden from the caller does not weigh on them
+	sources := []struct {
+		kind   string
+		query  *gorm.DB
+		corpus *gorm.DB
+		text   string
+	}{
+		{SearchFeedback, r.feedback.filtered(query.Feedback), r.feedback.filtered(FeedbackFilter{Reader: query.Feedback.Reader}), "content"},
+		{SearchMember, r.db.Model(&models.TeamMember{}), r.db.Model(&models.TeamMember{}), "name || ' ' || COALESCE(email, '')"},
+		{SearchTeam, r.db.Model(&models.Team{}), r.db.Model(&models.Team{}), "name"},
+	}
+	hits := []SearchHit{}
+	for _, source := range sources {
+		if !query.wants(source.kind) {
+			continue
+		}
+		// Only the rows containing a term somewhere are worth indexing
+		containing := r.db
+		for i, term := range terms {
+			pattern := "%" + likeEscaper.Replace(term) + "%"
+			if i == 0 {
+				containing = containing.Where(source.text+" LIKE ? ESCAPE '!'", pattern)
+			} else {
+				containing = containing.Or(source.text+" LIKE ? ESCAPE '!'", pattern)
+			}
+		}
+		corpus := source.corpus.Session(&gorm.Session{})
+		var total int64
+		if err := corpus.Count(&total).Error; err != nil {
+			return nil, err
+		}
+		var candidates []searchRow
+		if err := corpus.Select("id, " + source.text + " AS text").Where(containing).Scan(&candidates).Error; err != nil {
+			return nil, err
+		}
+		var ids []uint64
+		if err := source.query.Where(containing).Pluck("id", &ids).Error; err != nil {
+			return nil, err
+		}
+		readable := map[uint64]bool{}
+		for _, id := range ids {
+			readable[id] = true
+		}
+		for _, row := range rankRows(candidates, terms, int(total)) {
+			if readable[row.ID] {
+				hits = append(hits, SearchHit{Kind: source.kind, ID: row.ID, Snippet: highlight(row.Text, terms), Score: row.Score})
+			}
+		}
+	}
+	return bestHits(hits, query.Limit), nil
+}
+
+// rankRows scores the rows with BM25 over an inverted index of their words and
+// returns those matching at least one term. The rows are those of a corpus of
+// total rows that contain a term; the others could not match and only count
+// towards the size of the corpus.
+func rankRows(rows []searchRow, terms []string, total int) []searchRow {
+	// postings maps each term to the number of matching words in each row
+	postings := map[string]map[int]int{}
+	lengths := make([]int, len(rows))
+	words := 0
+	for i, row := range rows {
+		rowWords := strings.FieldsFunc(strings.ToLower(row.Text), isSeparator)
+		lengths[i] = len(rowWords)
+		words += len(rowWords)
+		for _, word := range rowWords {
+			for _, term := range terms {
+				if strings.HasPrefix(word, term) {
+					if postings[term] == nil {
+						postings[term] = map[int]int{}
+					}
+					postings[term][i]++
+				}
+			}
+		}
+	}
+	if words == 0 {
+		return nil
+	}
+
+	total = max(total, len(rows))
+	average := float64(words) / float64(len(rows))
+	for _, counts := range postings {
+		idf := math.Log(1 + (float64(total)-float64(len(counts))+0.5)/(float64(len(counts))+0.5))
+		for i, count := range counts {
+			tf := float64(count)
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(lengths[i])/average)
+			rows[i].Score += idf * tf * (bm25K1 + 1) / (tf + norm)
+		}
+	}
+
+	matching := []searchRow{}
+	for _, row := range rows {
+		if row.Score > 0 {
+			matching = append(matching, row)
+		}
+	}
+	return matching
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"coaching-app/models"
+
+	"gorm.io/gorm"
+)
+
+type TemplateRepository interface {
+	// Create stores the template as version 1 of its Questions, or returns
+	// ErrDuplicate when the name is taken
+	Create(template *models.FeedbackTemplate) error
+	// Get returns the template with the questions of its current version
+	Get(id uint64) (*models.FeedbackTemplate, error)
+	// GetVersion returns the template with the questions of the given version
+	GetVersion(id uint64, version int) (*models.FeedbackTemplate, error)
+	// List returns the templates ordered by name, without their questions
+	List() ([]models.FeedbackTemplate, error)
+	// Update applies the given column changes and returns the updated template
+	Update(id uint64, changes map[string]any) (*models.FeedbackTemplate, error)
+	// Revise stores the questions as the next version of the template and
+	// returns the updated template
+	Revise(id uint64, questions []models.TemplateQuestion) (*models.FeedbackTemplate, error)
+}
+
+type gormTemplateRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormTemplateRepository) Create(template *models.FeedbackTemplate) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.FeedbackTemplate{}).Where("name = ?", template.Name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrDuplicate
+		}
+		template.Version = 1
+		if err := tx.Create(template).Error; err != nil {
+			return err
+		}
+		return createQuestions(tx, template)
+	})
+}
+
+// createQuestions stores the questions of the template at its current version
+func createQuestions(tx *gorm.DB, template *models.FeedbackTemplate) error {
+	for i := range template.Questions {
+		template.Questions[i].ID = 0
+		template.Questions[i].TemplateID = template.ID
+		template.Questions[i].Version = template.Version
+		template.Questions[i].Position = i + 1
+	}
+	if len(template.Questions) == 0 {
+		return nil
+	}
+	return tx.Create(&template.Questions).Error
+}
+
+func (r *gormTemplateRepository) Get(id uint64) (*models.FeedbackTemplate, error) {
+	return r.GetVersion(id, 0)
+}
+
+func (r *gormTemplateRepository) GetVersion(id uint64, version int) (*models.FeedbackTemplate, error) {
+	var template models.FeedbackTemplate
+	if err := r.db.First(&template, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	if version == 0 {
+		version = template.Version
+	} else if version > template.Version {
+		return nil, ErrNotFound
+	}
+
+	template.Questions = []models.TemplateQuestion{}
+	err := r.db.Where("template_id = ? AND version = ?", id, version).Order("position").Find(&template.Questions).Error
+	return &template, err
+}
+
+func (r *gormTemplateRepository) List() ([]models.FeedbackTemplate, error) {
+	templates := []models.FeedbackTemplate{}
+	err := r.db.Order("name").Find(&templates).Error
+	return templates, err
+}
+
+func (r *gormTemplateRepository) Update(id uint64, changes map[string]any) (*models.FeedbackTemplate, error) {
+	var template models.FeedbackTemplate
+	if err := r.db.First(&template, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	if err := r.db.Model(&template).Updates(changes).Error; err != nil {
+		return nil, err
+	}
+	return r.Get(id)
+}
+
+func (r *gormTemplateRepository) Revise(id uint64, questions []models.TemplateQuestion) (*models.FeedbackTemplate, error) {
+	var template models.FeedbackTemplate
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&template, id).Error; err != nil {
+			return translateError(err)
+		}
+		template.Version++
+		if err := tx.Model(&template).Update("version", template.Version).Error; err != nil {
+			return err
+		}
+		template.Questions = questions
+		return createQuestions(tx, &template)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"coaching-app/models"
+
+	"gorm.io/gorm"
+)
+
+// ThreadRepository stores the conversation around feedback: replies and reactions
+type ThreadRepository interface {
+	AddReply(reply *models.FeedbackReply) error
+	// ListReplies returns the replies to a feedback, oldest first
+	ListReplies(feedbackID uint64) ([]models.FeedbackReply, error)
+
+	// AddReaction stores the reaction, or returns ErrDuplicate when the member
+	// already reacted to the feedback with the same kind
+	AddReaction(reaction *models.FeedbackReaction) error
+	RemoveReaction(feedbackID, memberID uint64, kind string) error
+	// ListReactions returns the reactions to a feedback, oldest first
+	ListReactions(feedbackID uint64) ([]models.FeedbackReaction, error)
+}
+
+type gormThreadRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormThreadRepository) AddReply(reply *models.FeedbackReply) error {
+	return r.db.Create(reply).Error
+}
+
+func (r *gormThreadRepository) ListReplies(feedbackID uint64) ([]models.FeedbackReply, error) {
+	replies := []models.FeedbackReply{}
+	err := r.db.Where("feedback_id = ?", feedbackID).Order("id").Find(&replies).Error
+	return replies, err
+}
+
+func (r *gormThreadRepository) AddReaction(reaction *models.FeedbackReaction) error {
+	var count int64
+	if err := r.db.Model(&models.FeedbackReaction{}).
+		Where("feedback_id = ? AND member_id = ? AND kind = ?", reaction.FeedbackID, reaction.MemberID, reaction.Kind).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicate
+	}
+	return r.db.Create(reaction).Error
+}
+
+func (r *gormThreadRepository) RemoveReaction(feedbackID, memberID uint64, kind string) error {
+	result := r.db.Where("feedback_id = ? AND member_id = ? AND kind = ?", feedbackID, memberID, kind).
+		Delete(&models.FeedbackReaction{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormThreadRepository) ListReactions(feedbackID uint64) ([]models.FeedbackReaction, error) {
+	reactions := []models.FeedbackReaction{}
+	err := r.db.Where("feedback_id = ?", feedbackID).Order("id").Find(&reactions).Error
+	return reactions, err
+}
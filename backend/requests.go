@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultRequestLifetime is how long a feedback request stays open unless it
+// sets its own expiry
+const defaultRequestLifetime = 14 * 24 * time.Hour
+
+type feedbackRequestRequest struct {
+	RecipientIDs []uint64 `json:"recipientids"`
+	TeamID       *uint64  `json:"teamid"`
+	// IncludeManager also asks the manager of the caller
+	IncludeManager bool       `json:"includemanager"`
+	Question       string     `json:"question"`
+	ExpiresAt      *time.Time `json:"expiresat"`
+}
+
+// CreateFeedbackRequest asks members for feedback about the caller. The
+// recipients are listed one by one, taken from a team, the manager of the
+// caller, or any of them.
+func (s *Server) CreateFeedbackRequest(c *gin.Context) {
+	var req feedbackRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	requesterID := CurrentIdentity(c).MemberID
+	requester, err := s.members.Get(requesterID)
+	if err != nil {
+		respondLookupError(c, err, "Requester member not found", "Error finding requester member: ")
+		return
+	}
+
+	recipientIDs := slices.Clone(req.RecipientIDs)
+	for _, id := range recipientIDs {
+		if _, err := s.members.Get(id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Recipient %d not found", id)})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error finding recipient: " + err.Error()})
+			}
+			return
+		}
+	}
+	if req.TeamID != nil {
+		team, err := s.teams.Get(*req.TeamID)
+		if err != nil {
+			respondLookupError(c, err, "Team not found", "Error finding team: ")
+			return
+		}
+		for _, member := range team.Members {
+			recipientIDs = append(recipientIDs, member.ID)
+		}
+	}
+	if req.IncludeManager {
+		if requester.ManagerID == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You have no manager to ask"})
+			return
+		}
+		recipientIDs = append(recipientIDs, *requester.ManagerID)
+	}
+
+	// Nobody is asked twice, and nobody asks themselves
+	slices.Sort(recipientIDs)
+	recipientIDs = slices.Compact(recipientIDs)
+	recipientIDs = slices.DeleteFunc(recipientIDs, func(id uint64) bool { return id == requesterID })
+	if len(recipientIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A feedback request needs at least one recipient other than yourself"})
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(defaultRequestLifetime)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ExpiresAt must be in the future"})
+			return
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	request := models.FeedbackRequest{RequesterID: requesterID, Question: req.Question, ExpiresAt: expiresAt}
+	for _, id := range recipientIDs {
+		request.Recipients = append(request.Recipients, models.FeedbackRequestRecipient{RecipientID: id})
+	}
+	if err := s.requests.Create(&request); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create feedback request: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, request)
+}
+
+// GetMyFeedbackRequests lists one page of the open requests made by the caller,
+// sorted by created_at or expires_at
+func (s *Server) GetMyFeedbackRequests(c *gin.Context) {
+	callerID := CurrentIdentity(c).MemberID
+	now := time.Now()
+	s.respondFeedbackRequests(c, repository.FeedbackRequestFilter{RequesterID: &callerID, OpenAt: &now})
+}
+
+// GetOwedFeedbackRequests lists one page of the open requests the caller was
+// asked and has not answered yet, sorted by created_at or expires_at
+func (s *Server) GetOwedFeedbackRequests(c *gin.Context) {
+	callerID := CurrentIdentity(c).MemberID
+	now := time.Now()
+	s.respondFeedbackRequests(c, repository.FeedbackRequestFilter{PendingFor: &callerID, OpenAt: &now})
+}
+
+func (s *Server) respondFeedbackRequests(c *gin.Context, filter repository.FeedbackRequestFilter) {
+	opts, err := parseListParams(c, "created_at", "expires_at")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	requests, total, err := s.requests.List(filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feedback requests: " + err.Error()})
+		return
+	}
+	setPageHeaders(c, opts, total)
+	c.JSON(http.StatusOK, requests)
+}
+
+// GetFeedbackRequest retrieves a feedback request for its requester, its
+// recipients and admins
+func (s *Server) GetFeedbackRequest(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	request, err := s.requests.Get(id)
+	if err != nil {
+		respondLookupError(c, err, "Feedback request not found", "")
+		return
+	}
+	if !perms.Admin && request.RequesterID != perms.MemberID && recipientOf(request, perms.MemberID) == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not part of this feedback request"})
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+// recipientOf returns the recipient entry of the member in the request, or nil
+func recipientOf(request *models.FeedbackRequest, memberID uint64) *models.FeedbackRequestRecipient {
+	for i := range request.Recipients {
+		if request.Recipients[i].RecipientID == memberID {
+			return &request.Recipients[i]
+		}
+	}
+	return nil
+}
+
+// answerFeedbackRequest stores feedback given in answer to a request. It must be
+// about the requester, given by a recipient who has not answered yet, before
+// the request expires.
+func (s *Server) answerFeedbackRequest(c *gin.Context, feedback *models.Feedback, giverID uint64) {
+	if feedback.ReviewCycleID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Review feedback cannot answer a feedback request"})
+		return
+	}
+
+	request, err := s.requests.Get(*feedback.RequestID)
+	if err != nil {
+		respondLookupError(c, err, "Feedback request not found", "Error finding feedback request: ")
+		return
+	}
+	if feedback.TargetType != "member" || feedback.TargetID != request.RequesterID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "An answer to a feedback request must be about its requester"})
+		return
+	}
+	recipient := recipientOf(request, giverID)
+	if recipient == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You were not asked for this feedback"})
+		return
+	}
+	if request.Expired(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Feedback request has expired"})
+		return
+	}
+
+	if err := s.requests.Answer(recipient, feedback); err != nil {
+		if errors.Is(err, repository.ErrAlreadyAnswered) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You already answered this feedback request"})
+		} else {
+			respondCreateFeedbackError(c, err)
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, feedback)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"coaching-app/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedbackRequests(t *testing.T) {
+	setupTestDatabase()
+
+	requester := models.TeamMember{Name: "Requester", Email: "requester@example.com"}
+	alice := models.TeamMember{Name: "Alice", Email: "alice@example.com"}
+	bob := models.TeamMember{Name: "Bob", Email: "bob@example.com"}
+	outsider := models.TeamMember{Name: "Outsider", Email: "outsider@example.com"}
+	for _, member := range []*models.TeamMember{&requester, &alice, &bob, &outsider} {
+		testDB.Create(member)
+	}
+	team := models.Team{Name: "Platform", Members: []models.TeamMember{requester, bob}}
+	testDB.Create(&team)
+
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/feedback/requests", fmt.Sprintf(`{"recipientids": [%d]}`, requester.ID), requester.ID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/feedback/requests", `{"recipientids": [99999]}`, requester.ID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/feedback/requests", fmt.Sprintf(`{"recipientids": [%d], "expiresat": "2020-01-01T00:00:00Z"}`, alice.ID), requester.ID).Code)
+
+	// The team adds Bob; the requester is left out
+	payload := fmt.Sprintf(`{"recipientids": [%d, %d], "teamid": %d, "question": "How was my demo?"}`, alice.ID, bob.ID, team.ID)
+	w := serve("POST", "/feedback/requests", payload, requester.ID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var request models.FeedbackRequest
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &request))
+	assert.Len(t, request.Recipients, 2)
+	assert.WithinDuration(t, time.Now().Add(defaultRequestLifetime), request.ExpiresAt, time.Minute)
+
+	var requests []models.FeedbackRequest
+	w = serve("GET", "/feedback/requests/owed", "", alice.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &requests))
+	assert.Len(t, requests, 1)
+	w = serve("GET", "/feedback/requests/mine", "", requester.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &requests))
+	assert.Len(t, requests, 1)
+	assert.Equal(t, http.StatusForbidden, serve("GET", fmt.Sprintf("/feedback/requests/%d", request.ID), "", outsider.ID).Code)
+
+	answer := func(targetID, giverID uint64, extra string) int {
+		payload := fmt.Sprintf(`{"content": "Clear demo", "targettype": "member", "targetid": %d, "requestid": %d%s}`, targetID, request.ID, extra)
+		return serve("POST", "/feedback/", payload, giverID).Code
+	}
+	assert.Equal(t, http.StatusBadRequest, answer(bob.ID, alice.ID, ""))
+	assert.Equal(t, http.StatusForbidden, answer(requester.ID, outsider.ID, ""))
+	assert.Equal(t, http.StatusBadRequest, answer(requester.ID, alice.ID, `, "anonymous": true`))
+	assert.Equal(t, http.StatusCreated, answer(requester.ID, alice.ID, ""))
+	assert.Equal(t, http.StatusConflict, answer(requester.ID, alice.ID, ""))
+
+	w = serve("GET", "/feedback/requests/owed", "", alice.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &requests))
+	assert.Empty(t, requests)
+	w = serve("GET", fmt.Sprintf("/feedback/requests/%d", request.ID), "", requester.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &request))
+	if assert.NotNil(t, recipientOf(&request, alice.ID)) {
+		assert.NotNil(t, recipientOf(&request, alice.ID).FeedbackID)
+	}
+
+	var stored models.Feedback
+	testDB.Where("request_id = ?", request.ID).First(&stored)
+	assert.Equal(t, &alice.ID, stored.GiverID)
+
+	// Expired requests are neither owed nor answerable
+	testDB.Model(&models.FeedbackRequest{}).Where("id = ?", request.ID).Update("expires_at", time.Now().Add(-time.Hour))
+	w = serve("GET", "/feedback/requests/owed", "", bob.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &requests))
+	assert.Empty(t, requests)
+	assert.Equal(t, http.StatusConflict, answer(requester.ID, bob.ID, ""))
+}
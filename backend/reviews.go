@@ -0,0 +1,364 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type cycleRequest struct {
+	Name      string    `json:"name" binding:"required"`
+	StartsAt  time.Time `json:"startsat" binding:"required"`
+	EndsAt    time.Time `json:"endsat" binding:"required"`
+	TeamIDs   []uint64  `json:"teamids"`
+	MemberIDs []uint64  `json:"memberids"`
+}
+
+// ReviewProgress counts the assignments of a reviewee and how many are completed
+type ReviewProgress struct {
+	Assigned  int
+	Completed int
+}
+
+// ReviewSummary is the outcome of a review cycle for one member
+type ReviewSummary struct {
+	CycleID  uint64
+	MemberID uint64
+	ReviewProgress
+	ByRelationship map[string]ReviewProgress
+	Feedback       []models.Feedback
+}
+
+// relationshipRank decides which relationship is kept when a reviewer is
+// related to a reviewee in several ways
+var relationshipRank = map[string]int{
+	models.ReviewPeer: 1,
+	models.ReviewLead: 2,
+	models.ReviewSelf: 3,
+}
+
+// CreateReviewCycle opens a review cycle for the members of the given teams and
+// the given members, and generates their review assignments. Only admins can
+// create cycles.
+func (s *Server) CreateReviewCycle(c *gin.Context) {
+	if !s.requireAdmin(c) {
+		return
+	}
+
+	var req cycleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "EndsAt must be after StartsAt"})
+		return
+	}
+
+	participants := map[uint64]models.TeamMember{}
+	for _, teamID := range req.TeamIDs {
+		team, err := s.teams.Get(teamID)
+		if err != nil {
+			respondLookupError(c, err, "Team not found", "")
+			return
+		}
+		for _, member := range team.Members {
+			participants[member.ID] = member
+		}
+	}
+	for _, memberID := range req.MemberIDs {
+		member, err := s.members.Get(memberID)
+		if err != nil {
+			respondLookupError(c, err, "Team member not found", "")
+			return
+		}
+		participants[member.ID] = *member
+	}
+	if len(participants) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A review cycle needs at least one participant"})
+		return
+	}
+
+	assignments, err := s.planAssignments(participants)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to plan review assignments: " + err.Error()})
+		return
+	}
+
+	cycle := models.ReviewCycle{Name: req.Name, StartsAt: req.StartsAt, EndsAt: req.EndsAt}
+	// Tokens outlive members, so the creator is only recorded while it exists
+	if creator, err := s.members.Get(CurrentIdentity(c).MemberID); err == nil {
+		cycle.CreatedByID = &creator.ID
+	}
+	for _, member := range participants {
+		cycle.Participants = append(cycle.Participants, member)
+	}
+	slices.SortFunc(cycle.Participants, func(a, b models.TeamMember) int { return cmp.Compare(a.ID, b.ID) })
+
+	if err := s.reviews.CreateCycle(&cycle, assignments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review cycle: " + err.Error()})
+		return
+	}
+	cycle.Status = cycle.StatusAt(time.Now())
+	c.JSON(http.StatusCreated, cycle)
+}
+
+// planAssignments pairs every participant with themselves, with the other
+// participants of their teams and with the leads of their teams. A reviewer
+// related to a reviewee in several ways reviews them once, under the closest
+// relationship.
+func (s *Server) planAssignments(participants map[uint64]models.TeamMember) ([]models.ReviewAssignment, error) {
+	type pair struct{ reviewer, reviewee uint64 }
+	relationships := map[pair]string{}
+	relate := func(reviewer, reviewee uint64, relationship string) {
+		key := pair{reviewer, reviewee}
+		if relationshipRank[relationship] > relationshipRank[relationships[key]] {
+			relationships[key] = relationship
+		}
+	}
+
+	leadsOf := map[uint64][]uint64{}
+	for revieweeID := range participants {
+		relate(revieweeID, revieweeID, models.ReviewSelf)
+
+		teams, _, err := s.teams.List(repository.TeamFilter{MemberID: &revieweeID}, repository.ListOptions{Page: 1, PageSize: maxPageSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, team := range teams {
+			for _, member := range team.Members {
+				if _, ok := participants[member.ID]; ok && member.ID != revieweeID {
+					relate(member.ID, revieweeID, models.ReviewPeer)
+				}
+			}
+
+			leads, ok := leadsOf[team.ID]
+			if !ok {
+				if leads, err = s.teams.ListLeadIDs(team.ID); err != nil {
+					return nil, err
+				}
+				leadsOf[team.ID] = leads
+			}
+			for _, leadID := range leads {
+				if leadID != revieweeID {
+					relate(leadID, revieweeID, models.ReviewLead)
+				}
+			}
+		}
+	}
+
+	assignments := make([]models.ReviewAssignment, 0, len(relationships))
+	for key, relationship := range relationships {
+		assignments = append(assignments, models.ReviewAssignment{
+			ReviewerID:   key.reviewer,
+			RevieweeID:   key.reviewee,
+			Relationship: relationship,
+		})
+	}
+	slices.SortFunc(assignments, func(a, b models.ReviewAssignment) int {
+		return cmp.Or(cmp.Compare(a.RevieweeID, b.RevieweeID), cmp.Compare(a.ReviewerID, b.ReviewerID))
+	})
+	return assignments, nil
+}
+
+// GetReviewCycles retrieves one page of review cycles, sorted by name,
+// starts_at, ends_at or created_at
+func (s *Server) GetReviewCycles(c *gin.Context) {
+	opts, err := parseListParams(c, "name", "starts_at", "ends_at", "created_at")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cycles, total, err := s.reviews.ListCycles(opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve review cycles: " + err.Error()})
+		return
+	}
+	now := time.Now()
+	for i := range cycles {
+		cycles[i].Status = cycles[i].StatusAt(now)
+	}
+	setPageHeaders(c, opts, total)
+	c.JSON(http.StatusOK, cycles)
+}
+
+// GetReviewCycle retrieves a review cycle with its participants
+func (s *Server) GetReviewCycle(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	cycle, err := s.reviews.GetCycle(id)
+	if err != nil {
+		respondLookupError(c, err, "Review cycle not found", "")
+		return
+	}
+	cycle.Status = cycle.StatusAt(time.Now())
+	c.JSON(http.StatusOK, cycle)
+}
+
+// GetReviewAssignments lists the assignments of a cycle, optionally filtered by
+// reviewer_id and reviewee_id. Callers who cannot read all feedback only see
+// the assignments they have to write.
+func (s *Server) GetReviewAssignments(c *gin.Context) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.reviews.GetCycle(id); err != nil {
+		respondLookupError(c, err, "Review cycle not found", "")
+		return
+	}
+
+	filter := repository.AssignmentFilter{CycleID: id}
+	var err error
+	if filter.ReviewerID, err = optionalIDQuery(c, "reviewer_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.RevieweeID, err = optionalIDQuery(c, "reviewee_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !perms.ReadsAllFeedback() {
+		if filter.ReviewerID != nil && *filter.ReviewerID != perms.MemberID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only list your own review assignments"})
+			return
+		}
+		filter.ReviewerID = &perms.MemberID
+	}
+
+	assignments, err := s.reviews.ListAssignments(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve review assignments: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, assignments)
+}
+
+// GetReviewSummary reports the completion of the reviews about a member in a
+// cycle, with the feedback written for them that the caller may read. It is
+// available to the member, to their manager, to the leads of their teams, and
+// to admins and coaches.
+func (s *Server) GetReviewSummary(c *gin.Context) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	cycleID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := idParam(c, "member_id")
+	if !ok {
+		return
+	}
+
+	if _, err := s.reviews.GetCycle(cycleID); err != nil {
+		respondLookupError(c, err, "Review cycle not found", "")
+		return
+	}
+	if _, err := s.members.Get(memberID); err != nil {
+		respondLookupError(c, err, "Team member not found", "")
+		return
+	}
+
+	allowed := perms.ReadsAllFeedback() || perms.MemberID == memberID
+	if !allowed {
+		var err error
+		if allowed, err = s.managesMember(perms, memberID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot read the review summary of this member"})
+		return
+	}
+
+	assignments, err := s.reviews.ListAssignments(repository.AssignmentFilter{CycleID: cycleID, RevieweeID: &memberID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve review assignments: " + err.Error()})
+		return
+	}
+
+	summary := ReviewSummary{CycleID: cycleID, MemberID: memberID, ByRelationship: map[string]ReviewProgress{}}
+	for _, assignment := range assignments {
+		progress := summary.ByRelationship[assignment.Relationship]
+		progress.Assigned++
+		summary.Assigned++
+		if assignment.CompletedAt != nil {
+			progress.Completed++
+			summary.Completed++
+		}
+		summary.ByRelationship[assignment.Relationship] = progress
+	}
+
+	filter := repository.FeedbackFilter{
+		TargetType: "member",
+		TargetID:   &memberID,
+		CycleID:    &cycleID,
+		Reader:     perms.FeedbackReader(),
+	}
+	summary.Feedback, _, err = s.feedback.List(filter, repository.ListOptions{Page: 1, PageSize: maxPageSize, SortBy: "created_at"})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feedbacks: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// giveReviewFeedback stores feedback written for one of the caller's review
+// assignments and marks the assignment as completed
+func (s *Server) giveReviewFeedback(c *gin.Context, feedback *models.Feedback) {
+	if feedback.TargetType != "member" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Review feedback must target a member"})
+		return
+	}
+
+	cycle, err := s.reviews.GetCycle(*feedback.ReviewCycleID)
+	if err != nil {
+		respondLookupError(c, err, "Review cycle not found", "Error finding review cycle: ")
+		return
+	}
+	switch cycle.StatusAt(time.Now()) {
+	case models.CycleScheduled:
+		c.JSON(http.StatusConflict, gin.H{"error": "Review cycle has not started yet"})
+		return
+	case models.CycleLocked:
+		c.JSON(http.StatusConflict, gin.H{"error": "Review cycle is locked"})
+		return
+	}
+
+	assignment, err := s.reviews.GetAssignment(cycle.ID, *feedback.GiverID, feedback.TargetID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not assigned to review this member in this cycle"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error finding review assignment: " + err.Error()})
+		return
+	}
+
+	if err := s.reviews.CompleteAssignment(assignment, feedback); err != nil {
+		if errors.Is(err, repository.ErrAlreadyCompleted) {
+			c.JSON(http.StatusConflict, gin.H{"error": "This review has already been submitted"})
+		} else {
+			respondCreateFeedbackError(c, err)
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, feedback)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"coaching-app/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestCycle(t *testing.T, startsAt, endsAt time.Time, teamIDs, memberIDs []uint64) models.ReviewCycle {
+	payload, _ := json.Marshal(map[string]any{
+		"name": "H1 review", "startsat": startsAt, "endsat": endsAt, "teamids": teamIDs, "memberids": memberIDs,
+	})
+	w := serve("POST", "/cycles/", string(payload), testCallerID)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var cycle models.ReviewCycle
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &cycle))
+	return cycle
+}
+
+func TestReviewCycleAssignments(t *testing.T) {
+	setupTestDatabase()
+
+	alice := models.TeamMember{Name: "Alice", Email: "alice@example.com"}
+	bob := models.TeamMember{Name: "Bob", Email: "bob@example.com"}
+	lead := models.TeamMember{Name: "Lead", Email: "lead@example.com"}
+	dave := models.TeamMember{Name: "Dave", Email: "dave@example.com"}
+	testDB.Create(&alice)
+	testDB.Create(&bob)
+	testDB.Create(&lead)
+	testDB.Create(&dave)
+	platform := models.Team{Name: "Platform", Members: []models.TeamMember{alice, bob}}
+	mobile := models.Team{Name: "Mobile", Members: []models.TeamMember{dave}}
+	testDB.Create(&platform)
+	testDB.Create(&mobile)
+	testDB.Create(&models.MemberRole{MemberID: lead.ID, Role: models.RoleTeamLead, TeamID: &platform.ID})
+
+	w := serve("POST", "/cycles/", `{"name": "Nope", "startsat": "2024-01-01T00:00:00Z", "endsat": "2024-02-01T00:00:00Z", "memberids": [1]}`, alice.ID)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serve("POST", "/cycles/", `{"name": "Backwards", "startsat": "2024-02-01T00:00:00Z", "endsat": "2024-01-01T00:00:00Z", "memberids": [1]}`, testCallerID)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	now := time.Now()
+	cycle := createTestCycle(t, now.Add(-time.Hour), now.Add(time.Hour), []uint64{platform.ID}, []uint64{dave.ID})
+	assert.Equal(t, models.CycleOpen, cycle.Status)
+	assert.Len(t, cycle.Participants, 3)
+
+	var assignments []models.ReviewAssignment
+	getList(t, fmt.Sprintf("/cycles/%d/assignments", cycle.ID), &assignments)
+	type pair struct {
+		reviewer, reviewee uint64
+		relationship       string
+	}
+	var got []pair
+	for _, assignment := range assignments {
+		got = append(got, pair{assignment.ReviewerID, assignment.RevieweeID, assignment.Relationship})
+	}
+	assert.ElementsMatch(t, []pair{
+		{alice.ID, alice.ID, models.ReviewSelf},
+		{bob.ID, alice.ID, models.ReviewPeer},
+		{lead.ID, alice.ID, models.ReviewLead},
+		{bob.ID, bob.ID, models.ReviewSelf},
+		{alice.ID, bob.ID, models.ReviewPeer},
+		{lead.ID, bob.ID, models.ReviewLead},
+		{dave.ID, dave.ID, models.ReviewSelf},
+	}, got)
+
+	// Regular members only see what they have to write
+	w = serve("GET", fmt.Sprintf("/cycles/%d/assignments", cycle.ID), "", lead.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &assignments))
+	assert.Len(t, assignments, 2)
+	w = serve("GET", fmt.Sprintf("/cycles/%d/assignments?reviewer_id=%d", cycle.ID, alice.ID), "", lead.ID)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestReviewCycleFeedbackAndSummary(t *testing.T) {
+	setupTestDatabase()
+
+	alice := models.TeamMember{Name: "Alice", Email: "alice@example.com"}
+	bob := models.TeamMember{Name: "Bob", Email: "bob@example.com"}
+	outsider := models.TeamMember{Name: "Outsider", Email: "outsider@example.com"}
+	testDB.Create(&alice)
+	testDB.Create(&bob)
+	testDB.Create(&outsider)
+	team := models.Team{Name: "Platform", Members: []models.TeamMember{alice, bob}}
+	testDB.Create(&team)
+
+	now := time.Now()
+	cycle := createTestCycle(t, now.Add(-time.Hour), now.Add(time.Hour), []uint64{team.ID}, nil)
+	review := func(giverID uint64, cycleID uint64) int {
+		payload := fmt.Sprintf(`{"content": "Solid half", "targetid": %d, "targettype": "member", "reviewcycleid": %d}`, alice.ID, cycleID)
+		return serve("POST", "/feedback/", payload, giverID).Code
+	}
+
+	assert.Equal(t, http.StatusCreated, review(bob.ID, cycle.ID))
+	assert.Equal(t, http.StatusConflict, review(bob.ID, cycle.ID))
+	assert.Equal(t, http.StatusForbidden, review(outsider.ID, cycle.ID))
+	assert.Equal(t, http.StatusNotFound, review(bob.ID, 99999))
+
+	// Ad-hoc feedback is not linked to the cycle
+	serve("POST", "/feedback/", fmt.Sprintf(`{"content": "Ad hoc", "targetid": %d, "targettype": "member"}`, alice.ID), bob.ID)
+
+	var feedbacks []models.Feedback
+	getList(t, fmt.Sprintf("/feedback/?cycle_id=%d", cycle.ID), &feedbacks)
+	if assert.Len(t, feedbacks, 1) {
+		assert.Equal(t, cycle.ID, *feedbacks[0].ReviewCycleID)
+	}
+
+	w := serve("GET", fmt.Sprintf("/cycles/%d/summary/%d", cycle.ID, alice.ID), "", alice.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var summary ReviewSummary
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+	assert.Equal(t, 2, summary.Assigned)
+	assert.Equal(t, 1, summary.Completed)
+	assert.Equal(t, ReviewProgress{Assigned: 1, Completed: 1}, summary.ByRelationship[models.ReviewPeer])
+	assert.Equal(t, ReviewProgress{Assigned: 1, Completed: 0}, summary.ByRelationship[models.ReviewSelf])
+	assert.Len(t, summary.Feedback, 1)
+
+	w = serve("GET", fmt.Sprintf("/cycles/%d/summary/%d", cycle.ID, alice.ID), "", outsider.ID)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// A cycle past its deadline is locked
+	past := createTestCycle(t, now.Add(-48*time.Hour), now.Add(-24*time.Hour), []uint64{team.ID}, nil)
+	assert.Equal(t, models.CycleLocked, past.Status)
+	assert.Equal(t, http.StatusConflict, review(bob.ID, past.ID))
+}
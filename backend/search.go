@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// Search looks the q query parameter up in the content of the feedback the
+// caller may read and in the names and emails of members and teams, most
+// relevant first. types restricts the kinds of hits (feedback, member, team,
+// comma-separated), limit caps their number (20 by default), and the feedback
+// list filters narrow down the feedback searched.
+func (s *Server) Search(c *gin.Context) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+
+	text := c.Query("q")
+	if len(repository.SearchTerms(text)) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q must contain at least one word"})
+		return
+	}
+	query := repository.SearchQuery{Text: text, Limit: defaultSearchLimit}
+	if types := c.Query("types"); types != "" {
+		for _, kind := range strings.Split(types, ",") {
+			if !slices.Contains(repository.SearchKinds, kind) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid types. Must be among " + strings.Join(repository.SearchKinds, ", ") + "."})
+				return
+			}
+			query.Kinds = append(query.Kinds, kind)
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxSearchLimit)})
+			return
+		}
+		query.Limit = parsed
+	}
+	var ok bool
+	if query.Feedback, ok = s.feedbackFilterQuery(c, perms); !ok {
+		return
+	}
+
+	hits, err := s.search.Search(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, hits)
+}
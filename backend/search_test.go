@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	setupTestDatabase()
+
+	target := models.TeamMember{Name: "Target", Email: "target@example.com"}
+	giver := models.TeamMember{Name: "Giver", Email: "giver@example.com"}
+	outsider := models.TeamMember{Name: "Outsider", Email: "outsider@example.com"}
+	testDB.Create(&target)
+	testDB.Create(&giver)
+	testDB.Create(&outsider)
+	testDB.Create(&models.Team{Name: "Incident Response"})
+	shared := models.Feedback{Content: "Calm during the incident", TargetType: "member", TargetID: target.ID, GiverID: &giver.ID, Visibility: models.VisibilityRecipient}
+	private := models.Feedback{Content: "Incident notes to self", TargetType: "member", TargetID: target.ID, GiverID: &giver.ID, Visibility: models.VisibilityPrivate}
+	testDB.Create(&shared)
+	testDB.Create(&private)
+
+	search := func(path string, caller uint64) []repository.SearchHit {
+		w := serve("GET", path, "", caller)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var hits []repository.SearchHit
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &hits))
+		return hits
+	}
+	kinds := func(hits []repository.SearchHit) []string {
+		result := []string{}
+		for _, hit := range hits {
+			result = append(result, fmt.Sprintf("%s:%d", hit.Kind, hit.ID))
+		}
+		return result
+	}
+
+	// The private feedback only shows up for its giver
+	assert.Len(t, search("/search?q=incident", giver.ID), 3)
+	hits := search("/search?q=incident&types=feedback", target.ID)
+	assert.Equal(t, []string{fmt.Sprintf("feedback:%d", shared.ID)}, kinds(hits))
+	assert.Equal(t, "Calm during the <mark>incident</mark>", hits[0].Snippet)
+	assert.Empty(t, search("/search?q=incident&types=feedback", outsider.ID))
+	assert.Len(t, search(fmt.Sprintf("/search?q=incident&types=feedback&giver_id=%d", giver.ID), giver.ID), 2)
+
+	hits = search("/search?q=outs&types=member,team", target.ID)
+	assert.Equal(t, []string{fmt.Sprintf("member:%d", outsider.ID)}, kinds(hits))
+
+	assert.Equal(t, http.StatusBadRequest, serve("GET", "/search?q=%20", "", target.ID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("GET", "/search?q=incident&types=goal", "", target.ID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("GET", "/search?q=incident&limit=500", "", target.ID).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("GET", "/search?q=incident", "", 0).Code)
+}
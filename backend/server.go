@@ -0,0 +1,233 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"coaching-app/auth"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Server holds the dependencies of the HTTP handlers
+type Server struct {
+	members      repository.MemberRepository
+	teams        repository.TeamRepository
+	feedback     repository.FeedbackRepository
+	reviews      repository.ReviewRepository
+	competencies repository.CompetencyRepository
+	goals        repository.GoalRepository
+	oneOnOnes    repository.OneOnOneRepository
+	threads      repository.ThreadRepository
+	requests     repository.FeedbackRequestRepository
+	templates    repository.TemplateRepository
+	search       repository.SearchRepository
+	tokens       *auth.TokenIssuer
+	settings     Settings
+}
+
+// Settings holds the behaviour of the server that is configured through the environment
+type Settings struct {
+	// AnonymityKey keys the hashes that stand in for the givers of anonymous feedback
+	AnonymityKey []byte
+	// MinAnonymousGivers is the number of distinct givers below which team
+	// aggregates are suppressed
+	MinAnonymousGivers int
+	// FeedbackEditWindow is how long after giving feedback its author can
+	// still edit or retract it
+	FeedbackEditWindow time.Duration
+	// FeedbackOnTargetDelete is the default repository.TargetDeletion policy
+	// applied to the feedback about a deleted member or team, archive when empty
+	FeedbackOnTargetDelete string
+}
+
+func NewServer(repos repository.Repositories, tokens *auth.TokenIssuer, settings Settings) *Server {
+	return &Server{
+		members:      repos.Members,
+		teams:        repos.Teams,
+		feedback:     repos.Feedback,
+		reviews:      repos.Reviews,
+		competencies: repos.Competencies,
+		goals:        repos.Goals,
+		oneOnOnes:    repos.OneOnOnes,
+		threads:      repos.Threads,
+		requests:     repos.Requests,
+		templates:    repos.Templates,
+		search:       repos.Search,
+		tokens:       tokens,
+		settings:     settings,
+	}
+}
+
+func (s *Server) RegisterRoutes(router *gin.Engine) {
+	// Authentication routes
+	authRoutes := router.Group("/auth")
+	{
+		authRoutes.POST("/login", s.Login)
+		authRoutes.GET("/me", s.AuthRequired(), s.GetCurrentMember)
+	}
+
+	// TeamMember routes
+	memberRoutes := router.Group("/members", s.AuthRequired())
+	{
+		memberRoutes.POST("/", s.CreateTeamMember)
+		memberRoutes.GET("/", s.GetTeamMembers)
+		memberRoutes.GET("/:id", s.GetTeamMember)
+		memberRoutes.PUT("/:id", s.UpdateTeamMember)
+		memberRoutes.DELETE("/:id", s.DeleteTeamMember)
+		memberRoutes.POST("/:id/restore", s.RestoreTeamMember)
+		memberRoutes.PUT("/:id/password", s.SetMemberPassword)
+		memberRoutes.GET("/:id/roles", s.GetMemberRoles)
+		memberRoutes.POST("/:id/roles", s.GrantMemberRole)
+		memberRoutes.DELETE("/:id/roles/:role_id", s.RevokeMemberRole)
+		memberRoutes.GET("/:id/scores", s.GetMemberScores)
+		memberRoutes.GET("/:id/goals", s.GetMemberGoals)
+		memberRoutes.GET("/:id/tags", s.GetMemberTagCounts)
+		memberRoutes.GET("/:id/timeline", s.GetMemberTimeline)
+		memberRoutes.PUT("/:id/manager", s.SetMemberManager)
+		memberRoutes.GET("/:id/managers", s.GetMemberManagers)
+		memberRoutes.GET("/:id/reports", s.GetMemberReports)
+	}
+
+	// Team routes
+	teamRoutes := router.Group("/teams", s.AuthRequired())
+	{
+		teamRoutes.POST("/", s.CreateTeam)
+		teamRoutes.GET("/", s.GetTeams)
+		teamRoutes.GET("/orgchart", s.GetOrgChart)
+		teamRoutes.GET("/:id", s.GetTeam)
+		teamRoutes.PUT("/:id", s.UpdateTeam)
+		teamRoutes.DELETE("/:id", s.DeleteTeam)
+		teamRoutes.POST("/:id/restore", s.RestoreTeam)
+
+		// Move team-member assignment routes to avoid conflict
+		// Use a different path structure
+		teamRoutes.POST("/:id/assign/:member_id", s.AssignMemberToTeam)
+		teamRoutes.DELETE("/:id/remove/:member_id", s.RemoveMemberFromTeam)
+		teamRoutes.POST("/:id/move/:member_id", s.MoveMemberToTeam)
+		teamRoutes.POST("/:id/assign", s.AssignMembersToTeam)
+		teamRoutes.POST("/:id/remove", s.RemoveMembersFromTeam)
+		teamRoutes.GET("/:id/scores", s.GetTeamScores)
+		teamRoutes.GET("/:id/goals", s.GetTeamGoals)
+		teamRoutes.GET("/:id/tags", s.GetTeamTagCounts)
+		teamRoutes.GET("/:id/timeline", s.GetTeamTimeline)
+		teamRoutes.GET("/:id/roster", s.GetTeamRoster)
+		teamRoutes.GET("/:id/subtree", s.GetTeamSubtree)
+		teamRoutes.GET("/:id/ancestors", s.GetTeamAncestors)
+	}
+
+	// Feedback routes
+	feedbackRoutes := router.Group("/feedback", s.AuthRequired())
+	{
+		feedbackRoutes.POST("/", s.GiveFeedback)
+		feedbackRoutes.GET("/", s.GetFeedbacks)
+		feedbackRoutes.GET("/tags", s.GetTags)
+		feedbackRoutes.POST("/requests", s.CreateFeedbackRequest)
+		feedbackRoutes.GET("/requests/mine", s.GetMyFeedbackRequests)
+		feedbackRoutes.GET("/requests/owed", s.GetOwedFeedbackRequests)
+		feedbackRoutes.GET("/requests/:id", s.GetFeedbackRequest)
+		feedbackRoutes.PUT("/:id", s.UpdateFeedback)
+		feedbackRoutes.DELETE("/:id", s.RetractFeedback)
+		feedbackRoutes.POST("/:id/restore", s.RestoreFeedback)
+		feedbackRoutes.GET("/:id/history", s.GetFeedbackHistory)
+		feedbackRoutes.PUT("/:id/visibility", s.SetFeedbackVisibility)
+		feedbackRoutes.GET("/:id/visibility", s.GetFeedbackVisibilityChanges)
+		feedbackRoutes.POST("/:id/acknowledge", s.AcknowledgeFeedback)
+		feedbackRoutes.POST("/:id/replies", s.AddFeedbackReply)
+		feedbackRoutes.GET("/:id/replies", s.GetFeedbackReplies)
+		feedbackRoutes.POST("/:id/reactions", s.AddFeedbackReaction)
+		feedbackRoutes.GET("/:id/reactions", s.GetFeedbackReactions)
+		feedbackRoutes.DELETE("/:id/reactions/:kind", s.RemoveFeedbackReaction)
+	}
+
+	// Competency framework routes
+	competencyRoutes := router.Group("/competencies", s.AuthRequired())
+	{
+		competencyRoutes.POST("/", s.CreateCompetency)
+		competencyRoutes.GET("/", s.GetCompetencies)
+		competencyRoutes.PUT("/:id", s.UpdateCompetency)
+	}
+
+	// Feedback template routes
+	templateRoutes := router.Group("/templates", s.AuthRequired())
+	{
+		templateRoutes.POST("/", s.CreateTemplate)
+		templateRoutes.GET("/", s.GetTemplates)
+		templateRoutes.GET("/:id", s.GetTemplate)
+		templateRoutes.PUT("/:id", s.UpdateTemplate)
+		templateRoutes.GET("/:id/versions/:version", s.GetTemplateVersion)
+	}
+
+	// Search routes
+	router.GET("/search", s.AuthRequired(), s.Search)
+
+	// Goal routes
+	goalRoutes := router.Group("/goals", s.AuthRequired())
+	{
+		goalRoutes.POST("/", s.CreateGoal)
+		goalRoutes.GET("/", s.GetGoals)
+		goalRoutes.GET("/:id", s.GetGoal)
+		goalRoutes.PUT("/:id", s.UpdateGoal)
+		goalRoutes.DELETE("/:id", s.DeleteGoal)
+		goalRoutes.POST("/:id/keyresults", s.AddKeyResult)
+		goalRoutes.PUT("/:id/keyresults/:kr_id", s.UpdateKeyResult)
+		goalRoutes.DELETE("/:id/keyresults/:kr_id", s.DeleteKeyResult)
+		goalRoutes.POST("/:id/checkins", s.AddGoalCheckIn)
+		goalRoutes.GET("/:id/checkins", s.GetGoalCheckIns)
+	}
+
+	// One-on-one routes
+	oneOnOneRoutes := router.Group("/oneonones", s.AuthRequired())
+	{
+		oneOnOneRoutes.POST("/", s.CreateOneOnOne)
+		oneOnOneRoutes.GET("/", s.GetOneOnOnes)
+		oneOnOneRoutes.GET("/:id", s.GetOneOnOne)
+		oneOnOneRoutes.PUT("/:id", s.UpdateOneOnOne)
+		oneOnOneRoutes.POST("/:id/meetings", s.CreateMeeting)
+		oneOnOneRoutes.GET("/:id/meetings", s.GetMeetings)
+		oneOnOneRoutes.GET("/:id/meetings/:meeting_id", s.GetMeeting)
+		oneOnOneRoutes.PUT("/:id/meetings/:meeting_id", s.UpdateMeeting)
+		oneOnOneRoutes.POST("/:id/meetings/:meeting_id/agenda", s.AddAgendaItem)
+		oneOnOneRoutes.POST("/:id/meetings/:meeting_id/agenda/feedback", s.PullFeedbackIntoAgenda)
+		oneOnOneRoutes.PUT("/:id/meetings/:meeting_id/agenda/:item_id", s.UpdateAgendaItem)
+		oneOnOneRoutes.POST("/:id/meetings/:meeting_id/notes", s.AddMeetingNote)
+		oneOnOneRoutes.PUT("/:id/meetings/:meeting_id/notes/:note_id", s.UpdateMeetingNote)
+		oneOnOneRoutes.POST("/:id/meetings/:meeting_id/actions", s.AddActionItem)
+		oneOnOneRoutes.GET("/:id/actions", s.GetActionItems)
+		oneOnOneRoutes.PUT("/:id/actions/:action_id", s.UpdateActionItem)
+	}
+
+	// Review cycle routes
+	cycleRoutes := router.Group("/cycles", s.AuthRequired())
+	{
+		cycleRoutes.POST("/", s.CreateReviewCycle)
+		cycleRoutes.GET("/", s.GetReviewCycles)
+		cycleRoutes.GET("/:id", s.GetReviewCycle)
+		cycleRoutes.GET("/:id/assignments", s.GetReviewAssignments)
+		cycleRoutes.GET("/:id/summary/:member_id", s.GetReviewSummary)
+	}
+}
+
+// idParam reads a numeric path parameter. It answers 400 and returns false
+// when the parameter is not a valid ID.
+func idParam(c *gin.Context, name string) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondLookupError answers 404 with notFound when err is repository.ErrNotFound
+// and 500 with errorPrefix and the error otherwise
+func respondLookupError(c *gin.Context, err error, notFound, errorPrefix string) {
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	} else {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorPrefix + err.Error()})
+	}
+}
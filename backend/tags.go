@@ -0,0 +1,175 @@
+package main
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxTagLength      = 50
+	maxTagsPerEntry   = 10
+	defaultTagMatches = 10
+	maxTagMatches     = 50
+)
+
+// TagCount is how many of the feedback entries the caller may read carry a tag
+type TagCount struct {
+	Tag   string
+	Count int64
+	// Suppressed is set instead of the count when too few givers used the tag
+	Suppressed bool
+}
+
+// normalizeTag trims a tag name and puts it in lower case
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTags normalizes and deduplicates the tags of new feedback. It
+// answers 400 and returns false when a tag is empty or too long, or when there
+// are too many.
+func normalizeTags(c *gin.Context, feedback *models.Feedback) bool {
+	tags := []models.Tag{}
+	for _, tag := range feedback.Tags {
+		name := normalizeTag(tag.Name)
+		if name == "" || utf8.RuneCountInString(name) > maxTagLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tags must have between 1 and " + strconv.Itoa(maxTagLength) + " characters"})
+			return false
+		}
+		if !slices.ContainsFunc(tags, func(t models.Tag) bool { return t.Name == name }) {
+			tags = append(tags, models.Tag{Name: name})
+		}
+	}
+	if len(tags) > maxTagsPerEntry {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Feedback can have at most " + strconv.Itoa(maxTagsPerEntry) + " tags"})
+		return false
+	}
+	feedback.Tags = tags
+	return true
+}
+
+// validCategory answers 400 and returns false unless category is a known feedback category
+func validCategory(c *gin.Context, category string) bool {
+	if !slices.Contains(models.FeedbackCategories, category) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Category. Must be one of " + strings.Join(models.FeedbackCategories, ", ") + "."})
+		return false
+	}
+	return true
+}
+
+// tagsQuery reads the comma-separated tags query parameter
+func tagsQuery(c *gin.Context) []string {
+	var tags []string
+	for _, name := range strings.Split(c.Query("tags"), ",") {
+		if name = normalizeTag(name); name != "" {
+			tags = append(tags, name)
+		}
+	}
+	return tags
+}
+
+// GetTags suggests the existing tags starting with the prefix query parameter,
+// at most limit of them (10 by default). Only the tags of the feedback the
+// caller may read are suggested.
+func (s *Server) GetTags(c *gin.Context) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	limit := defaultTagMatches
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxTagMatches {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxTagMatches)})
+			return
+		}
+		limit = parsed
+	}
+
+	tags, err := s.feedback.ListTags(normalizeTag(c.Query("prefix")), limit, perms.FeedbackReader())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tags: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// GetMemberTagCounts counts the tags of the feedback addressed to a member
+func (s *Server) GetMemberTagCounts(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.members.Get(id); err != nil {
+		respondLookupError(c, err, "Team member not found", "")
+		return
+	}
+	s.respondTagCounts(c, repository.FeedbackFilter{TargetType: "member", TargetID: &id}, 0)
+}
+
+// GetTeamTagCounts counts the tags of the feedback addressed to a team
+func (s *Server) GetTeamTagCounts(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.teams.Get(id); err != nil {
+		respondLookupError(c, err, "Team not found", "")
+		return
+	}
+	teamIDs, ok := s.rollupQuery(c, id)
+	if !ok {
+		return
+	}
+	s.respondTagCounts(c, repository.FeedbackFilter{TargetType: "team", TargetIDs: teamIDs}, s.settings.MinAnonymousGivers)
+}
+
+// respondTagCounts answers with the number of feedback entries per tag, most
+// used first, optionally limited by category and by a from/to creation date
+// range. Only the feedback the caller may read is counted. The counts of the
+// tags used by fewer than minGivers distinct givers are left out.
+func (s *Server) respondTagCounts(c *gin.Context, filter repository.FeedbackFilter, minGivers int) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	filter.Reader = perms.FeedbackReader()
+
+	if filter.Category = c.Query("category"); filter.Category != "" && !validCategory(c, filter.Category) {
+		return
+	}
+	var err error
+	if filter.CreatedFrom, filter.CreatedBefore, err = dateRangeQuery(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	counts, err := s.feedback.CountTags(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tags: " + err.Error()})
+		return
+	}
+
+	result := make([]TagCount, 0, len(counts))
+	for _, count := range counts {
+		if count.Givers < int64(minGivers) {
+			result = append(result, TagCount{Tag: count.Tag, Suppressed: true})
+		} else {
+			result = append(result, TagCount{Tag: count.Tag, Count: count.Count})
+		}
+	}
+	// The suppressed tags go last, as if they were never used
+	slices.SortStableFunc(result, func(a, b TagCount) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+	c.JSON(http.StatusOK, result)
+}
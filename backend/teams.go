@@ -0,0 +1,302 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Team CRUD operations
+
+type teamCreateRequest struct {
+	Name     string  `json:"name"`
+	LogoURL  string  `json:"logourl"`
+	ParentID *uint64 `json:"parentid"`
+}
+
+// CreateTeam creates a team, under the team given by ParentID if any. Only
+// admins can place a team under another. Members join it with
+// AssignMemberToTeam.
+func (s *Server) CreateTeam(c *gin.Context) {
+	var req teamCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate required fields
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Team name is required"})
+		return
+	}
+	if req.ParentID != nil && *req.ParentID != 0 && !s.requireAdmin(c) {
+		return
+	}
+
+	team := models.Team{Name: req.Name, LogoURL: req.LogoURL, ParentID: req.ParentID}
+
+	if err := s.teams.Create(&team); err != nil {
+		if respondParentError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create team: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, team)
+}
+
+// GetTeams lists teams one page at a time with the members of the teams on that
+// page. They can be filtered by name prefix and member_id membership and sorted by
+// name or created_at. Admins can list the deleted teams too with include_deleted.
+func (s *Server) GetTeams(c *gin.Context) {
+	opts, err := parseListParams(c, "name", "created_at")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := repository.TeamFilter{NamePrefix: c.Query("name")}
+	if filter.MemberID, err = optionalIDQuery(c, "member_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var ok bool
+	if filter.IncludeDeleted, ok = s.includeDeletedQuery(c); !ok {
+		return
+	}
+
+	teams, total, err := s.teams.List(filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	setPageHeaders(c, opts, total)
+	c.JSON(http.StatusOK, teams)
+}
+
+func (s *Server) GetTeam(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	team, err := s.teams.Get(id)
+	if err != nil {
+		respondLookupError(c, err, "Team not found", "")
+		return
+	}
+	c.JSON(http.StatusOK, team)
+}
+
+type teamUpdateRequest struct {
+	Name     string  `json:"name"`
+	LogoURL  string  `json:"logourl"`
+	ParentID *uint64 `json:"parentid"`
+}
+
+// UpdateTeam changes the name, logo or parent of a team. A ParentID of 0 makes
+// it a top-level team; a team cannot be placed under itself or a team below it.
+// Only admins and the leads of the team can change it, and only admins can
+// change the parent.
+func (s *Server) UpdateTeam(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := s.teams.Get(id); err != nil {
+		respondLookupError(c, err, "Team not found", "")
+		return
+	}
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	if !perms.LeadsTeam(id) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and leads of this team can change it"})
+		return
+	}
+
+	var req teamUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The hierarchy decides what rolls up where, so only admins change it
+	if req.ParentID != nil && !s.requireAdmin(c) {
+		return
+	}
+
+	team, err := s.teams.Update(id, models.Team{Name: req.Name, LogoURL: req.LogoURL, ParentID: req.ParentID})
+	if err != nil {
+		if respondParentError(c, err) {
+			return
+		}
+		respondLookupError(c, err, "Team not found", "")
+		return
+	}
+	c.JSON(http.StatusOK, team) // Return the updated team
+}
+
+// DeleteTeam soft-deletes a team, keeping its memberships until it is restored
+// or purged. Only admins can delete teams. The feedback about the team is
+// archived, deleted or reassigned, see targetDeletionQuery.
+func (s *Server) DeleteTeam(c *gin.Context) {
+	if !s.requireAdmin(c) {
+		return
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	deletion, ok := s.targetDeletionQuery(c)
+	if !ok {
+		return
+	}
+	if err := s.teams.Delete(id, deletion); err != nil {
+		if errors.Is(err, repository.ErrTargetNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reassign_to must be another existing team"})
+			return
+		}
+		respondLookupError(c, err, "Team not found", "")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// RestoreTeam brings back a deleted team with its memberships and the feedback
+// about it that was archived or deleted along with it. Only admins can restore
+// teams.
+func (s *Server) RestoreTeam(c *gin.Context) {
+	if !s.requireAdmin(c) {
+		return
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	team, err := s.teams.Restore(id)
+	if err != nil {
+		respondLookupError(c, err, "Team not found", "")
+		return
+	}
+	c.JSON(http.StatusOK, team)
+}
+
+// lookupTeamAndMember loads the team and member of an assignment route, answering
+// 404 when either does not exist
+func (s *Server) lookupTeamAndMember(c *gin.Context) (*models.Team, *models.TeamMember, bool) {
+	teamID, ok := idParam(c, "id")
+	if !ok {
+		return nil, nil, false
+	}
+	memberID, ok := idParam(c, "member_id")
+	if !ok {
+		return nil, nil, false
+	}
+
+	team, err := s.teams.Get(teamID)
+	if err != nil {
+		respondLookupError(c, err, "Team not found", "Error finding team: ")
+		return nil, nil, false
+	}
+
+	member, err := s.members.Get(memberID)
+	if err != nil {
+		respondLookupError(c, err, "Team member not found", "Error finding member: ")
+		return nil, nil, false
+	}
+	return team, member, true
+}
+
+type assignmentRequest struct {
+	Role       string `json:"role"`
+	Allocation *int   `json:"allocation"`
+}
+
+// validate returns why the role or allocation of an assignment is invalid, or
+// an empty string when they are valid or left out
+func (r assignmentRequest) validate() string {
+	if r.Role != "" && !slices.Contains(models.TeamRoles, r.Role) {
+		return "Invalid role. Must be one of " + strings.Join(models.TeamRoles, ", ") + "."
+	}
+	if r.Allocation != nil && (*r.Allocation < 1 || *r.Allocation > 100) {
+		return "allocation must be between 1 and 100"
+	}
+	return ""
+}
+
+// assignment builds the assignment of the member to the team, leaving the
+// allocation for the repository to default when it was left out
+func (r assignmentRequest) assignment(teamID, memberID uint64) models.TeamAssignment {
+	assignment := models.TeamAssignment{TeamID: teamID, MemberID: memberID, Role: r.Role}
+	if r.Allocation != nil {
+		assignment.Allocation = *r.Allocation
+	}
+	return assignment
+}
+
+// AssignMemberToTeam assigns a member to a team, opening an assignment period.
+// The optional body sets the role of the member in the team (member or lead,
+// member by default) and their allocation in percent (100 by default).
+// Assigning a member already on the team with another role or allocation
+// closes their current period. Only leads of the team can assign members.
+func (s *Server) AssignMemberToTeam(c *gin.Context) {
+	team, member, ok := s.lookupTeamAndMember(c)
+	if !ok {
+		return
+	}
+	if !s.requireTeamLead(c, team.ID) {
+		return
+	}
+
+	// The body is optional
+	var req assignmentRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if invalid := req.validate(); invalid != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalid})
+		return
+	}
+
+	assignment := req.assignment(team.ID, member.ID)
+	if err := s.teams.AddMember(&assignment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign member to team: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member assigned to team successfully"})
+}
+
+// RemoveMemberFromTeam removes a member from a team, closing their assignment
+// period. Only leads of the team can remove members.
+func (s *Server) RemoveMemberFromTeam(c *gin.Context) {
+	team, member, ok := s.lookupTeamAndMember(c)
+	if !ok {
+		return
+	}
+	if !s.requireTeamLead(c, team.ID) {
+		return
+	}
+
+	if err := s.teams.RemoveMember(team.ID, member.ID, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member from team: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed from team successfully"})
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type questionRequest struct {
+	Prompt    string   `json:"prompt"`
+	Kind      string   `json:"kind"`
+	Required  bool     `json:"required"`
+	MinRating int      `json:"minrating"`
+	MaxRating int      `json:"maxrating"`
+	Options   []string `json:"options"`
+}
+
+type templateRequest struct {
+	Name        string            `json:"name" binding:"required"`
+	Description string            `json:"description"`
+	Questions   []questionRequest `json:"questions"`
+}
+
+type templateUpdateRequest struct {
+	Name        *string           `json:"name"`
+	Description *string           `json:"description"`
+	Active      *bool             `json:"active"`
+	Questions   []questionRequest `json:"questions"`
+}
+
+// newQuestions validates the questions of a template version. It answers 400
+// and returns false when they are invalid. Rating scales default to 1-5.
+func newQuestions(c *gin.Context, reqs []questionRequest) ([]models.TemplateQuestion, bool) {
+	if len(reqs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A template needs at least one question"})
+		return nil, false
+	}
+	questions := make([]models.TemplateQuestion, 0, len(reqs))
+	for i, req := range reqs {
+		question := models.TemplateQuestion{Prompt: strings.TrimSpace(req.Prompt), Kind: req.Kind, Required: req.Required}
+		if question.Prompt == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Question %d needs a prompt", i+1)})
+			return nil, false
+		}
+		switch req.Kind {
+		case models.QuestionText:
+		case models.QuestionRating:
+			question.MinRating, question.MaxRating = defaultMinScore, defaultMaxScore
+			if req.MinRating != 0 || req.MaxRating != 0 {
+				question.MinRating, question.MaxRating = req.MinRating, req.MaxRating
+			}
+			if question.MinRating >= question.MaxRating {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Question %d: MinRating must be lower than MaxRating", i+1)})
+				return nil, false
+			}
+		case models.QuestionChoice:
+			for _, option := range req.Options {
+				option = strings.TrimSpace(option)
+				if option != "" && !slices.Contains(question.Options, option) {
+					question.Options = append(question.Options, option)
+				}
+			}
+			if len(question.Options) < 2 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Question %d needs at least two distinct options", i+1)})
+				return nil, false
+			}
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Question %d: Kind must be one of %s", i+1, strings.Join(models.QuestionKinds, ", "))})
+			return nil, false
+		}
+		questions = append(questions, question)
+	}
+	return questions, true
+}
+
+// CreateTemplate defines a feedback template with its ordered questions. Only
+// admins manage templates.
+func (s *Server) CreateTemplate(c *gin.Context) {
+	if !s.requireAdmin(c) {
+		return
+	}
+
+	var req templateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	questions, ok := newQuestions(c, req.Questions)
+	if !ok {
+		return
+	}
+
+	template := models.FeedbackTemplate{Name: req.Name, Description: req.Description, Active: true, Questions: questions}
+	if err := s.templates.Create(&template); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A template with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, template)
+}
+
+// GetTemplates lists the templates, including deactivated ones, without their questions
+func (s *Server) GetTemplates(c *gin.Context) {
+	templates, err := s.templates.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve templates: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate retrieves a template with the questions of its current version
+func (s *Server) GetTemplate(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	template, err := s.templates.Get(id)
+	if err != nil {
+		respondLookupError(c, err, "Template not found", "")
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// GetTemplateVersion retrieves a template with the questions of one of its
+// versions, to interpret the answers given against it
+func (s *Server) GetTemplateVersion(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return
+	}
+	template, err := s.templates.GetVersion(id, version)
+	if err != nil {
+		respondLookupError(c, err, "Template version not found", "")
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// UpdateTemplate renames, describes, deactivates or reactivates a template.
+// New questions replace the current ones as the next version of the template.
+func (s *Server) UpdateTemplate(c *gin.Context) {
+	if !s.requireAdmin(c) {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req templateUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	changes := map[string]any{}
+	if req.Name != nil {
+		if *req.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Name cannot be empty"})
+			return
+		}
+		changes["name"] = *req.Name
+	}
+	if req.Description != nil {
+		changes["description"] = *req.Description
+	}
+	if req.Active != nil {
+		changes["active"] = *req.Active
+	}
+	var questions []models.TemplateQuestion
+	if req.Questions != nil {
+		if questions, ok = newQuestions(c, req.Questions); !ok {
+			return
+		}
+	}
+
+	template, err := s.templates.Update(id, changes)
+	if err == nil && questions != nil {
+		template, err = s.templates.Revise(id, questions)
+	}
+	if err != nil {
+		respondLookupError(c, err, "Template not found", "Failed to update template: ")
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// applyTemplate checks the answers of new feedback against the current version
+// of its template and writes them out in its Content, after whatever the giver
+// wrote. It answers and returns false when the answers are invalid.
+func (s *Server) applyTemplate(c *gin.Context, feedback *models.Feedback) bool {
+	feedback.TemplateVersion = nil
+	if feedback.TemplateID == nil {
+		if len(feedback.Answers) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Answers require a TemplateID"})
+			return false
+		}
+		return true
+	}
+
+	template, err := s.templates.Get(*feedback.TemplateID)
+	if err != nil {
+		respondLookupError(c, err, "Template not found", "Error finding template: ")
+		return false
+	}
+	if !template.Active {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Template " + template.Name + " is not active"})
+		return false
+	}
+
+	answers := map[uint64]models.FeedbackAnswer{}
+	for _, answer := range feedback.Answers {
+		i := slices.IndexFunc(template.Questions, func(q models.TemplateQuestion) bool { return q.ID == answer.QuestionID })
+		if i < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Question %d is not part of the current version of the template", answer.QuestionID)})
+			return false
+		}
+		if _, seen := answers[answer.QuestionID]; seen {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Question %d is answered twice", answer.QuestionID)})
+			return false
+		}
+		if !validAnswer(c, template.Questions[i], &answer) {
+			return false
+		}
+		answers[answer.QuestionID] = answer
+	}
+
+	lines := []string{}
+	if content := strings.TrimSpace(feedback.Content); content != "" {
+		lines = append(lines, content)
+	}
+	feedback.Answers = nil
+	for _, question := range template.Questions {
+		answer, ok := answers[question.ID]
+		if !ok {
+			if question.Required {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Question '" + question.Prompt + "' is required"})
+				return false
+			}
+			continue
+		}
+		feedback.Answers = append(feedback.Answers, answer)
+		lines = append(lines, question.Prompt+"\n"+formatAnswer(question, answer))
+	}
+	feedback.Content = strings.Join(lines, "\n\n")
+	feedback.TemplateVersion = &template.Version
+	return true
+}
+
+// validAnswer checks an answer against its question, keeping only the field
+// the question kind uses. It answers 400 and returns false when it is invalid.
+func validAnswer(c *gin.Context, question models.TemplateQuestion, answer *models.FeedbackAnswer) bool {
+	answer.ID = 0
+	answer.Text = strings.TrimSpace(answer.Text)
+	switch question.Kind {
+	case models.QuestionRating:
+		answer.Text = ""
+		if answer.Rating == nil || *answer.Rating < question.MinRating || *answer.Rating > question.MaxRating {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("'%s' needs a rating between %d and %d", question.Prompt, question.MinRating, question.MaxRating)})
+			return false
+		}
+	case models.QuestionChoice:
+		answer.Rating = nil
+		if !slices.Contains(question.Options, answer.Text) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("'%s' must be answered with one of %s", question.Prompt, strings.Join(question.Options, ", "))})
+			return false
+		}
+	default:
+		answer.Rating = nil
+		if answer.Text == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("'%s' needs a text answer", question.Prompt)})
+			return false
+		}
+	}
+	return true
+}
+
+// formatAnswer writes an answer out for the Content of its feedback
+func formatAnswer(question models.TemplateQuestion, answer models.FeedbackAnswer) string {
+	if question.Kind == models.QuestionRating {
+		return fmt.Sprintf("%d/%d", *answer.Rating, question.MaxRating)
+	}
+	return answer.Text
+}
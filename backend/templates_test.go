@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"coaching-app/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManageTemplates(t *testing.T) {
+	setupTestDatabase()
+
+	member := models.TeamMember{Name: "Member", Email: "member@example.com"}
+	testDB.Create(&member)
+
+	payload := `{"name": "Post-incident", "questions": [
+		{"prompt": "What went well?", "kind": "text", "required": true},
+		{"prompt": "Communication", "kind": "rating"},
+		{"prompt": "Would you pair again?", "kind": "choice", "options": ["Yes", "No"]}]}`
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/templates/", payload, member.ID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/templates/", `{"name": "Empty"}`, testCallerID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/templates/", `{"name": "Bad", "questions": [{"prompt": "Pick", "kind": "choice", "options": ["Only"]}]}`, testCallerID).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/templates/", `{"name": "Bad", "questions": [{"prompt": "Essay", "kind": "essay"}]}`, testCallerID).Code)
+
+	w := serve("POST", "/templates/", payload, testCallerID)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var template models.FeedbackTemplate
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &template))
+	assert.Equal(t, 1, template.Version)
+	if !assert.Len(t, template.Questions, 3) {
+		return
+	}
+	assert.Equal(t, 5, template.Questions[1].MaxRating)
+	assert.Equal(t, http.StatusConflict, serve("POST", "/templates/", payload, testCallerID).Code)
+
+	// New questions make a new version; the old one stays readable
+	w = serve("PUT", fmt.Sprintf("/templates/%d", template.ID), `{"questions": [{"prompt": "What should change?", "kind": "text"}]}`, testCallerID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var revised models.FeedbackTemplate
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &revised))
+	assert.Equal(t, 2, revised.Version)
+	assert.Len(t, revised.Questions, 1)
+
+	var first models.FeedbackTemplate
+	w = serve("GET", fmt.Sprintf("/templates/%d/versions/1", template.ID), "", member.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+	assert.Equal(t, template.Questions, first.Questions)
+	assert.Equal(t, http.StatusNotFound, serve("GET", fmt.Sprintf("/templates/%d/versions/3", template.ID), "", member.ID).Code)
+
+	var templates []models.FeedbackTemplate
+	w = serve("GET", "/templates/", "", member.ID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &templates))
+	assert.Len(t, templates, 1)
+}
+
+func TestGiveFeedbackWithTemplate(t *testing.T) {
+	setupTestDatabase()
+
+	giver := models.TeamMember{Name: "Giver", Email: "giver@example.com"}
+	target := models.TeamMember{Name: "Target", Email: "target@example.com"}
+	testDB.Create(&giver)
+	testDB.Create(&target)
+
+	var template models.FeedbackTemplate
+	w := serve("POST", "/templates/", `{"name": "Project end", "questions": [
+		{"prompt": "Highlights", "kind": "text", "required": true},
+		{"prompt": "Ownership", "kind": "rating", "minrating": 1, "maxrating": 4},
+		{"prompt": "Scope", "kind": "choice", "options": ["Too small", "Right", "Too big"]}]}`, testCallerID)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &template))
+	if !assert.Len(t, template.Questions, 3) {
+		return
+	}
+	highlights, ownership, scope := template.Questions[0].ID, template.Questions[1].ID, template.Questions[2].ID
+
+	give := func(answers string) *models.Feedback {
+		payload := fmt.Sprintf(`{"content": "Thanks for the project.", "targetid": %d, "targettype": "member", "templateid": %d, "answers": %s}`, target.ID, template.ID, answers)
+		w := serve("POST", "/feedback/", payload, giver.ID)
+		if w.Code != http.StatusCreated {
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			return nil
+		}
+		var feedback models.Feedback
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &feedback))
+		return &feedback
+	}
+	assert.Nil(t, give(fmt.Sprintf(`[{"questionid": %d, "rating": 3}]`, ownership)))
+	assert.Nil(t, give(fmt.Sprintf(`[{"questionid": %d, "text": "Docs"}, {"questionid": %d, "rating": 5}]`, highlights, ownership)))
+	assert.Nil(t, give(fmt.Sprintf(`[{"questionid": %d, "text": "Docs"}, {"questionid": %d, "text": "Huge"}]`, highlights, scope)))
+	assert.Nil(t, give(fmt.Sprintf(`[{"questionid": %d, "text": "Docs"}, {"questionid": 99999, "text": "?"}]`, highlights)))
+
+	feedback := give(fmt.Sprintf(`[{"questionid": %d, "rating": 3}, {"questionid": %d, "text": "The migration plan"}, {"questionid": %d, "text": "Right"}]`, ownership, highlights, scope))
+	if assert.NotNil(t, feedback) {
+		assert.Equal(t, "Thanks for the project.\n\nHighlights\nThe migration plan\n\nOwnership\n3/4\n\nScope\nRight", feedback.Content)
+		assert.Equal(t, &template.Version, feedback.TemplateVersion)
+		assert.Len(t, feedback.Answers, 3)
+	}
+
+	var feedbacks []models.Feedback
+	getList(t, "/feedback/", &feedbacks)
+	if assert.Len(t, feedbacks, 1) {
+		assert.Len(t, feedbacks[0].Answers, 3)
+		// Editing the text would leave the answers behind
+		assert.Equal(t, http.StatusBadRequest, serve("PUT", fmt.Sprintf("/feedback/%d", feedbacks[0].ID), `{"content": "Rewritten"}`, giver.ID).Code)
+	}
+
+	assert.Equal(t, http.StatusOK, serve("PUT", fmt.Sprintf("/templates/%d", template.ID), `{"active": false}`, testCallerID).Code)
+	assert.Nil(t, give(fmt.Sprintf(`[{"questionid": %d, "text": "Docs"}]`, highlights)))
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"coaching-app/models"
+	"coaching-app/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readableFeedbackParam loads the feedback named by the :id path parameter,
+// answering 400 or 404 and returning nil when it cannot. Feedback the caller
+// may not read is reported as not found.
+func (s *Server) readableFeedbackParam(c *gin.Context, perms *Permissions) *models.Feedback {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return nil
+	}
+	filter := repository.FeedbackFilter{IDs: []uint64{id}, Reader: perms.FeedbackReader()}
+	feedbacks, _, err := s.feedback.List(filter, repository.ListOptions{Page: 1, PageSize: 1})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feedback: " + err.Error()})
+		return nil
+	}
+	if len(feedbacks) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feedback not found"})
+		return nil
+	}
+	return &feedbacks[0]
+}
+
+// isRecipient reports whether the member is the target of the feedback or a
+// member of its target team
+func (s *Server) isRecipient(memberID uint64, feedback *models.Feedback) (bool, error) {
+	if feedback.TargetType == "member" {
+		return feedback.TargetID == memberID, nil
+	}
+	team, err := s.teams.Get(feedback.TargetID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(team.Members, func(m models.TeamMember) bool { return m.ID == memberID }), nil
+}
+
+// AcknowledgeFeedback marks a feedback entry as read by its recipient. For team
+// feedback, the first member of the team to acknowledge it does so for the team.
+// Acknowledging twice keeps the first acknowledgement.
+func (s *Server) AcknowledgeFeedback(c *gin.Context) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	feedback := s.readableFeedbackParam(c, perms)
+	if feedback == nil {
+		return
+	}
+	recipient, err := s.isRecipient(perms.MemberID, feedback)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load the recipient: " + err.Error()})
+		return
+	}
+	if !recipient {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the recipient can acknowledge feedback"})
+		return
+	}
+
+	acknowledged, err := s.feedback.Acknowledge(feedback.ID, perms.MemberID, time.Now())
+	if err != nil {
+		respondLookupError(c, err, "Feedback not found", "Failed to acknowledge feedback: ")
+		return
+	}
+	// The entry was read through the reader filter: keep what it hides hidden
+	feedback.AcknowledgedAt, feedback.AcknowledgedByID = acknowledged.AcknowledgedAt, acknowledged.AcknowledgedByID
+	c.JSON(http.StatusOK, feedback)
+}
+
+type replyRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// AddFeedbackReply adds a message to the conversation about a feedback entry.
+// Anyone who can read the feedback can reply to it.
+func (s *Server) AddFeedbackReply(c *gin.Context) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	feedback := s.readableFeedbackParam(c, perms)
+	if feedback == nil {
+		return
+	}
+
+	var req replyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Content must not be blank"})
+		return
+	}
+
+	reply := models.FeedbackReply{FeedbackID: feedback.ID, Content: req.Content}
+	if author, err := s.members.Get(perms.MemberID); err == nil {
+		reply.AuthorID = &author.ID
+	}
+	if err := s.threads.AddReply(&reply); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add reply: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, reply)
+}
+
+// GetFeedbackReplies lists the replies to a feedback entry, oldest first
+func (s *Server) GetFeedbackReplies(c *gin.Context) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	feedback := s.readableFeedbackParam(c, perms)
+	if feedback == nil {
+		return
+	}
+
+	replies, err := s.threads.ListReplies(feedback.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve replies: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, replies)
+}
+
+type reactionRequest struct {
+	Kind string `json:"kind" binding:"required"`
+}
+
+// validReaction answers 400 and returns false unless kind is a known reaction
+func validReaction(c *gin.Context, kind string) bool {
+	if !slices.Contains(models.ReactionKinds, kind) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Kind. Must be one of " + strings.Join(models.ReactionKinds, ", ") + "."})
+		return false
+	}
+	return true
+}
+
+// AddFeedbackReaction reacts to a feedback entry on behalf of the caller
+func (s *Server) AddFeedbackReaction(c *gin.Context) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	feedback := s.readableFeedbackParam(c, perms)
+	if feedback == nil {
+		return
+	}
+
+	var req reactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validReaction(c, req.Kind) {
+		return
+	}
+
+	reaction := models.FeedbackReaction{FeedbackID: feedback.ID, MemberID: perms.MemberID, Kind: req.Kind}
+	if err := s.threads.AddReaction(&reaction); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You already reacted this way"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add reaction: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, reaction)
+}
+
+// RemoveFeedbackReaction withdraws a reaction of the caller
+func (s *Server) RemoveFeedbackReaction(c *gin.Context) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	feedback := s.readableFeedbackParam(c, perms)
+	if feedback == nil {
+		return
+	}
+
+	if err := s.threads.RemoveReaction(feedback.ID, perms.MemberID, c.Param("kind")); err != nil {
+		respondLookupError(c, err, "Reaction not found", "Failed to remove reaction: ")
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetFeedbackReactions lists the reactions to a feedback entry, oldest first
+func (s *Server) GetFeedbackReactions(c *gin.Context) {
+	perms := s.loadPermissions(c)
+	if perms == nil {
+		return
+	}
+	feedback := s.readableFeedbackParam(c, perms)
+	if feedback == nil {
+		return
+	}
+
+	reactions, err := s.threads.ListReactions(feedback.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reactions: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reactions)
+}
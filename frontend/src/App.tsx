@@ -1,11 +1,12 @@
-import React from 'react';
+import React, { useState } from 'react';
 import { BrowserRouter as Router, Routes, Route, Link } from 'react-router-dom';
-import { AddTeamMember, CreateTeam, AssignToTeam, GiveFeedback, ListFeedbacks, TeamManagement } from './pages'; // Added ListFeedbacks and TeamManagement
+import { AddTeamMember, CreateTeam, AssignToTeam, GiveFeedback, ListFeedbacks, TeamManagement, Login } from './pages'; // Added ListFeedbacks and TeamManagement
+import { getToken, clearToken } from './api';
 import logo from '/logo-app.png'; // Import the logo
 import { ToastContainer } from 'react-toastify';
 import 'react-toastify/dist/ReactToastify.css';
 
-const AppNav: React.FC = () => (
+const AppNav: React.FC<{ onLogout: () => void }> = ({ onLogout }) => (
   <nav style={{ marginBottom: '2rem', backgroundColor: '#f0f0f0', padding: '1rem', display: 'flex', alignItems: 'center' }}>
     <img src={logo} alt="App Logo" style={{ height: '40px', marginRight: '20px' }} />
     <ul style={{ listStyle: 'none', display: 'flex', flexGrow: 1, justifyContent: 'space-around', margin: 0, padding: 0 }}>
@@ -15,14 +16,25 @@ const AppNav: React.FC = () => (
       <li><Link to="/give-feedback">Give Feedback</Link></li>
       <li><Link to="/feedbacks">View Feedbacks</Link></li> {/* Added link to View Feedbacks */}
       <li><Link to="/team-management">Team Management</Link></li> {/* Added link to Team Management */}
+      <li><a href="/login" onClick={(e) => { e.preventDefault(); onLogout(); }}>Log Out</a></li>
     </ul>
   </nav>
 );
 
 function App() {
+  const [loggedIn, setLoggedIn] = useState<boolean>(getToken() !== null);
+
+  if (!loggedIn) {
+    return (
+      <div style={{ padding: '2rem' }}>
+        <Login onLogin={() => setLoggedIn(true)} />
+      </div>
+    );
+  }
+
   return (
     <Router>
-      <AppNav />
+      <AppNav onLogout={() => { clearToken(); setLoggedIn(false); }} />
       <ToastContainer
         position="top-right"
         autoClose={5000} // Default autoClose time, can be overridden per toast
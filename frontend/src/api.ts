@@ -0,0 +1,25 @@
+const API_BASE_URL = 'http://localhost:8080';
+const TOKEN_KEY = 'authToken';
+
+export const getToken = (): string | null => localStorage.getItem(TOKEN_KEY);
+
+export const setToken = (token: string) => localStorage.setItem(TOKEN_KEY, token);
+
+export const clearToken = () => localStorage.removeItem(TOKEN_KEY);
+
+// apiFetch calls the backend with the stored bearer token and sends the user
+// back to the login page when the token is missing or has expired
+export const apiFetch = async (path: string, init: RequestInit = {}): Promise<Response> => {
+  const headers = new Headers(init.headers);
+  const token = getToken();
+  if (token) {
+    headers.set('Authorization', `Bearer ${token}`);
+  }
+
+  const response = await fetch(`${API_BASE_URL}${path}`, { ...init, headers });
+  if (response.status === 401 && !path.startsWith('/auth/login')) {
+    clearToken();
+    window.location.assign('/login');
+  }
+  return response;
+};
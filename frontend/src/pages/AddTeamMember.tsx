@@ -1,5 +1,6 @@
 import React, { useState } from 'react';
 import { Input, Button, Card } from '../components';
+import { apiFetch } from '../api';
 import { toast } from 'react-toastify';
 
 const AddTeamMember: React.FC = () => {
@@ -21,7 +22,7 @@ const AddTeamMember: React.FC = () => {
     };
 
     try {
-      const response = await fetch('http://localhost:8080/members/', {  // Added trailing slash
+      const response = await apiFetch('/members/', {  // Added trailing slash
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
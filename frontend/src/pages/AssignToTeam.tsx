@@ -1,5 +1,6 @@
 import React, { useState, useEffect } from 'react';
 import { Button, Card } from '../components';
+import { apiFetch } from '../api';
 import { toast } from 'react-toastify';
 
 // Updated interfaces to match the actual Go struct field names
@@ -34,7 +35,7 @@ const AssignToTeam: React.FC = () => {
       try {
         console.log('Fetching members and teams...');
         
-        const membersResponse = await fetch('http://localhost:8080/members/');
+        const membersResponse = await apiFetch('/members/');
         console.log('Members response:', membersResponse);
         
         if (!membersResponse.ok) {
@@ -44,7 +45,7 @@ const AssignToTeam: React.FC = () => {
         console.log('Members data detailed:', JSON.stringify(membersData, null, 2));
         setMembers(membersData);
 
-        const teamsResponse = await fetch('http://localhost:8080/teams/');
+        const teamsResponse = await apiFetch('/teams/');
         console.log('Teams response:', teamsResponse);
         
         if (!teamsResponse.ok) {
@@ -79,7 +80,7 @@ const AssignToTeam: React.FC = () => {
 
     try {
       // Using the correct endpoint format from your backend: /teams/:id/assign/:member_id
-      const response = await fetch(`http://localhost:8080/teams/${selectedTeamId}/assign/${selectedMemberId}`, {
+      const response = await apiFetch(`/teams/${selectedTeamId}/assign/${selectedMemberId}`, {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
@@ -1,5 +1,6 @@
 import React, { useState } from 'react';
 import { Input, Button, Card } from '../components';
+import { apiFetch } from '../api';
 import { toast } from 'react-toastify';
 
 const CreateTeam: React.FC = () => {
@@ -19,7 +20,7 @@ const CreateTeam: React.FC = () => {
     };
 
     try {
-      const response = await fetch('http://localhost:8080/teams/', {  // Added trailing slash
+      const response = await apiFetch('/teams/', {  // Added trailing slash
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
@@ -1,5 +1,6 @@
 import React, { useState, useEffect } from 'react';
 import { Button, Card } from '../components';
+import { apiFetch } from '../api';
 import { toast } from 'react-toastify';
 
 // Updated interfaces to match the actual Go struct field names
@@ -37,9 +38,9 @@ const GiveFeedback: React.FC = () => {
         
         let response;
         if (feedbackType === 'member') {
-          response = await fetch('http://localhost:8080/members/');
+          response = await apiFetch('/members/');
         } else {
-          response = await fetch('http://localhost:8080/teams/');
+          response = await apiFetch('/teams/');
         }
 
         if (!response.ok) {
@@ -75,13 +76,13 @@ const GiveFeedback: React.FC = () => {
     const feedbackData = {
       content: feedbackText,        // Backend expects 'content'
       targetid: parseInt(selectedTargetId, 10),  // Backend expects 'targetid'
-      targettype: feedbackType      // Backend expects 'targettype'
+      targettype: feedbackType      // Backend expects 'targettype' (the giver is the logged in user)
     };
 
     try {
       console.log('Submitting feedback:', feedbackData);
       
-      const response = await fetch('http://localhost:8080/feedback/', {
+      const response = await apiFetch('/feedback/', {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
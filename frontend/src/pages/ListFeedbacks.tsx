@@ -1,5 +1,6 @@
 import React, { useState, useEffect } from 'react';
 import { Card } from '../components';
+import { apiFetch } from '../api';
 
 // Updated interfaces to match Go backend field names
 interface Member {
@@ -16,6 +17,14 @@ interface Team {
   Members?: Member[];
 }
 
+// Target of a feedback, embedded when the list is requested with expand=target
+interface FeedbackParty {
+  Type: string;
+  ID: number;
+  Name: string;
+  PictureURL?: string;
+}
+
 interface Feedback {
   ID: number;           // Backend uses uppercase ID
   Content: string;      // Backend uses Content, not feedback_text
@@ -23,6 +32,7 @@ interface Feedback {
   TargetType: string;   // Backend uses TargetType, not target_type
   CreatedAt?: string;   // Backend uses CreatedAt
   UpdatedAt?: string;   // Backend uses UpdatedAt
+  Target?: FeedbackParty;
 }
 
 const ListFeedbacks: React.FC = () => {
@@ -39,8 +49,8 @@ const ListFeedbacks: React.FC = () => {
     const fetchMembersAndTeams = async () => {
       try {
         const [membersResponse, teamsResponse] = await Promise.all([
-          fetch('http://localhost:8080/members/'),
-          fetch('http://localhost:8080/teams/')
+          apiFetch('/members/'),
+          apiFetch('/teams/')
         ]);
 
         if (membersResponse.ok) {
@@ -67,8 +77,9 @@ const ListFeedbacks: React.FC = () => {
       setError(null);
       
       try {
-        let url = 'http://localhost:8080/feedback/'; // Note: your backend uses /feedback/, not /feedbacks
+        let url = '/feedback/'; // Note: your backend uses /feedback/, not /feedbacks
         const params = new URLSearchParams();
+        params.append('expand', 'target');
         
         if (filterType === 'member' && selectedId) {
           params.append('member_id', selectedId);
@@ -76,12 +87,10 @@ const ListFeedbacks: React.FC = () => {
           params.append('team_id', selectedId);
         }
         
-        if (params.toString()) {
-          url += `?${params.toString()}`;
-        }
+        url += `?${params.toString()}`;
 
         console.log(`Fetching from: ${url}`);
-        const response = await fetch(url);
+        const response = await apiFetch(url);
         
         if (!response.ok) {
           const errorData = await response.json().catch(() => ({ error: "Failed to parse error response" }));
@@ -104,6 +113,9 @@ const ListFeedbacks: React.FC = () => {
 
   // Get target name for display
   const getTargetName = (feedback: Feedback): string => {
+    if (feedback.Target) {
+      return feedback.Target.Name;
+    }
     if (feedback.TargetType === 'member') {
       const member = members.find(m => m.ID === feedback.TargetID);
       return member ? `${member.Name} (${member.Email})` : `Member ID ${feedback.TargetID}`;
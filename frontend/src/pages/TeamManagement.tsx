@@ -1,5 +1,6 @@
 import React, { useState, useEffect } from 'react';
 import { Button, Card } from '../components';
+import { apiFetch } from '../api';
 import { toast } from 'react-toastify';
 
 // Updated interfaces to match Go backend field names
@@ -31,7 +32,7 @@ const TeamManagement: React.FC = () => {
     setLoading(true);
     setError(null);
     try {
-      const response = await fetch('http://localhost:8080/teams/');
+      const response = await apiFetch('/teams/');
       if (!response.ok) {
         const errorText = await response.text();
         let errorMessage;
@@ -90,7 +91,7 @@ const TeamManagement: React.FC = () => {
 
     try {
       // Using the correct backend route: /teams/:id/remove/:member_id
-      const response = await fetch(`http://localhost:8080/teams/${teamId}/remove/${memberId}`, {
+      const response = await apiFetch(`/teams/${teamId}/remove/${memberId}`, {
         method: 'DELETE',
       });
       
@@ -126,7 +127,7 @@ const TeamManagement: React.FC = () => {
 
     try {
       // Try without trailing slash first
-      const response = await fetch(`http://localhost:8080/teams/${teamId}`, {
+      const response = await apiFetch(`/teams/${teamId}`, {
         method: 'DELETE',
       });
       
@@ -4,3 +4,4 @@ export { default as AssignToTeam } from './AssignToTeam';
 export { default as GiveFeedback } from './GiveFeedback';
 export { default as ListFeedbacks } from './ListFeedbacks'; // Added export for ListFeedbacks
 export { default as TeamManagement } from './TeamManagement'; // Added export for TeamManagement
+export { default as Login } from './Login';